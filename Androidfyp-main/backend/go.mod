@@ -5,20 +5,20 @@ go 1.24.6
 require github.com/lib/pq v1.10.9 // or latest version
 
 require (
-	github.com/bwmarrin/snowflake v0.3.0 // indirect
-	github.com/go-chi/chi/v5 v5.2.2 // indirect
-	github.com/golang-jwt/jwt/v4 v4.5.2 // indirect
+	github.com/bwmarrin/snowflake v0.3.0
+	github.com/go-chi/chi/v5 v5.2.2
+	github.com/golang-jwt/jwt/v4 v4.5.2
+	github.com/gorilla/websocket v1.5.3
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
-	github.com/jackc/pgx/v5 v5.6.0 // indirect
+	github.com/jackc/pgx/v5 v5.6.0
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
-	golang.org/x/crypto v0.42.0 // indirect
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.42.0
 	golang.org/x/sync v0.17.0 // indirect
 	golang.org/x/text v0.29.0 // indirect
-	gorm.io/driver/postgres v1.6.0 // indirect
-	gorm.io/gorm v1.30.1 // indirect
-	github.com/joho/godotenv v1.5.1 
-
+	gorm.io/driver/postgres v1.6.0
+	gorm.io/gorm v1.30.1
 )
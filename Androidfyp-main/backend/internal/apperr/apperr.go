@@ -0,0 +1,96 @@
+// Package apperr is the error model shared by services, handlers and
+// middleware. Domain errors carry a Code, each Code maps to one HTTP status,
+// and Write renders any error as an RFC 7807 problem document.
+package apperr
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Code classifies an error independently of the transport
+type Code string
+
+const (
+	CodeInvalid         Code = "invalid"         // the request is malformed or fails validation
+	CodeUnauthenticated Code = "unauthenticated" // no or bad credentials
+	CodeForbidden       Code = "forbidden"       // authenticated but not allowed
+	CodeNotFound        Code = "not_found"       // the target does not exist (or is hidden)
+	CodeConflict        Code = "conflict"        // clashes with the current state, e.g. a duplicate
+	CodeGone            Code = "gone"            // existed but can no longer be used
+	CodeUnprocessable   Code = "unprocessable"   // well-formed but refused, e.g. by the content filter
+	CodeRateLimited     Code = "rate_limited"    // too many requests or attempts
+	CodeInternal        Code = "internal"        // anything unexpected
+	CodeUnavailable     Code = "unavailable"     // a dependency is down
+)
+
+var statusByCode = map[Code]int{
+	CodeInvalid:         http.StatusBadRequest,
+	CodeUnauthenticated: http.StatusUnauthorized,
+	CodeForbidden:       http.StatusForbidden,
+	CodeNotFound:        http.StatusNotFound,
+	CodeConflict:        http.StatusConflict,
+	CodeGone:            http.StatusGone,
+	CodeUnprocessable:   http.StatusUnprocessableEntity,
+	CodeRateLimited:     http.StatusTooManyRequests,
+	CodeInternal:        http.StatusInternalServerError,
+	CodeUnavailable:     http.StatusServiceUnavailable,
+}
+
+// Status is the HTTP status for a code
+func (c Code) Status() int {
+	if status, ok := statusByCode[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is a domain error. Message is safe to show to the client; Err is the
+// underlying cause, kept for logs and errors.Is.
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New creates a domain error; declare sentinels with it
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Wrap attaches a code and a client-facing message to a cause. An empty
+// message uses the cause's text.
+func Wrap(err error, code Code, message string) *Error {
+	if message == "" && err != nil {
+		message = err.Error()
+	}
+	return &Error{Code: code, Message: message, Err: err}
+}
+
+func Invalid(message string) *Error         { return New(CodeInvalid, message) }
+func Unauthenticated(message string) *Error { return New(CodeUnauthenticated, message) }
+func Forbidden(message string) *Error       { return New(CodeForbidden, message) }
+func NotFound(message string) *Error        { return New(CodeNotFound, message) }
+func Conflict(message string) *Error        { return New(CodeConflict, message) }
+func Internal(message string) *Error        { return New(CodeInternal, message) }
+
+// CodeOf finds the code of err, looking through wrapped errors. Unique
+// violations that were not translated by the caller count as conflicts.
+func CodeOf(err error) Code {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	if IsUniqueViolation(err) {
+		return CodeConflict
+	}
+	return CodeInternal
+}
@@ -0,0 +1,32 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// uniqueViolation is the Postgres SQLSTATE for a unique constraint failure
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err comes from a unique constraint,
+// either as the raw Postgres error or as GORM's translated one
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == uniqueViolation
+	}
+	return errors.Is(err, gorm.ErrDuplicatedKey)
+}
+
+// Unique turns a unique violation into target (usually a conflict sentinel)
+// and returns any other error unchanged. The result matches target with
+// errors.Is and keeps the database error for logs.
+func Unique(err error, target *Error) error {
+	if IsUniqueViolation(err) {
+		return fmt.Errorf("%w: %w", target, err)
+	}
+	return err
+}
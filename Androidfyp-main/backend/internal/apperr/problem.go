@@ -0,0 +1,51 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// Problem is an RFC 7807 problem details document. Code is an extension
+// member clients can switch on.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     Code   `json:"code"`
+}
+
+// Write renders err as application/problem+json. Errors without a domain
+// code are logged and reported as a bare 500 so internals never leak.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	code := CodeOf(err)
+	status := code.Status()
+
+	var detail string
+	var appErr *Error
+	switch {
+	case errors.As(err, &appErr):
+		detail = appErr.Message
+	case code == CodeConflict:
+		detail = "conflicts with an existing record"
+	default:
+		detail = "internal server error"
+	}
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+	})
+}
@@ -0,0 +1,24 @@
+// Package audit carries who is calling from the HTTP layer down to the
+// service layer, which records security events without knowing about requests.
+package audit
+
+import "context"
+
+// Client is where a request came from
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// WithClient stores the caller's address and user agent in ctx
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, contextKey{}, client)
+}
+
+// ClientFrom returns the caller stored in ctx, empty outside a request
+func ClientFrom(ctx context.Context) Client {
+	client, _ := ctx.Value(contextKey{}).(Client)
+	return client
+}
@@ -0,0 +1,107 @@
+package contentfilter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Classification is a classifier's opinion of some text
+type Classification struct {
+	Score float64 `json:"score"` // 0 (fine) to 1 (certainly abusive)
+	Label string  `json:"label"` // e.g. "spam", "toxic"
+}
+
+// Classifier is the hook for an external abuse/spam model
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Classification, error)
+}
+
+// ClassifierCheck turns classifier scores into verdicts. Classifier errors are
+// logged and the content allowed, so an outage doesn't stop people posting.
+type ClassifierCheck struct {
+	Classifier   Classifier
+	HoldAbove    float64
+	RejectAbove  float64
+	RejectReason string // shown to the author when the score passes RejectAbove
+}
+
+func (c ClassifierCheck) Name() string { return "classifier" }
+
+func (c ClassifierCheck) Check(ctx context.Context, s Submission) (Verdict, error) {
+	result, err := c.Classifier.Classify(ctx, s.Text)
+	if err != nil {
+		slog.Warn("content filter: classifier unavailable", "error", err)
+		return Allowed, nil
+	}
+
+	switch {
+	case result.Score > c.RejectAbove:
+		return Verdict{Action: ActionReject, Reason: c.RejectReason}, nil
+	case result.Score > c.HoldAbove:
+		return Verdict{Action: ActionHold, Reason: fmt.Sprintf("classifier flagged %q (score %.2f)", result.Label, result.Score)}, nil
+	}
+	return Allowed, nil
+}
+
+// FakeClassifier is a local stand-in for the external model: every keyword
+// found in the text adds 0.6 to the score, labelled "fake"
+type FakeClassifier struct {
+	Keywords []string
+}
+
+func (f FakeClassifier) Classify(_ context.Context, text string) (Classification, error) {
+	lower := strings.ToLower(text)
+	score := 0.0
+	for _, keyword := range f.Keywords {
+		if strings.Contains(lower, strings.ToLower(keyword)) {
+			score += 0.6
+		}
+	}
+	if score > 1 {
+		score = 1
+	}
+	return Classification{Score: score, Label: "fake"}, nil
+}
+
+// HTTPClassifier POSTs {"text": ...} to URL and expects a Classification back
+type HTTPClassifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func NewHTTPClassifier(url string) *HTTPClassifier {
+	return &HTTPClassifier{URL: url, Client: &http.Client{Timeout: 2 * time.Second}}
+}
+
+func (h *HTTPClassifier) Classify(ctx context.Context, text string) (Classification, error) {
+	var result Classification
+
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return result, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return result, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("classifier returned status %d", resp.StatusCode)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
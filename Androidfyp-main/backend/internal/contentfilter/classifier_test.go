@@ -0,0 +1,91 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// scoreClassifier returns a fixed classification
+type scoreClassifier struct {
+	score float64
+	err   error
+}
+
+func (c scoreClassifier) Classify(context.Context, string) (Classification, error) {
+	return Classification{Score: c.score, Label: "test"}, c.err
+}
+
+func TestClassifierCheck(t *testing.T) {
+	tests := []struct {
+		score float64
+		err   error
+		want  string
+	}{
+		{0, nil, ActionAllow},
+		{0.5, nil, ActionAllow},
+		{0.51, nil, ActionHold},
+		{0.9, nil, ActionHold},
+		{0.91, nil, ActionReject},
+		{1, errors.New("classifier down"), ActionAllow},
+	}
+	for _, tt := range tests {
+		check := ClassifierCheck{
+			Classifier:   scoreClassifier{score: tt.score, err: tt.err},
+			HoldAbove:    0.5,
+			RejectAbove:  0.9,
+			RejectReason: "no",
+		}
+		verdict, err := check.Check(context.Background(), Submission{Text: "text"})
+		if err != nil {
+			t.Fatalf("score %.2f: %v", tt.score, err)
+		}
+		if verdict.Action != tt.want {
+			t.Errorf("score %.2f (err %v): Check = %s, want %s", tt.score, tt.err, verdict.Action, tt.want)
+		}
+		if verdict.Action == ActionReject && verdict.Reason != "no" {
+			t.Errorf("reject reason = %q, want RejectReason", verdict.Reason)
+		}
+	}
+}
+
+func TestFakeClassifier(t *testing.T) {
+	fake := FakeClassifier{Keywords: []string{"scam", "Crypto"}}
+
+	tests := []struct {
+		text string
+		want float64
+	}{
+		{"nothing to see", 0},
+		{"a SCAM", 0.6},
+		{"crypto scam", 1},
+	}
+	for _, tt := range tests {
+		result, err := fake.Classify(context.Background(), tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Score != tt.want {
+			t.Errorf("Classify(%q) score = %v, want %v", tt.text, result.Score, tt.want)
+		}
+	}
+}
+
+func TestClassifierCheckWithFake(t *testing.T) {
+	check := ClassifierCheck{Classifier: FakeClassifier{Keywords: []string{"scam", "crypto"}}, HoldAbove: 0.5, RejectAbove: 0.9}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"hello", ActionAllow},
+		{"this is a scam", ActionHold},
+		{"crypto scam", ActionReject},
+	}
+	for _, tt := range tests {
+		verdict, _ := check.Check(context.Background(), Submission{Text: tt.text})
+		if verdict.Action != tt.want {
+			t.Errorf("Check(%q) = %s, want %s", tt.text, verdict.Action, tt.want)
+		}
+	}
+}
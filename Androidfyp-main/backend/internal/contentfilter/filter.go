@@ -0,0 +1,94 @@
+// Package contentfilter screens user-written text before it is saved.
+// A Pipeline runs a list of Checks; each returns a Verdict and the strictest
+// one wins: a reject stops the pipeline, a hold is remembered.
+package contentfilter
+
+import (
+	"context"
+	"strings"
+)
+
+// Actions, ordered from most to least permissive
+const (
+	ActionAllow  = "allow"
+	ActionHold   = "hold"   // save it hidden and put it in the moderation queue
+	ActionReject = "reject" // don't save it, show Reason to the author
+)
+
+// Verdict is the outcome of screening one piece of content
+type Verdict struct {
+	Action string
+	Reason string // shown to the author on reject, to moderators on hold
+	Check  string // name of the check that decided
+}
+
+// Allowed is the verdict for content no check objected to
+var Allowed = Verdict{Action: ActionAllow}
+
+func (v Verdict) strictness() int {
+	switch v.Action {
+	case ActionReject:
+		return 2
+	case ActionHold:
+		return 1
+	}
+	return 0
+}
+
+// Content kinds
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+// Submission is the content being screened
+type Submission struct {
+	UserID uint64
+	Kind   string
+	Text   string
+}
+
+// Check is one step of the pipeline
+type Check interface {
+	Name() string
+	Check(ctx context.Context, s Submission) (Verdict, error)
+}
+
+// Pipeline runs its checks in order
+type Pipeline struct {
+	checks []Check
+}
+
+func NewPipeline(checks ...Check) *Pipeline {
+	return &Pipeline{checks: checks}
+}
+
+// Screen runs every check and returns the strictest verdict. Text with nothing
+// but whitespace (e.g. a photo-only post) is always allowed.
+func (p *Pipeline) Screen(ctx context.Context, s Submission) (Verdict, error) {
+	if strings.TrimSpace(s.Text) == "" {
+		return Allowed, nil
+	}
+
+	result := Allowed
+	for _, check := range p.checks {
+		verdict, err := check.Check(ctx, s)
+		if err != nil {
+			return Allowed, err
+		}
+		if verdict.strictness() > result.strictness() {
+			verdict.Check = check.Name()
+			result = verdict
+		}
+		if result.Action == ActionReject {
+			break
+		}
+	}
+	return result, nil
+}
+
+// Normalize lowercases text and collapses runs of whitespace so trivially
+// different copies compare equal
+func Normalize(text string) string {
+	return strings.ToLower(strings.Join(strings.Fields(text), " "))
+}
@@ -0,0 +1,110 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// fixedCheck returns the same verdict for everything and counts its calls
+type fixedCheck struct {
+	name    string
+	verdict Verdict
+	err     error
+	calls   *int
+}
+
+func (c fixedCheck) Name() string { return c.name }
+
+func (c fixedCheck) Check(context.Context, Submission) (Verdict, error) {
+	if c.calls != nil {
+		*c.calls++
+	}
+	return c.verdict, c.err
+}
+
+func TestPipelineScreen(t *testing.T) {
+	allow := fixedCheck{name: "allow", verdict: Allowed}
+	hold := fixedCheck{name: "hold", verdict: Verdict{Action: ActionHold, Reason: "held"}}
+	reject := fixedCheck{name: "reject", verdict: Verdict{Action: ActionReject, Reason: "rejected"}}
+
+	tests := []struct {
+		name   string
+		checks []Check
+		want   Verdict
+	}{
+		{"no checks", nil, Allowed},
+		{"all allow", []Check{allow, allow}, Allowed},
+		{"hold wins over allow", []Check{allow, hold, allow}, Verdict{Action: ActionHold, Reason: "held", Check: "hold"}},
+		{"reject wins over hold", []Check{hold, reject}, Verdict{Action: ActionReject, Reason: "rejected", Check: "reject"}},
+		{"first hold is kept", []Check{hold, fixedCheck{name: "later", verdict: Verdict{Action: ActionHold, Reason: "later"}}},
+			Verdict{Action: ActionHold, Reason: "held", Check: "hold"}},
+	}
+	for _, tt := range tests {
+		got, err := NewPipeline(tt.checks...).Screen(context.Background(), Submission{Text: "hello"})
+		if err != nil {
+			t.Fatalf("%s: Screen: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: Screen = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPipelineStopsAtReject(t *testing.T) {
+	calls := 0
+	pipeline := NewPipeline(
+		fixedCheck{name: "reject", verdict: Verdict{Action: ActionReject}},
+		fixedCheck{name: "after", verdict: Allowed, calls: &calls},
+	)
+	if _, err := pipeline.Screen(context.Background(), Submission{Text: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 0 {
+		t.Errorf("a check after a reject ran %d times", calls)
+	}
+}
+
+func TestPipelineAllowsBlankText(t *testing.T) {
+	calls := 0
+	pipeline := NewPipeline(fixedCheck{name: "reject", verdict: Verdict{Action: ActionReject}, calls: &calls})
+
+	for _, text := range []string{"", "  \n\t "} {
+		got, err := pipeline.Screen(context.Background(), Submission{Text: text})
+		if err != nil || got != Allowed {
+			t.Errorf("Screen(%q) = %+v, %v; want allowed", text, got, err)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("checks ran %d times on blank text", calls)
+	}
+}
+
+func TestPipelineReturnsCheckErrors(t *testing.T) {
+	boom := errors.New("boom")
+	pipeline := NewPipeline(fixedCheck{name: "broken", err: boom})
+
+	got, err := pipeline.Screen(context.Background(), Submission{Text: "hello"})
+	if !errors.Is(err, boom) {
+		t.Fatalf("Screen error = %v, want %v", err, boom)
+	}
+	if got != Allowed {
+		t.Errorf("Screen = %+v alongside an error, want allowed", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello World", "hello world"},
+		{"  Hello \n\t  WORLD  ", "hello world"},
+		{"", ""},
+		{"مرحبا   بكم", "مرحبا بكم"},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package contentfilter
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkLimit holds content carrying more links than MaxLinks
+type LinkLimit struct {
+	MaxLinks int
+}
+
+func (c LinkLimit) Name() string { return "link_limit" }
+
+func (c LinkLimit) Check(_ context.Context, s Submission) (Verdict, error) {
+	links := len(linkPattern.FindAllStringIndex(s.Text, -1))
+	if links > c.MaxLinks {
+		return Verdict{Action: ActionHold, Reason: fmt.Sprintf("contains %d links (limit %d)", links, c.MaxLinks)}, nil
+	}
+	return Allowed, nil
+}
+
+// History looks up what a user has already written
+type History interface {
+	// CountRecentDuplicates counts the user's posts and comments created
+	// since the given time whose normalized text equals normalized
+	CountRecentDuplicates(userID uint64, normalized string, since time.Time) (int64, error)
+}
+
+// RepeatedContent rejects the same text posted more than MaxRepeats times within Window
+type RepeatedContent struct {
+	History    History
+	MaxRepeats int
+	Window     time.Duration
+}
+
+func (c RepeatedContent) Name() string { return "repeated_content" }
+
+func (c RepeatedContent) Check(_ context.Context, s Submission) (Verdict, error) {
+	count, err := c.History.CountRecentDuplicates(s.UserID, Normalize(s.Text), time.Now().Add(-c.Window))
+	if err != nil {
+		return Allowed, err
+	}
+	if count >= int64(c.MaxRepeats) {
+		return Verdict{Action: ActionReject, Reason: "You've already posted this recently"}, nil
+	}
+	return Allowed, nil
+}
@@ -0,0 +1,84 @@
+package contentfilter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLinkLimit(t *testing.T) {
+	check := LinkLimit{MaxLinks: 2}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"no links here", ActionAllow},
+		{"see https://a.example and http://b.example", ActionAllow},
+		{"https://a.example www.b.example HTTP://c.example", ActionHold},
+		{"example.com has no scheme, so only www.a.example counts", ActionAllow},
+		{"https://a.example https://b.example https://c.example", ActionHold},
+	}
+	for _, tt := range tests {
+		verdict, err := check.Check(context.Background(), Submission{Text: tt.text})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", tt.text, err)
+		}
+		if verdict.Action != tt.want {
+			t.Errorf("Check(%q) = %s, want %s", tt.text, verdict.Action, tt.want)
+		}
+	}
+}
+
+// fakeHistory records the lookup it was asked for
+type fakeHistory struct {
+	count      int64
+	err        error
+	normalized string
+	since      time.Time
+}
+
+func (h *fakeHistory) CountRecentDuplicates(_ uint64, normalized string, since time.Time) (int64, error) {
+	h.normalized = normalized
+	h.since = since
+	return h.count, h.err
+}
+
+func TestRepeatedContent(t *testing.T) {
+	tests := []struct {
+		count int64
+		want  string
+	}{
+		{0, ActionAllow},
+		{2, ActionAllow},
+		{3, ActionReject},
+		{10, ActionReject},
+	}
+	for _, tt := range tests {
+		history := &fakeHistory{count: tt.count}
+		check := RepeatedContent{History: history, MaxRepeats: 3, Window: time.Hour}
+
+		verdict, err := check.Check(context.Background(), Submission{UserID: 1, Text: "  Same   TEXT "})
+		if err != nil {
+			t.Fatalf("%d earlier copies: %v", tt.count, err)
+		}
+		if verdict.Action != tt.want {
+			t.Errorf("%d earlier copies: Check = %s, want %s", tt.count, verdict.Action, tt.want)
+		}
+		if history.normalized != "same text" {
+			t.Errorf("looked up %q, want the normalized text", history.normalized)
+		}
+		if ago := time.Since(history.since); ago < 59*time.Minute || ago > 61*time.Minute {
+			t.Errorf("looked back %v, want the one hour window", ago)
+		}
+	}
+}
+
+func TestRepeatedContentReturnsHistoryErrors(t *testing.T) {
+	boom := errors.New("boom")
+	check := RepeatedContent{History: &fakeHistory{err: boom}, MaxRepeats: 3, Window: time.Hour}
+	if _, err := check.Check(context.Background(), Submission{Text: "text"}); !errors.Is(err, boom) {
+		t.Errorf("Check error = %v, want %v", err, boom)
+	}
+}
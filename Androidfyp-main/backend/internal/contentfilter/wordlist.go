@@ -0,0 +1,85 @@
+package contentfilter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+type wordRule struct {
+	pattern *regexp.Regexp
+	action  string
+}
+
+// WordList holds or rejects content matching configured words or regexes
+type WordList struct {
+	rules []wordRule
+}
+
+// ParseWordList reads one rule per line:
+//
+//	reject slur            # whole word, case-insensitive
+//	hold   /free\s+crypto/ # regular expression, case-insensitive
+//
+// Blank lines and lines starting with # are ignored.
+func ParseWordList(r io.Reader) (*WordList, error) {
+	list := &WordList{}
+	scanner := bufio.NewScanner(r)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+
+		action, pattern, ok := strings.Cut(line, " ")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" || (action != ActionHold && action != ActionReject) {
+			return nil, fmt.Errorf("word list line %d: want \"hold|reject <word or /regex/>\"", lineNo)
+		}
+
+		if err := list.Add(action, pattern); err != nil {
+			return nil, fmt.Errorf("word list line %d: %w", lineNo, err)
+		}
+	}
+	return list, scanner.Err()
+}
+
+// Add appends a rule; a pattern wrapped in slashes is a regex, anything else a whole word
+func (l *WordList) Add(action, pattern string) error {
+	var expr string
+	if len(pattern) > 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		expr = "(?i)" + pattern[1:len(pattern)-1]
+	} else {
+		expr = `(?i)\b` + regexp.QuoteMeta(pattern) + `\b`
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	l.rules = append(l.rules, wordRule{pattern: re, action: action})
+	return nil
+}
+
+func (l *WordList) Name() string { return "word_list" }
+
+func (l *WordList) Check(_ context.Context, s Submission) (Verdict, error) {
+	result := Allowed
+	for _, rule := range l.rules {
+		if !rule.pattern.MatchString(s.Text) {
+			continue
+		}
+		if rule.action == ActionReject {
+			return Verdict{Action: ActionReject, Reason: "Your " + s.Kind + " contains language that isn't allowed"}, nil
+		}
+		result = Verdict{Action: ActionHold, Reason: "matched word list pattern " + rule.pattern.String()}
+	}
+	return result, nil
+}
@@ -0,0 +1,68 @@
+package contentfilter
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+const testWordList = `
+# comments and blank lines are skipped
+
+reject badword          # whole word
+hold   /free\s+crypto/  # regular expression
+hold   spam
+`
+
+func TestWordListCheck(t *testing.T) {
+	list, err := ParseWordList(strings.NewReader(testWordList))
+	if err != nil {
+		t.Fatalf("ParseWordList: %v", err)
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"a perfectly normal post", ActionAllow},
+		{"this has a BadWord in it", ActionReject},
+		{"badwords is a different word", ActionAllow},
+		{"get FREE   crypto now", ActionHold},
+		{"freecrypto without a space", ActionAllow},
+		{"spam", ActionHold},
+		{"spam and badword", ActionReject},
+	}
+	for _, tt := range tests {
+		verdict, err := list.Check(context.Background(), Submission{Kind: KindPost, Text: tt.text})
+		if err != nil {
+			t.Fatalf("Check(%q): %v", tt.text, err)
+		}
+		if verdict.Action != tt.want {
+			t.Errorf("Check(%q) = %s, want %s", tt.text, verdict.Action, tt.want)
+		}
+	}
+}
+
+func TestWordListRejectReasonNamesKind(t *testing.T) {
+	list := &WordList{}
+	if err := list.Add(ActionReject, "badword"); err != nil {
+		t.Fatal(err)
+	}
+	verdict, _ := list.Check(context.Background(), Submission{Kind: KindComment, Text: "badword"})
+	if !strings.Contains(verdict.Reason, "comment") {
+		t.Errorf("reject reason %q doesn't mention the comment", verdict.Reason)
+	}
+}
+
+func TestParseWordListRejectsBadLines(t *testing.T) {
+	for _, line := range []string{
+		"badword",          // no action
+		"ban badword",      // unknown action
+		"hold",             // no pattern
+		"reject /([a-z]+/", // invalid regex
+	} {
+		if _, err := ParseWordList(strings.NewReader(line)); err == nil {
+			t.Errorf("ParseWordList accepted %q", line)
+		}
+	}
+}
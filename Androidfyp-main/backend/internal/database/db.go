@@ -2,6 +2,9 @@ package db
 
 import (
 	"fmt"
+	"log/slog"
+
+	"wazzafak_back/internal/metrics"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -33,6 +36,23 @@ func Connect() error {
 		return fmt.Errorf("failed to connect to DB: %w", err)
 	}
 
-	fmt.Println("✅ Successfully connected to Supabase PostgreSQL using GORM")
+	// Query counts and durations for /metrics
+	if err := DB.Use(metrics.GormPlugin{}); err != nil {
+		return fmt.Errorf("failed to register DB metrics: %w", err)
+	}
+
+	slog.Info("connected to Supabase PostgreSQL")
 	return nil
 }
+
+// Close releases the connection pool; call it once nothing uses DB anymore
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
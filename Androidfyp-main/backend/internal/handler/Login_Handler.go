@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/service"
 )
 
@@ -12,7 +13,14 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
+	Token             string `json:"token,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"` // send it to /login/2fa with a code
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"` // authenticator or recovery code
 }
 
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
@@ -20,15 +28,36 @@ func LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid request"})
+		apperr.Write(w, r, apperr.Invalid("Invalid request"))
+		return
+	}
+
+	result, err := service.Login(r.Context(), req.Email, req.Password)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(LoginResponse{
+		Token:             result.Token,
+		ChallengeToken:    result.ChallengeToken,
+		TwoFactorRequired: result.TwoFactorRequired,
+	})
+}
+
+// POST /login/2fa — second step of a login for accounts with 2FA
+func TwoFactorLoginHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var req TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid request"))
 		return
 	}
 
-	token, err := service.Login(req.Email, req.Password)
+	token, err := service.CompleteTwoFactorLogin(r.Context(), req.ChallengeToken, req.Code)
 	if err != nil {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		apperr.Write(w, r, err)
 		return
 	}
 
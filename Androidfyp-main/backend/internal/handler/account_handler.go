@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/service"
+)
+
+type UsernameRequest struct {
+	Username string `json:"username"`
+}
+
+type EmailChangeRequest struct {
+	Email string `json:"email"`
+}
+
+type EmailChangeCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PUT /users/me/username
+func ChangeUsernameHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req UsernameRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	user, err := service.ChangeUsername(r.Context(), userID, req.Username)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// POST /users/me/email — sends a code to the new address
+func RequestEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailChangeRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	if err := service.RequestEmailChange(r.Context(), userID, req.Email); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Verification code sent to the new email"})
+}
+
+// POST /users/me/email/verify
+func ConfirmEmailChangeHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req EmailChangeCodeRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	user, err := service.ConfirmEmailChange(r.Context(), userID, req.Code)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// PUT /users/me/password — signs out every other session and returns a new token
+func ChangePasswordHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store") // carries the new session token
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req ChangePasswordRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		apperr.Write(w, r, apperr.Invalid("Current and new password are required"))
+		return
+	}
+
+	token, err := service.ChangePassword(r.Context(), userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(LoginResponse{Token: token})
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code,omitempty"` // required when 2FA is enabled
+}
+
+type DeleteAccountResponse struct {
+	Message     string `json:"message"`
+	DeleteAfter string `json:"delete_after"`
+}
+
+// DELETE /users/me — the account is purged once the grace period is over
+func DeleteAccountHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req DeleteAccountRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	if req.Password == "" {
+		apperr.Write(w, r, apperr.Invalid("Password is required"))
+		return
+	}
+
+	deleteAfter, err := service.RequestAccountDeletion(r.Context(), userID, req.Password, req.Code)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(DeleteAccountResponse{
+		Message:     "Account scheduled for deletion",
+		DeleteAfter: deleteAfter.Format(time.RFC3339),
+	})
+}
+
+// POST /users/me/deletion/cancel
+func CancelAccountDeletionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.CancelAccountDeletion(r.Context(), userID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Account deletion cancelled"})
+}
+
+// GET /users/me/export — a zip archive of everything stored about the user
+func ExportAccountHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	archive, err := service.ExportAccountData(r.Context(), userID)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		apperr.Write(w, r, err)
+		return
+	}
+
+	fileName := "wazzafak-export-" + strconv.FormatUint(userID, 10) + ".zip"
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(archive)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(archive)
+}
+
+// GET /users/me/security-events?cursor=...&limit=... — sign-ins, password
+// and email changes and other account events, newest first
+func GetMySecurityEventsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.ListMySecurityEvents(userID, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
@@ -0,0 +1,386 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// ModerationRequest is the optional body of every admin action
+type ModerationRequest struct {
+	Reason   string  `json:"reason"`
+	ReportID *uint64 `json:"report_id"` // the report that prompted the action, if any
+}
+
+type SuspendRequest struct {
+	Reason   string     `json:"reason"`
+	ReportID *uint64    `json:"report_id"`
+	Until    *time.Time `json:"until"` // omit to suspend indefinitely
+}
+
+// GET /admin/reports?status=open&target_type=post&cursor=...&limit=...
+func GetReportsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := repository.ReportFilter{
+		Status:     query.Get("status"),
+		TargetType: query.Get("target_type"),
+	}
+	// The queue shows open reports unless asked otherwise
+	if filter.Status == "" {
+		filter.Status = model.ReportStatusOpen
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.ListReports(filter, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// POST /admin/reports/{reportID}/dismiss
+func DismissReportHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	reportID, err := strconv.ParseUint(chi.URLParam(r, "reportID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid report ID"))
+		return
+	}
+
+	adminID, req, ok := decodeModerationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.DismissReport(adminID, reportID, req.Reason); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Report dismissed"})
+}
+
+// POST /admin/posts/{postID}/hide
+func HidePostHandler(w http.ResponseWriter, r *http.Request) {
+	moderatePost(w, r, model.ModerationHidePost, "Post hidden")
+}
+
+// POST /admin/posts/{postID}/unhide
+func UnhidePostHandler(w http.ResponseWriter, r *http.Request) {
+	moderatePost(w, r, model.ModerationUnhidePost, "Post restored")
+}
+
+// DELETE /admin/posts/{postID}
+func RemovePostHandler(w http.ResponseWriter, r *http.Request) {
+	moderatePost(w, r, model.ModerationRemovePost, "Post removed")
+}
+
+func moderatePost(w http.ResponseWriter, r *http.Request, action, successMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
+	}
+
+	adminID, req, ok := decodeModerationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.ModeratePost(adminID, postID, action, req.Reason, req.ReportID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: successMessage})
+}
+
+// POST /admin/comments/{commentID}/hide
+func HideCommentHandler(w http.ResponseWriter, r *http.Request) {
+	moderateComment(w, r, model.ModerationHideComment, "Comment hidden")
+}
+
+// POST /admin/comments/{commentID}/unhide
+func UnhideCommentHandler(w http.ResponseWriter, r *http.Request) {
+	moderateComment(w, r, model.ModerationUnhideComment, "Comment restored")
+}
+
+// DELETE /admin/comments/{commentID}
+func RemoveCommentHandler(w http.ResponseWriter, r *http.Request) {
+	moderateComment(w, r, model.ModerationRemoveComment, "Comment removed")
+}
+
+func moderateComment(w http.ResponseWriter, r *http.Request, action, successMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	commentID, err := strconv.ParseUint(chi.URLParam(r, "commentID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid comment ID"))
+		return
+	}
+
+	adminID, req, ok := decodeModerationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.ModerateComment(adminID, commentID, action, req.Reason, req.ReportID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: successMessage})
+}
+
+// POST /admin/users/{userID}/suspend
+func SuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return
+	}
+
+	adminID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req SuspendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	if err := service.SuspendUser(adminID, userID, req.Until, req.Reason, req.ReportID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "User suspended"})
+}
+
+// POST /admin/users/{userID}/unsuspend
+func UnsuspendUserHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return
+	}
+
+	adminID, req, ok := decodeModerationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.UnsuspendUser(adminID, userID, req.Reason); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "User unsuspended"})
+}
+
+// GET /admin/audit-log?admin_id=...&target_type=...&target_id=...&cursor=...&limit=...
+func GetModerationLogHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := repository.ModerationActionFilter{TargetType: query.Get("target_type")}
+
+	if raw := query.Get("admin_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid admin ID"))
+			return
+		}
+		filter.AdminID = id
+	}
+	if raw := query.Get("target_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid target ID"))
+			return
+		}
+		filter.TargetID = id
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.ListModerationActions(filter, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// GET /admin/security-events?user_id=...&actor_id=...&type=...&ip=...&from=...&to=...&cursor=...&limit=...
+// from and to are RFC 3339 times; to is exclusive
+func GetSecurityEventsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := repository.AuditEventFilter{Type: query.Get("type"), IP: query.Get("ip")}
+
+	if raw := query.Get("user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+			return
+		}
+		filter.UserID = id
+	}
+	if raw := query.Get("actor_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid actor ID"))
+			return
+		}
+		filter.ActorID = id
+	}
+	if raw := query.Get("from"); raw != "" {
+		from, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("from must be an RFC 3339 time"))
+			return
+		}
+		filter.From = from
+	}
+	if raw := query.Get("to"); raw != "" {
+		to, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("to must be an RFC 3339 time"))
+			return
+		}
+		filter.To = to
+	}
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.ListAuditEvents(filter, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// decodeModerationRequest reads the admin ID and the optional request body
+func decodeModerationRequest(w http.ResponseWriter, r *http.Request) (uint64, ModerationRequest, bool) {
+	var req ModerationRequest
+
+	adminID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, req, false
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return 0, req, false
+	}
+	return adminID, req, true
+}
+
+// POST /admin/users/{userID}/recruiter
+func GrantRecruiterHandler(w http.ResponseWriter, r *http.Request) {
+	setRecruiter(w, r, true)
+}
+
+// DELETE /admin/users/{userID}/recruiter
+func RevokeRecruiterHandler(w http.ResponseWriter, r *http.Request) {
+	setRecruiter(w, r, false)
+}
+
+func setRecruiter(w http.ResponseWriter, r *http.Request, isRecruiter bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	adminID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := service.SetRecruiter(adminID, userID, isRecruiter); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	message := "Recruiter access granted"
+	if !isRecruiter {
+		message = "Recruiter access revoked"
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: message})
+}
+
+// GET /admin/outbox/dead?cursor=...&limit=... — emails the mail worker gave up on
+func GetDeadEmailsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	page, err := service.GetDeadEmails(r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// POST /admin/outbox/{emailID}/retry
+func RetryDeadEmailHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	emailID, err := strconv.ParseUint(chi.URLParam(r, "emailID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid email ID"))
+		return
+	}
+
+	if err := service.RetryDeadEmail(emailID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Email queued again"})
+}
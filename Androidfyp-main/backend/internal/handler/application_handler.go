@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ApplyRequest struct {
+	CVURL       string `json:"cv_url"` // the uploaded CV document
+	CVFileName  string `json:"cv_file_name"`
+	CoverLetter string `json:"cover_letter"`
+}
+
+type ChangeStageRequest struct {
+	Stage string `json:"stage"` // screening, interview, offer or rejected
+}
+
+type ApplicationNotesRequest struct {
+	Notes string `json:"notes"`
+}
+
+// POST /jobs/{jobID}/apply
+func ApplyToJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	application, err := service.ApplyToJob(userID, jobID, service.ApplicationInput{
+		CVURL:       req.CVURL,
+		CVFileName:  req.CVFileName,
+		CoverLetter: req.CoverLetter,
+	})
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(application)
+}
+
+// GET /jobs/{jobID}/applications?stage=...&q=...&cursor=...&limit=...
+func GetJobApplicantsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	filter := repository.ApplicantFilter{
+		Stage: query.Get("stage"),
+		Query: query.Get("q"),
+	}
+
+	page, err := service.ListJobApplicants(jobID, filter, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// GET /users/me/applications?stage=...&cursor=...&limit=...
+func GetMyApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.ListMyApplications(userID, query.Get("stage"), query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// GET /applications/{applicationID}
+func GetApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, applicationID, ok := applicationParams(w, r)
+	if !ok {
+		return
+	}
+
+	application, err := service.GetApplication(userID, applicationID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// POST /applications/{applicationID}/withdraw
+func WithdrawApplicationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, applicationID, ok := applicationParams(w, r)
+	if !ok {
+		return
+	}
+
+	application, err := service.WithdrawApplication(userID, applicationID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// PUT /applications/{applicationID}/stage
+func ChangeApplicationStageHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, applicationID, ok := applicationParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ChangeStageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	application, err := service.ChangeApplicationStage(userID, applicationID, req.Stage)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// PUT /applications/{applicationID}/notes
+func UpdateApplicationNotesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, applicationID, ok := applicationParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ApplicationNotesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	application, err := service.UpdateApplicationNotes(applicationID, req.Notes)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(application)
+}
+
+// applicationParams reads the caller and the {applicationID} URL param
+func applicationParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, 0, false
+	}
+
+	applicationID, err := strconv.ParseUint(chi.URLParam(r, "applicationID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid application ID"))
+		return 0, 0, false
+	}
+	return userID, applicationID, true
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/mail"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/service"
 )
 
@@ -29,49 +30,42 @@ type CompleteRegistrationRequest struct {
 	JobPositionType string `json:"job_position_type"`
 }
 
+type RegistrationResponse struct {
+	Message string `json:"message"`
+	UserID  uint64 `json:"user_id"`
+}
+
 // SendVerificationCodeHandler - Step 1: Send code to email (NO password needed)
 func SendVerificationCodeHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	var req SendVerificationCodeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	// Validate email is not empty
 	if req.Email == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Email cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Email cannot be empty"))
 		return
 	}
 
 	// Validate email format
 	if _, err := mail.ParseAddress(req.Email); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid email format"})
+		apperr.Write(w, r, apperr.Invalid("Invalid email format"))
 		return
 	}
 
 	// ✅ All validations passed, now send the code
 	_, err := service.SendVerificationCode(req.Email)
 	if err != nil {
-		switch err.Error() {
-		case "email already registered":
-			w.WriteHeader(http.StatusConflict)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Email already exists"})
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Verification code sent to email",
-	})
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Verification code sent to email"})
 }
 
 // VerifyEmailCodeHandler - Step 2: Verify code (NO password, just confirm email)
@@ -80,35 +74,29 @@ func VerifyEmailCodeHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req VerifyEmailCodeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	// Validate required fields
 	if req.Email == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Email cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Email cannot be empty"))
 		return
 	}
 
 	if req.Code == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Code cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Code cannot be empty"))
 		return
 	}
 
 	err := service.VerifyEmailCode(req.Email, req.Code)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Email verified successfully. Proceed to complete registration.",
-	})
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Email verified successfully. Proceed to complete registration."})
 }
 
 // CompleteRegistrationHandler - Step 3: Create user with password (after email verified)
@@ -117,49 +105,43 @@ func CompleteRegistrationHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req CompleteRegistrationRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	// Validate required fields
 	if req.Username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Username cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Username cannot be empty"))
 		return
 	}
 
 	if req.Name == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Name cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Name cannot be empty"))
 		return
 	}
 
 	if req.Email == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Email cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Email cannot be empty"))
 		return
 	}
 
 	if req.Password == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Password cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Password cannot be empty"))
 		return
 	}
 
 	if req.JobPosition == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Job position cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Job position cannot be empty"))
 		return
 	}
 
 	if req.JobPositionType == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Job position type cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Job position type cannot be empty"))
 		return
 	}
 
 	userID, err := service.CompleteUserRegistration(
+		r.Context(),
 		req.Email,
 		req.Username,
 		req.Name,
@@ -168,29 +150,13 @@ func CompleteRegistrationHandler(w http.ResponseWriter, r *http.Request) {
 		req.JobPositionType,
 	)
 	if err != nil {
-		switch err.Error() {
-		case "email not verified. Please verify your email first":
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
-		case "email already registered":
-			w.WriteHeader(http.StatusConflict)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Email already exists"})
-		default:
-			if err.Error() == "registration failed: Error 1062: Duplicate entry" ||
-				err.Error() == "registration failed: UNIQUE constraint failed" {
-				w.WriteHeader(http.StatusConflict)
-				json.NewEncoder(w).Encode(map[string]string{"error": "Username already exists"})
-			} else {
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(map[string]string{"error": "Failed to create user"})
-			}
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "User registered successfully",
-		"user_id": userID,
+	json.NewEncoder(w).Encode(RegistrationResponse{
+		Message: "User registered successfully",
+		UserID:  userID,
 	})
 }
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// POST /users/id/{userID}/block
+func BlockUserHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	blockerID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	blockedID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := service.BlockUser(blockerID, blockedID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "User blocked"})
+}
+
+// DELETE /users/id/{userID}/block
+func UnblockUserHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	blockerID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	blockedID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return
+	}
+
+	if err := service.UnblockUser(blockerID, blockedID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "User unblocked"})
+}
+
+// GET /users/me/blocks
+func GetMyBlocksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	users, err := service.GetBlockedUsers(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(users)
+}
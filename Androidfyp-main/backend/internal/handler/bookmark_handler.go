@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type BookmarkRequest struct {
+	CollectionID *uint64 `json:"collection_id"`
+}
+
+type CollectionRequest struct {
+	Name string `json:"name"`
+}
+
+type BookmarksPageResponse struct {
+	Posts      []PostResponse `json:"posts"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// POST /posts/{postID}/bookmark
+func BookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	// Body is optional: no body means "save without a collection"
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	if err := service.BookmarkPost(userID, postID, req.CollectionID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Post bookmarked successfully"})
+}
+
+// DELETE /posts/{postID}/bookmark
+func RemoveBookmarkHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	if err := service.RemoveBookmark(userID, postID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Bookmark removed successfully"})
+}
+
+// GET /users/me/bookmarks?collection_id=&cursor=&limit=
+func GetMyBookmarksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	query := r.URL.Query()
+
+	var collectionID *uint64
+	if raw := query.Get("collection_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid collection ID"))
+			return
+		}
+		collectionID = &id
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.GetMyBookmarks(userID, collectionID, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	response := BookmarksPageResponse{Posts: []PostResponse{}, NextCursor: page.NextCursor}
+	for _, bookmarked := range page.Posts {
+		response.Posts = append(response.Posts, buildPostResponse(bookmarked.Post, userID))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GET /users/me/bookmarks/collections
+func GetBookmarkCollectionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	collections, err := service.GetBookmarkCollections(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(collections)
+}
+
+// POST /users/me/bookmarks/collections
+func CreateBookmarkCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	collection, err := service.CreateBookmarkCollection(userID, req.Name)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(collection)
+}
+
+// PUT /users/me/bookmarks/collections/{collectionID}
+func RenameBookmarkCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	collectionID, err := strconv.ParseUint(chi.URLParam(r, "collectionID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid collection ID"))
+		return
+	}
+
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	if err := service.RenameBookmarkCollection(userID, collectionID, req.Name); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Collection renamed successfully"})
+}
+
+// DELETE /users/me/bookmarks/collections/{collectionID}
+func DeleteBookmarkCollectionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	collectionID, err := strconv.ParseUint(chi.URLParam(r, "collectionID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid collection ID"))
+		return
+	}
+
+	if err := service.DeleteBookmarkCollection(userID, collectionID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Collection deleted successfully"})
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/middleware"
 	"wazzafak_back/internal/service"
 
@@ -22,40 +24,29 @@ func CommentOnPostHandler(w http.ResponseWriter, r *http.Request) {
 	postIDStr := chi.URLParam(r, "postID")
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid post ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID not found"})
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found"))
 		return
 	}
 
 	var req CommentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON body"})
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
 		return
 	}
 
-	comment, err := service.AddComment(userID, postID, req.Content)
+	comment, err := service.AddComment(r.Context(), userID, postID, req.Content)
 	if err != nil {
-		switch err {
-		case service.ErrEmptyComment:
-			w.WriteHeader(http.StatusBadRequest)
-		case service.ErrPostNotFound:
-			w.WriteHeader(http.StatusNotFound)
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		apperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(heldStatus(comment.Hidden, http.StatusCreated))
 	json.NewEncoder(w).Encode(comment)
 }
 
@@ -69,35 +60,52 @@ func DeleteCommentFromPostHandler(w http.ResponseWriter, r *http.Request) {
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	commentID, err2 := strconv.ParseUint(commentIDStr, 10, 64)
 	if err != nil || err2 != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid ID"))
 		return
 	}
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID not found"})
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found"))
 		return
 	}
 
-	err = service.DeleteCommentFromPost(postID, commentID, userID)
+	restoreUntil, err := service.DeleteCommentFromPost(postID, commentID, userID)
 	if err != nil {
-		switch err {
-		case service.ErrUnauthorized:
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "You can only delete your own comments"})
-		case service.ErrCommentNotFound:
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Comment not found"})
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to delete comment"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
-	json.NewEncoder(w).Encode(SuccessResponse{Message: "Comment deleted successfully"})
+	json.NewEncoder(w).Encode(DeletedResponse{
+		Message:      "Comment deleted successfully",
+		RestoreUntil: restoreUntil.Format(time.RFC3339),
+	})
+}
+
+// POST /posts/{postID}/comments/{commentID}/restore
+func RestoreCommentHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	commentID, err2 := strconv.ParseUint(chi.URLParam(r, "commentID"), 10, 64)
+	if err != nil || err2 != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid ID"))
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found"))
+		return
+	}
+
+	comment, err := service.RestoreComment(postID, commentID, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(comment)
 }
 
 // GET /posts/{postID}/comments
@@ -107,19 +115,19 @@ func GetCommentsForPostHandler(w http.ResponseWriter, r *http.Request) {
 	postIDStr := chi.URLParam(r, "postID")
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "User ID not found", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found"))
 		return
 	}
 
 	comments, err := service.GetCommentsByPost(postID, userID)
 	if err != nil {
-		http.Error(w, "Failed to retrieve comments", http.StatusInternalServerError)
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -133,40 +141,28 @@ func UpdateCommentHandler(w http.ResponseWriter, r *http.Request) {
 	commentIDStr := chi.URLParam(r, "commentID")
 	commentID, err := strconv.ParseUint(commentIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid comment ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid comment ID"))
 		return
 	}
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID not found"})
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found"))
 		return
 	}
 
 	var req CommentRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid JSON body"})
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
 		return
 	}
 
-	comment, err := service.UpdateComment(commentID, userID, req.Content)
+	comment, err := service.UpdateComment(r.Context(), commentID, userID, req.Content)
 	if err != nil {
-		switch err {
-		case service.ErrEmptyComment:
-			w.WriteHeader(http.StatusBadRequest)
-		case service.ErrCommentNotFound:
-			w.WriteHeader(http.StatusNotFound)
-		case service.ErrUnauthorized:
-			w.WriteHeader(http.StatusForbidden)
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		apperr.Write(w, r, err)
 		return
 	}
 
+	w.WriteHeader(heldStatus(comment.Hidden, http.StatusOK))
 	json.NewEncoder(w).Encode(comment)
 }
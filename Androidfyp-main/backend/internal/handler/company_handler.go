@@ -0,0 +1,379 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CompanyRequest struct {
+	Name        string `json:"name"`
+	LogoURL     string `json:"logo_url"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+}
+
+func (req CompanyRequest) input() service.CompanyInput {
+	return service.CompanyInput{
+		Name:        req.Name,
+		LogoURL:     req.LogoURL,
+		Description: req.Description,
+		Website:     req.Website,
+	}
+}
+
+type CompanyRoleRequest struct {
+	Role string `json:"role"` // owner, recruiter or viewer
+}
+
+type CompanyInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"` // owner, recruiter or viewer
+}
+
+type AcceptInviteRequest struct {
+	Code string `json:"code"`
+}
+
+// POST /companies
+func CreateCompanyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req CompanyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	company, err := service.CreateCompany(userID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(company)
+}
+
+// GET /companies/{companyID}
+func GetCompanyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	company, err := service.GetCompany(companyID, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(company)
+}
+
+// PUT /companies/{companyID} — owners only
+func UpdateCompanyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req CompanyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	company, err := service.UpdateCompany(companyID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(company)
+}
+
+// GET /companies/{companyID}/jobs?status=open|closed|all&cursor=...&limit=...
+func GetCompanyJobsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	listJobs(w, r, repository.JobFilter{
+		Status:    r.URL.Query().Get("status"),
+		CompanyID: companyID,
+	})
+}
+
+// POST /companies/{companyID}/jobs — recruiters and owners
+func CreateCompanyJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	job, err := service.CreateCompanyJob(companyID, userID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GET /companies/{companyID}/members — any member
+func GetCompanyMembersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	members, err := service.GetCompanyMembers(companyID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(members)
+}
+
+// PUT /companies/{companyID}/members/{userID} — owners only
+func SetCompanyMemberRoleHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+	memberID, ok := memberParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req CompanyRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	if err := service.SetCompanyMemberRole(companyID, memberID, req.Role); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Role updated"})
+}
+
+// DELETE /companies/{companyID}/members/{userID} — owners only
+func RemoveCompanyMemberHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+	memberID, ok := memberParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.RemoveCompanyMember(companyID, memberID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Member removed"})
+}
+
+// DELETE /companies/{companyID}/members/me
+func LeaveCompanyHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.RemoveCompanyMember(companyID, userID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "You left the company"})
+}
+
+// POST /companies/{companyID}/invites — owners only
+func InviteCompanyMemberHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req CompanyInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	invite, err := service.InviteCompanyMember(companyID, userID, req.Email, req.Role)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invite)
+}
+
+// GET /companies/{companyID}/invites — owners only
+func GetCompanyInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	invites, err := service.GetCompanyInvites(companyID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invites)
+}
+
+// DELETE /companies/{companyID}/invites/{inviteID} — owners only
+func RevokeCompanyInviteHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_, companyID, ok := companyParams(w, r)
+	if !ok {
+		return
+	}
+
+	inviteID, err := strconv.ParseUint(chi.URLParam(r, "inviteID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid invite ID"))
+		return
+	}
+
+	if err := service.RevokeCompanyInvite(companyID, inviteID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Invite revoked"})
+}
+
+// POST /companies/invites/accept
+func AcceptCompanyInviteHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req AcceptInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	company, err := service.AcceptCompanyInvite(userID, req.Code)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(company)
+}
+
+// GET /users/me/companies
+func GetMyCompaniesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	companies, err := service.GetMyCompanies(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(companies)
+}
+
+// companyParams reads the caller and the {companyID} URL param
+func companyParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, 0, false
+	}
+
+	companyID, err := strconv.ParseUint(chi.URLParam(r, "companyID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid company ID"))
+		return 0, 0, false
+	}
+	return userID, companyID, true
+}
+
+func memberParam(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	memberID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return 0, false
+	}
+	return memberID, true
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// POST /users/id/{userID}/skills/{skill}/endorse
+func EndorseSkillHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	endorserID, userID, ok := endorsementParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.EndorseSkill(endorserID, userID, skillParam(r)); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Skill endorsed"})
+}
+
+// DELETE /users/id/{userID}/skills/{skill}/endorse
+func UnendorseSkillHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	endorserID, userID, ok := endorsementParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.UnendorseSkill(endorserID, userID, skillParam(r)); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Endorsement removed"})
+}
+
+func endorsementParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	endorserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, 0, false
+	}
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return 0, 0, false
+	}
+	return endorserID, userID, true
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/middleware"
 	"wazzafak_back/internal/service"
 
@@ -18,8 +19,7 @@ func FollowHandler(w http.ResponseWriter, r *http.Request) {
 	// followerID from JWT (you - the person doing the following)
 	followerID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
@@ -27,23 +27,20 @@ func FollowHandler(w http.ResponseWriter, r *http.Request) {
 	followingIDStr := chi.URLParam(r, "userID")
 	followingID, err := strconv.ParseUint(followingIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
 		return
 	}
 
 	// Prevent self-follow
 	if followerID == followingID {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "You cannot follow yourself"})
+		apperr.Write(w, r, apperr.Invalid("You cannot follow yourself"))
 		return
 	}
 
 	// Call service: YOU (followerID) follow THEM (followingID)
-	err = service.FollowUser(followerID, followingID)
+	err = service.FollowUser(r.Context(), followerID, followingID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Follow failed"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -58,8 +55,7 @@ func UnfollowHandler(w http.ResponseWriter, r *http.Request) {
 	// followerID from JWT (you - the person doing the unfollowing)
 	followerID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
@@ -67,23 +63,20 @@ func UnfollowHandler(w http.ResponseWriter, r *http.Request) {
 	followingIDStr := chi.URLParam(r, "userID")
 	followingID, err := strconv.ParseUint(followingIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
 		return
 	}
 
 	// Prevent self-unfollow
 	if followerID == followingID {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "You cannot unfollow yourself"})
+		apperr.Write(w, r, apperr.Invalid("You cannot unfollow yourself"))
 		return
 	}
 
 	// Call service: YOU (followerID) unfollow THEM (followingID)
-	err = service.UnfollowUser(followerID, followingID)
+	err = service.UnfollowUser(r.Context(), followerID, followingID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to unfollow"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -97,15 +90,13 @@ func GetFollowers(w http.ResponseWriter, r *http.Request) {
 
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Username is required"})
+		apperr.Write(w, r, apperr.Invalid("Username is required"))
 		return
 	}
 
 	followers, err := service.GetFollowersByUsername(username)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve followers"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -119,15 +110,13 @@ func GetFollowing(w http.ResponseWriter, r *http.Request) {
 
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Username is required"})
+		apperr.Write(w, r, apperr.Invalid("Username is required"))
 		return
 	}
 
 	following, err := service.GetFollowingByUsername(username)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve following"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -141,15 +130,13 @@ func GetMyFollowers(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
 	followers, err := service.GetFollowersByUserID(userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve followers"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -163,15 +150,13 @@ func GetMyFollowing(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
 	following, err := service.GetFollowingByUserID(userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve following"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -185,22 +170,19 @@ func GetFollowersByID(w http.ResponseWriter, r *http.Request) {
 
 	userIDStr := chi.URLParam(r, "userID")
 	if userIDStr == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID is required"})
+		apperr.Write(w, r, apperr.Invalid("User ID is required"))
 		return
 	}
 
 	userID, err := strconv.ParseUint(userIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
 		return
 	}
 
 	followers, err := service.GetFollowersByUserID(userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve followers"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -214,22 +196,19 @@ func GetFollowingByID(w http.ResponseWriter, r *http.Request) {
 
 	userIDStr := chi.URLParam(r, "userID")
 	if userIDStr == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID is required"})
+		apperr.Write(w, r, apperr.Invalid("User ID is required"))
 		return
 	}
 
 	userID, err := strconv.ParseUint(userIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
 		return
 	}
 
 	following, err := service.GetFollowingByUserID(userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve following"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"wazzafak_back/internal/service"
+)
+
+// GET /health/live
+// The process is up and serving HTTP. Dependencies are left out on purpose:
+// restarting the process doesn't fix a database outage.
+func LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+}
+
+// GET /health/ready
+// 200 while this replica should get traffic, 503 when the database can't be
+// used, its schema is older than this build, or shutdown has begun
+func ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	readiness := service.CheckReadiness(r.Context())
+
+	status := http.StatusOK
+	if readiness.Status == service.NotReady {
+		status = http.StatusServiceUnavailable
+		if !readiness.Draining {
+			slog.WarnContext(r.Context(), "not ready", "checks", readiness.Checks)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(readiness)
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type JobRequest struct {
+	Title           string `json:"title"`
+	Company         string `json:"company"`
+	Location        string `json:"location"`
+	RemoteType      string `json:"remote_type"` // onsite, hybrid or remote
+	Seniority       string `json:"seniority"`   // intern, junior, mid, senior or lead
+	JobPosition     string `json:"job_position"`
+	JobPositionType string `json:"job_position_type"`
+	Description     string `json:"description"`
+}
+
+func (req JobRequest) input() service.JobInput {
+	return service.JobInput{
+		Title:           req.Title,
+		Company:         req.Company,
+		Location:        req.Location,
+		RemoteType:      req.RemoteType,
+		Seniority:       req.Seniority,
+		JobPosition:     req.JobPosition,
+		JobPositionType: req.JobPositionType,
+		Description:     req.Description,
+	}
+}
+
+// GET /jobs?q=...&location=...&company=...&remote_type=...&seniority=...
+//
+//	&job_position=...&job_position_type=...&status=open|closed|all&cursor=...&limit=...
+func SearchJobsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	listJobs(w, r, repository.JobFilter{
+		Query:           query.Get("q"),
+		Location:        query.Get("location"),
+		Company:         query.Get("company"),
+		RemoteType:      query.Get("remote_type"),
+		Seniority:       query.Get("seniority"),
+		JobPosition:     query.Get("job_position"),
+		JobPositionType: query.Get("job_position_type"),
+		Status:          query.Get("status"),
+	})
+}
+
+// GET /users/me/jobs?status=open|closed|all&cursor=...&limit=...
+func GetMyJobsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	listJobs(w, r, repository.JobFilter{
+		Status:      r.URL.Query().Get("status"),
+		RecruiterID: userID,
+	})
+}
+
+func listJobs(w http.ResponseWriter, r *http.Request, filter repository.JobFilter) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.SearchJobs(filter, userID, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// POST /jobs
+func CreateJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	job, err := service.CreateJob(userID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GET /jobs/{jobID}
+func GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := service.GetJob(jobID, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// PUT /jobs/{jobID}
+func UpdateJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req JobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	job, err := service.UpdateJob(jobID, userID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// POST /jobs/{jobID}/close
+func CloseJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := service.CloseJob(jobID, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// POST /jobs/{jobID}/save
+func SaveJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.SaveJob(userID, jobID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Job saved"})
+}
+
+// DELETE /jobs/{jobID}/save
+func UnsaveJobHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, jobID, ok := jobParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.UnsaveJob(userID, jobID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Job removed from saved jobs"})
+}
+
+// GET /users/me/saved-jobs?cursor=...&limit=...
+func GetSavedJobsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.GetSavedJobs(userID, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// jobParams reads the caller and the {jobID} URL param
+func jobParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, 0, false
+	}
+
+	jobID, err := strconv.ParseUint(chi.URLParam(r, "jobID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid job ID"))
+		return 0, 0, false
+	}
+	return userID, jobID, true
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/middleware"
 	"wazzafak_back/internal/service"
 
@@ -18,21 +19,18 @@ func LikePostHandler(w http.ResponseWriter, r *http.Request) {
 	postIDStr := chi.URLParam(r, "postID")
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid post ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
 		return
 	}
 
 	if err := service.LikePost(userID, postID); err != nil {
-		w.WriteHeader(http.StatusConflict)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -47,21 +45,18 @@ func UnlikePostHandler(w http.ResponseWriter, r *http.Request) {
 	postIDStr := chi.URLParam(r, "postID")
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid post ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
 		return
 	}
 
 	if err := service.UnlikePost(userID, postID); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -76,15 +71,13 @@ func GetPostLikesHandler(w http.ResponseWriter, r *http.Request) {
 	postIDStr := chi.URLParam(r, "postID")
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid post ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
 	likes, err := service.GetUsersWhoLikedPost(postID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to fetch likes"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type StartConversationRequest struct {
+	MemberIDs []string `json:"member_ids"`
+	Title     string   `json:"title"`   // groups only
+	Message   string   `json:"message"` // optional first message
+}
+
+type SendMessageRequest struct {
+	Content string `json:"content"`
+}
+
+type MarkReadRequest struct {
+	MessageID string `json:"message_id"`
+}
+
+// GET /conversations?cursor=...&limit=...
+func GetConversationsHandler(w http.ResponseWriter, r *http.Request) {
+	listConversations(w, r, false)
+}
+
+// GET /conversations/requests?cursor=...&limit=...
+func GetMessageRequestsHandler(w http.ResponseWriter, r *http.Request) {
+	listConversations(w, r, true)
+}
+
+func listConversations(w http.ResponseWriter, r *http.Request, requests bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.ListConversations(userID, requests, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// POST /conversations
+func StartConversationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req StartConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	memberIDs := make([]uint64, 0, len(req.MemberIDs))
+	for _, raw := range req.MemberIDs {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid member ID"))
+			return
+		}
+		memberIDs = append(memberIDs, id)
+	}
+
+	conversation, err := service.StartConversation(userID, memberIDs, req.Title, req.Message)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(conversation)
+}
+
+// GET /conversations/{conversationID}
+func GetConversationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	conversation, err := service.GetConversation(userID, conversationID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(conversation)
+}
+
+// GET /conversations/{conversationID}/messages?cursor=...&limit=...
+func GetMessagesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	page, err := service.GetMessages(userID, conversationID, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+// POST /conversations/{conversationID}/messages
+func SendMessageHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	message, err := service.SendMessage(userID, conversationID, req.Content)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(message)
+}
+
+// POST /conversations/{conversationID}/read
+func MarkConversationReadHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req MarkReadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+	messageID, err := strconv.ParseUint(req.MessageID, 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid message ID"))
+		return
+	}
+
+	if err := service.MarkConversationRead(userID, conversationID, messageID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Conversation marked as read"})
+}
+
+// POST /conversations/{conversationID}/accept
+func AcceptMessageRequestHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.AcceptMessageRequest(userID, conversationID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Message request accepted"})
+}
+
+// POST /conversations/{conversationID}/decline
+func DeclineMessageRequestHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.DeclineMessageRequest(userID, conversationID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Message request declined"})
+}
+
+// DELETE /conversations/{conversationID}/members/me
+func LeaveConversationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, conversationID, ok := conversationParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.LeaveConversation(userID, conversationID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "You left the conversation"})
+}
+
+// GET /conversations/unread-count
+func GetUnreadMessagesCountHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	badges, err := service.GetUnreadMessageBadges(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(badges)
+}
+
+// conversationParams reads the caller and the {conversationID} URL param
+func conversationParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, 0, false
+	}
+
+	conversationID, err := strconv.ParseUint(chi.URLParam(r, "conversationID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid conversation ID"))
+		return 0, 0, false
+	}
+	return userID, conversationID, true
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/metrics"
+)
+
+// GET /metrics
+// Prometheus scrape endpoint. When METRICS_TOKEN is set the scraper has to
+// send it as a bearer token; user JWTs are not accepted here.
+func MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	if token := os.Getenv("METRICS_TOKEN"); token != "" {
+		given := []byte(r.Header.Get("Authorization"))
+		if subtle.ConstantTimeCompare(given, []byte("Bearer "+token)) != 1 {
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid metrics token"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(http.StatusOK)
+	metrics.WriteText(w)
+}
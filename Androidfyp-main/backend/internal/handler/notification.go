@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"wazzafak_back/internal/apperr"
 	database "wazzafak_back/internal/database"
 	"wazzafak_back/internal/middleware"
 	"wazzafak_back/internal/repository"
@@ -12,11 +13,19 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
+// UnreadCountResponse carries every badge the app shows
+type UnreadCountResponse struct {
+	UnreadCount         int64 `json:"unread_count"`
+	UnreadMessages      int64 `json:"unread_messages"`
+	UnreadConversations int64 `json:"unread_conversations"`
+	MessageRequests     int64 `json:"message_requests"`
+}
+
 // GetNotificationsHandler retrieves user notifications with details
 func GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized: missing user ID", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: missing user ID"))
 		return
 	}
 
@@ -30,9 +39,12 @@ func GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
 
 	notifications, err := repository.GetUserNotificationsWithDetails(database.DB, userID, limit)
 	if err != nil {
-		http.Error(w, "Failed to get notifications", http.StatusInternalServerError)
+		apperr.Write(w, r, err)
 		return
 	}
+	if notifications == nil {
+		notifications = []map[string]interface{}{}
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(notifications)
@@ -42,18 +54,30 @@ func GetNotificationsHandler(w http.ResponseWriter, r *http.Request) {
 func GetUnreadCountHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized: missing user ID", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: missing user ID"))
 		return
 	}
 
 	count, err := repository.GetUnreadNotificationCount(database.DB, userID)
 	if err != nil {
-		http.Error(w, "Failed to get unread count", http.StatusInternalServerError)
+		apperr.Write(w, r, err)
+		return
+	}
+
+	// Message badges ride along so the app can refresh every badge in one call
+	messages, err := repository.GetUnreadMessageCounts(database.DB, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]int64{"unread_count": count})
+	json.NewEncoder(w).Encode(UnreadCountResponse{
+		UnreadCount:         count,
+		UnreadMessages:      messages.UnreadMessages,
+		UnreadConversations: messages.UnreadConversations,
+		MessageRequests:     messages.MessageRequests,
+	})
 }
 
 // MarkNotificationReadHandler marks a single notification as read
@@ -61,32 +85,34 @@ func MarkNotificationReadHandler(w http.ResponseWriter, r *http.Request) {
 	notifIDStr := chi.URLParam(r, "notificationID")
 	notifID, err := strconv.ParseUint(notifIDStr, 10, 64)
 	if err != nil {
-		http.Error(w, "Invalid notification ID", http.StatusBadRequest)
+		apperr.Write(w, r, apperr.Invalid("Invalid notification ID"))
 		return
 	}
 
 	if err := repository.MarkNotificationAsRead(database.DB, notifID); err != nil {
-		http.Error(w, "Failed to mark as read", http.StatusInternalServerError)
+		apperr.Write(w, r, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "Marked as read"})
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Marked as read"})
 }
 
 // MarkAllNotificationsReadHandler marks all notifications of the user as read
 func MarkAllNotificationsReadHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized: missing user ID", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: missing user ID"))
 		return
 	}
 
 	if err := repository.MarkAllNotificationsAsRead(database.DB, userID); err != nil {
-		http.Error(w, "Failed to mark all as read", http.StatusInternalServerError)
+		apperr.Write(w, r, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"message": "All marked as read"})
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "All marked as read"})
 }
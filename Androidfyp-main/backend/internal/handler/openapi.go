@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/openapi"
+)
+
+var (
+	apiDocOnce sync.Once
+	apiDoc     *openapi.Document
+)
+
+// APIDoc describes every route of this service and of the interview AI
+// service. It is built from the handlers' own request and response types,
+// so a renamed field shows up in the document without anyone editing it.
+func APIDoc() *openapi.Document {
+	apiDocOnce.Do(func() {
+		spec := newAPISpec()
+		describeRoutes(spec)
+		spec.idempotencyKeys()
+		describeAIRoutes(spec)
+		apiDoc = spec.doc
+	})
+	return apiDoc
+}
+
+// GET /openapi.json
+func OpenAPIHandler(w http.ResponseWriter, r *http.Request) {
+	openapi.Handler(APIDoc())(w, r)
+}
+
+// apiSpec builds the document one operation at a time
+type apiSpec struct {
+	doc *openapi.Document
+	gen *openapi.Generator
+	tag string // tag of the operations added next
+	ids map[string]bool
+}
+
+// apiOperation is returned by route so details can be chained onto it
+type apiOperation struct {
+	spec *apiSpec
+	op   *openapi.Operation
+}
+
+func newAPISpec() *apiSpec {
+	doc := openapi.New(openapi.Info{
+		Title:       "Wazzafak API",
+		Description: "Social backend of the Wazzafak app. Errors are RFC 7807 problem documents; operations tagged \"Interview AI\" are served by the interview AI service.",
+		Version:     "1.0.0",
+	})
+	doc.Servers = []openapi.Server{{URL: "/", Description: "Social backend"}}
+	doc.Security = []openapi.Security{{"bearerAuth": {}}}
+	doc.Components.SecuritySchemes["bearerAuth"] = openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+
+	return &apiSpec{doc: doc, gen: openapi.NewGenerator(doc), ids: map[string]bool{}}
+}
+
+// section starts a group of operations under one tag; a tag is described the first time
+func (s *apiSpec) section(tag, description string) {
+	s.tag = tag
+	for _, existing := range s.doc.Tags {
+		if existing.Name == tag {
+			return
+		}
+	}
+	s.doc.Tags = append(s.doc.Tags, openapi.Tag{Name: tag, Description: description})
+}
+
+// component registers a hand-written schema for responses no Go type describes
+func (s *apiSpec) component(name string, schema *openapi.Schema) *openapi.Schema {
+	s.doc.Components.Schemas[name] = schema
+	return openapi.Ref(name)
+}
+
+// route documents one route of this service; the operation ID comes from the handler's name
+func (s *apiSpec) route(method, path string, h http.HandlerFunc, summary string) *apiOperation {
+	name := runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+	name = strings.TrimSuffix(name[strings.LastIndex(name, ".")+1:], "Handler")
+	return s.add(method, path, string(unicode.ToLower(rune(name[0])))+name[1:], summary)
+}
+
+func (s *apiSpec) add(method, path, id, summary string) *apiOperation {
+	if s.ids[id] {
+		panic(fmt.Sprintf("openapi: operation ID %s is used twice", id))
+	}
+	s.ids[id] = true
+
+	op := &openapi.Operation{
+		Tags:        []string{s.tag},
+		Summary:     summary,
+		OperationID: id,
+		Responses: map[string]*openapi.Response{
+			"default": {
+				Description: "Problem details",
+				Content:     map[string]openapi.MediaType{"application/problem+json": {Schema: s.gen.Schema(apperr.Problem{})}},
+			},
+		},
+	}
+	for _, param := range strings.Split(path, "/") {
+		if strings.HasPrefix(param, "{") && strings.HasSuffix(param, "}") {
+			op.Parameters = append(op.Parameters, pathParameter(strings.Trim(param, "{}")))
+		}
+	}
+
+	s.doc.Add(method, path, op)
+	return &apiOperation{spec: s, op: op}
+}
+
+// idempotencyKeys documents the Idempotency-Key header on every write that
+// needs a token, the routes middleware.Idempotency runs on
+func (s *apiSpec) idempotencyKeys() {
+	maxLength := 255
+	header := openapi.Parameter{
+		Name:        middleware.IdempotencyKeyHeader,
+		In:          "header",
+		Description: "Makes the request safe to retry: the first response is replayed for 24 hours, the same key with a different body is refused with 422",
+		Schema:      &openapi.Schema{Type: "string", MaxLength: &maxLength},
+	}
+	for _, route := range s.doc.Routes() {
+		op := route.Operation
+		public := op.Security != nil && len(*op.Security) == 0
+		if public || (route.Method != "POST" && route.Method != "PUT" && route.Method != "DELETE") {
+			continue
+		}
+		op.Parameters = append(op.Parameters, header)
+	}
+}
+
+// pathParameter types {postID}-style parameters as IDs and the rest as text
+func pathParameter(name string) openapi.Parameter {
+	schema := openapi.String()
+	if strings.HasSuffix(name, "ID") {
+		schema = openapi.Integer()
+	}
+	return openapi.Parameter{Name: name, In: "path", Required: true, Schema: schema}
+}
+
+// public marks an operation that needs no token
+func (o *apiOperation) public() *apiOperation {
+	o.op.Security = &[]openapi.Security{}
+	return o
+}
+
+// body documents a JSON request body; ValidateRequests enforces it
+func (o *apiOperation) body(v interface{}, required ...string) *apiOperation {
+	return o.bodySchema(o.spec.gen.Input(v, required...))
+}
+
+func (o *apiOperation) bodySchema(schema *openapi.Schema) *apiOperation {
+	o.op.RequestBody = &openapi.RequestBody{
+		Required: true,
+		Content:  map[string]openapi.MediaType{"application/json": {Schema: schema}},
+	}
+	return o
+}
+
+// optionalBody is for handlers that also accept an empty body
+func (o *apiOperation) optionalBody() *apiOperation {
+	o.op.RequestBody.Required = false
+	return o
+}
+
+// enum limits a string property of the request body
+func (o *apiOperation) enum(property string, values ...string) *apiOperation {
+	o.op.RequestBody.Content["application/json"].Schema.Properties[property].Enum = values
+	return o
+}
+
+// query documents an optional query parameter
+func (o *apiOperation) query(name string, schema *openapi.Schema, description string) *apiOperation {
+	o.op.Parameters = append(o.op.Parameters, openapi.Parameter{Name: name, In: "query", Description: description, Schema: schema})
+	return o
+}
+
+// paged documents the cursor and limit parameters of a paginated list
+func (o *apiOperation) paged() *apiOperation {
+	return o.
+		query("cursor", openapi.String(), "next_cursor of the previous page").
+		query("limit", openapi.Integer(), "page size")
+}
+
+// reply documents a JSON response; v is a value of the encoded type, nil for no body
+func (o *apiOperation) reply(status int, v interface{}) *apiOperation {
+	if v == nil {
+		o.op.Responses[strconv.Itoa(status)] = &openapi.Response{Description: http.StatusText(status)}
+		return o
+	}
+	return o.replySchema(status, "application/json", o.spec.gen.Schema(v))
+}
+
+func (o *apiOperation) replySchema(status int, contentType string, schema *openapi.Schema) *apiOperation {
+	o.op.Responses[strconv.Itoa(status)] = &openapi.Response{
+		Description: http.StatusText(status),
+		Content:     map[string]openapi.MediaType{contentType: {Schema: schema}},
+	}
+	return o
+}
+
+// held documents the 202 answered when the content filter holds a submission for review
+func (o *apiOperation) held(v interface{}) *apiOperation {
+	o.reply(http.StatusAccepted, v)
+	o.op.Responses[strconv.Itoa(http.StatusAccepted)].Description = "Held for review by the content filter"
+	return o
+}
+
+// served marks an operation that another service answers
+func (o *apiOperation) served(server openapi.Server) *apiOperation {
+	o.op.Servers = []openapi.Server{server}
+	return o
+}
+
+// aiServiceURL is where the app reaches the interview AI service
+func aiServiceURL() string {
+	if url := os.Getenv("AI_SERVICE_URL"); url != "" {
+		return url
+	}
+	return "http://localhost:8089"
+}
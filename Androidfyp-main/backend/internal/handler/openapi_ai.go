@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"net/http"
+
+	"wazzafak_back/internal/openapi"
+)
+
+// describeAIRoutes documents the interview AI service (wazzafak-ai-main). It
+// is a separate Go module, so its types are written out by hand here. The
+// app sends the same user IDs to both services; the AI service takes them
+// as JSON numbers. Its errors are plain text, not problem documents.
+func describeAIRoutes(s *apiSpec) {
+	server := openapi.Server{URL: aiServiceURL(), Description: "Interview AI service"}
+	ok := http.StatusOK
+
+	s.section("Interview AI", "Mock interviews, CV analysis and scores, served by the interview AI service")
+
+	s.component("AIOk", openapi.Require(openapi.Object(openapi.Props{
+		"ok":      openapi.Boolean(),
+		"message": openapi.String(),
+	}), "ok"))
+	s.component("AIFeedback", openapi.Require(openapi.Object(openapi.Props{
+		"user_id":    openapi.Integer(),
+		"feedback":   openapi.String(),
+		"created_at": openapi.DateTime(),
+		"message":    openapi.String(),
+	}), "user_id", "message"))
+
+	s.ai("GET", "/v1/health", "aiHealth", "Liveness check", server).
+		replySchema(ok, "application/json", openapi.Ref("AIOk"))
+	s.ai("POST", "/v1/kb/seed", "aiSeedKnowledgeBase", "Add interview questions to a domain's knowledge base", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"domain": openapi.String(),
+			"items":  openapi.Array(openapi.String()),
+		}), "domain", "items")).
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"ok":       openapi.Boolean(),
+			"inserted": openapi.Integer(),
+		}), "ok", "inserted"))
+	s.ai("POST", "/v1/cv/ingest", "aiIngestCV", "Store a CV's text for question generation", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"user_id": openapi.Integer(),
+			"text":    openapi.String(),
+		}), "user_id", "text")).
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"ok":     openapi.Boolean(),
+			"cv_id":  openapi.Integer(),
+			"chunks": openapi.Integer(),
+		}), "ok", "cv_id", "chunks"))
+	s.ai("POST", "/v1/next-question", "aiNextQuestion", "Generate the next interview question", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"user_id":    openapi.Integer(),
+			"domain":     openapi.String(),
+			"difficulty": openapi.String(),
+		}), "user_id", "domain")).
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"question": openapi.String(),
+		}), "question"))
+	s.ai("POST", "/v1/evaluate", "aiEvaluateAnswer", "Score an answer to an interview question", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"session_id": openapi.String(),
+			"user_id":    openapi.Integer(),
+			"domain":     openapi.String(),
+			"question":   openapi.String(),
+			"answer":     openapi.String(),
+		}), "user_id", "domain", "question", "answer")).
+		replySchema(ok, "application/json", openapi.Object(openapi.Props{
+			"scores": openapi.Array(openapi.Object(openapi.Props{
+				"name":  openapi.String(),
+				"score": openapi.Number(),
+			})),
+			"overall":                openapi.Number(),
+			"strengths":              openapi.Array(openapi.String()),
+			"weaknesses":             openapi.Array(openapi.String()),
+			"action_items":           openapi.Array(openapi.String()),
+			"suggested_model_answer": openapi.String(),
+		}))
+
+	cvUpload := openapi.Require(openapi.Object(openapi.Props{
+		"file":    openapi.Binary(),
+		"user_id": openapi.Integer(),
+	}), "file", "user_id")
+	cvAnalysis := openapi.Object(openapi.Props{
+		"message":         openapi.String(),
+		"cv_id":           openapi.Integer(),
+		"chunks_count":    openapi.Integer(),
+		"field":           openapi.String(),
+		"analysis":        openapi.String(),
+		"analysis_result": openapi.String(),
+		"ai_suggestion":   openapi.String(),
+		"text_length":     openapi.Integer(),
+		"cv_score":        openapi.Number(),
+		"stored":          openapi.Boolean(),
+	})
+	s.ai("POST", "/v1/upload-cv", "aiUploadCV", "Upload a CV (PDF or DOCX) and analyze it", server).
+		multipart(cvUpload).
+		replySchema(ok, "application/json", cvAnalysis)
+	s.ai("POST", "/v1/cv/upload", "aiUploadCVAlias", "Same as /v1/upload-cv", server).
+		multipart(cvUpload).
+		replySchema(ok, "application/json", cvAnalysis)
+
+	s.ai("GET", "/v1/live-interview", "aiLiveInterview", "Upgrade to a WebSocket running a spoken interview", server).
+		query("user_id", openapi.Integer(), "loads the user's latest CV").
+		reply(http.StatusSwitchingProtocols, nil)
+	s.ai("POST", "/v1/feedback", "aiSaveFeedback", "Save the scores of a finished interview", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"session_id":          openapi.String(),
+			"user_id":             openapi.Integer(),
+			"overall_score":       openapi.Number(),
+			"technical_score":     openapi.Number(),
+			"communication_score": openapi.Number(),
+			"confidence_score":    openapi.Number(),
+			"text_feedback":       openapi.String(),
+		}), "session_id")).
+		replySchema(ok, "application/json", openapi.Ref("AIOk"))
+	s.ai("POST", "/v1/session/start", "aiStartSession", "Start a practice interview", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"user_id": openapi.Integer(),
+			"major":   openapi.String(),
+		}), "user_id")).
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"session_id": openapi.String(),
+			"message":    openapi.String(),
+		}), "session_id", "message"))
+	s.ai("POST", "/v1/session/end", "aiEndSession", "End a practice interview and grade it", server).
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{
+			"session_id": openapi.String(),
+			"user_id":    openapi.Integer(),
+		}), "session_id")).
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"session_id":          openapi.String(),
+			"grade":               openapi.Integer(),
+			"behavioral_feedback": openapi.String(),
+			"technical_feedback":  openapi.String(),
+			"message":             openapi.String(),
+		}), "session_id", "grade", "message"))
+
+	s.ai("GET", "/v1/user/scores", "aiUserScores", "A user's latest scores", server).
+		userQuery().
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"user_id":           openapi.Integer(),
+			"technical_score":   openapi.Number(),
+			"behavioral_score":  openapi.Number(),
+			"cv_analysis_score": openapi.Number(),
+		}), "user_id", "technical_score", "behavioral_score", "cv_analysis_score"))
+	s.ai("GET", "/v1/user/feedback/cv", "aiCVFeedback", "A user's latest CV analysis", server).
+		userQuery().
+		replySchema(ok, "application/json", openapi.Require(openapi.Object(openapi.Props{
+			"user_id":       openapi.Integer(),
+			"grade":         openapi.Nullable(openapi.Integer()),
+			"ai_response":   openapi.Nullable(openapi.String()),
+			"ai_suggestion": openapi.Nullable(openapi.String()),
+			"score":         openapi.Number(),
+			"cv_text":       openapi.String(),
+			"created_at":    openapi.DateTime(),
+			"message":       openapi.String(),
+		}), "user_id", "message"))
+	s.ai("GET", "/v1/user/feedback/technical", "aiTechnicalFeedback", "A user's latest technical interview feedback", server).
+		userQuery().
+		replySchema(ok, "application/json", openapi.Ref("AIFeedback"))
+	s.ai("GET", "/v1/user/feedback/behavioral", "aiBehavioralFeedback", "A user's latest behavioral interview feedback", server).
+		userQuery().
+		replySchema(ok, "application/json", openapi.Ref("AIFeedback"))
+}
+
+// ai documents one route of the interview AI service. It checks no token and
+// reports errors as text/plain.
+func (s *apiSpec) ai(method, path, id, summary string, server openapi.Server) *apiOperation {
+	o := s.add(method, path, id, summary).public().served(server)
+	o.op.Responses["default"] = &openapi.Response{
+		Description: "Error message",
+		Content:     map[string]openapi.MediaType{"text/plain": {Schema: openapi.String()}},
+	}
+	return o
+}
+
+// userQuery documents the user_id every AI read endpoint requires
+func (o *apiOperation) userQuery() *apiOperation {
+	o.op.Parameters = append(o.op.Parameters, openapi.Parameter{Name: "user_id", In: "query", Required: true, Schema: openapi.Integer()})
+	return o
+}
+
+// multipart documents a form upload
+func (o *apiOperation) multipart(schema *openapi.Schema) *apiOperation {
+	o.op.RequestBody = &openapi.RequestBody{
+		Required: true,
+		Content:  map[string]openapi.MediaType{"multipart/form-data": {Schema: schema}},
+	}
+	return o
+}
@@ -0,0 +1,515 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/openapi"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
+)
+
+// describeRoutes documents the routes main.go registers, in the same order.
+// The contract test fails when the two lists drift apart.
+func describeRoutes(s *apiSpec) {
+	var (
+		user      = model.User{}
+		users     = []model.User{}
+		post      = PostResponse{}
+		posts     = []PostResponse{}
+		success   = SuccessResponse{}
+		created   = http.StatusCreated
+		ok        = http.StatusOK
+		idParam   = openapi.Integer()
+		textParam = openapi.String()
+	)
+
+	s.section("Auth", "Login, registration and password reset")
+	s.route("POST", "/login", LoginHandler, "Log in with email and password").public().
+		body(LoginRequest{}, "email", "password").
+		reply(ok, LoginResponse{})
+	s.route("POST", "/login/2fa", TwoFactorLoginHandler, "Finish a login with an authenticator or recovery code").public().
+		body(TwoFactorLoginRequest{}, "challenge_token", "code").
+		reply(ok, LoginResponse{})
+	s.route("GET", "/health", HealthCheckHandler, "Liveness check, same as /health/live").public().
+		reply(ok, HealthResponse{})
+	s.route("GET", "/health/live", LivenessHandler, "Liveness probe").public().
+		reply(ok, HealthResponse{})
+	s.route("GET", "/health/ready", ReadinessHandler, "Readiness probe: database, schema version and mail provider").public().
+		reply(ok, service.Readiness{}).
+		reply(http.StatusServiceUnavailable, service.Readiness{})
+	s.route("GET", "/openapi.json", OpenAPIHandler, "This document").public().
+		replySchema(ok, "application/json", &openapi.Schema{Type: "object"})
+	s.route("GET", "/metrics", MetricsHandler, "Prometheus metrics; needs METRICS_TOKEN as bearer token when it is set").public().
+		replySchema(ok, "text/plain", openapi.String())
+	s.route("POST", "/send-verification-code", SendVerificationCodeHandler, "Email a registration code").public().
+		body(SendVerificationCodeRequest{}, "email").
+		reply(ok, success)
+	s.route("POST", "/verify-email-code", VerifyEmailCodeHandler, "Check a registration code").public().
+		body(VerifyEmailCodeRequest{}, "email", "code").
+		reply(ok, success)
+	s.route("POST", "/complete-registration", CompleteRegistrationHandler, "Create the account of a verified email").public().
+		body(CompleteRegistrationRequest{}, "email", "username", "name", "password", "job_position", "job_position_type").
+		reply(created, RegistrationResponse{})
+	s.route("POST", "/send-password-reset", SendPasswordResetCodeHandler, "Email a password reset code").public().
+		body(SendPasswordResetRequest{}, "email").
+		reply(ok, success)
+	s.route("POST", "/reset-password", ResetPasswordHandler, "Set a new password with a reset code").public().
+		body(ResetPasswordRequest{}, "email", "code", "new_password").
+		reply(ok, success)
+
+	s.section("Users", "Accounts, settings and two-factor authentication")
+	s.route("GET", "/users/me/posts", GetMyPostsHandler, "Published posts of the current user").
+		reply(ok, posts)
+	s.route("GET", "/users/me/drafts", GetMyDraftsHandler, "Drafts and scheduled posts of the current user").
+		reply(ok, posts)
+	s.route("GET", "/users/me", GetUserProfile, "The current user").
+		reply(ok, user)
+	s.route("GET", "/users/me/followers", GetMyFollowers, "Followers of the current user").
+		reply(ok, users)
+	s.route("GET", "/users/me/following", GetMyFollowing, "Users the current user follows").
+		reply(ok, users)
+	s.route("GET", "/users/id/{userID}", GetUserByIDHandler, "A user by ID").
+		reply(ok, user)
+	s.route("GET", "/users/id/{userID}/photo", GetUserPhotoByID, "A user's photo URL").
+		reply(ok, PhotoResponse{})
+	s.route("GET", "/users/id/{userID}/followers", GetFollowersByID, "Followers of a user").
+		reply(ok, users)
+	s.route("GET", "/users/id/{userID}/following", GetFollowingByID, "Users a user follows").
+		reply(ok, users)
+	s.route("PUT", "/users/name", UpdateUserName, "Change the display name").
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{"name": openapi.String()}), "name")).
+		reply(ok, success)
+	s.route("PUT", "/users/photo", UpdatePhoto, "Change the profile photo").
+		bodySchema(openapi.Require(openapi.Object(openapi.Props{"photo_url": openapi.String()}), "photo_url")).
+		reply(ok, success)
+	s.route("DELETE", "/users/photo", DeletePhoto, "Reset the profile photo to the default").
+		reply(ok, success)
+	s.route("PUT", "/users/me/username", ChangeUsernameHandler, "Change the username").
+		body(UsernameRequest{}, "username").
+		reply(ok, user)
+	s.route("POST", "/users/me/email", RequestEmailChangeHandler, "Send a code to a new email address").
+		body(EmailChangeRequest{}, "email").
+		reply(ok, success)
+	s.route("POST", "/users/me/email/verify", ConfirmEmailChangeHandler, "Confirm the new email address").
+		body(EmailChangeCodeRequest{}, "code").
+		reply(ok, user)
+	s.route("PUT", "/users/me/password", ChangePasswordHandler, "Change the password and sign out other sessions").
+		body(ChangePasswordRequest{}, "current_password", "new_password").
+		reply(ok, LoginResponse{})
+	s.route("GET", "/users/me/2fa", GetTwoFactorStatusHandler, "Two-factor authentication status").
+		reply(ok, service.TwoFactorStatus{})
+	s.route("POST", "/users/me/2fa/setup", SetupTwoFactorHandler, "Start setting up an authenticator").
+		reply(ok, service.TwoFactorSetup{})
+	s.route("POST", "/users/me/2fa/enable", EnableTwoFactorHandler, "Turn on two-factor authentication").
+		body(TwoFactorCodeRequest{}, "code").
+		reply(ok, RecoveryCodesResponse{})
+	s.route("POST", "/users/me/2fa/recovery-codes", RegenerateRecoveryCodesHandler, "Replace the recovery codes").
+		body(TwoFactorCodeRequest{}, "code").
+		reply(ok, RecoveryCodesResponse{})
+	s.route("POST", "/users/me/2fa/disable", DisableTwoFactorHandler, "Turn off two-factor authentication").
+		body(DisableTwoFactorRequest{}, "password", "code").
+		reply(ok, success)
+	s.route("DELETE", "/users/me", DeleteAccountHandler, "Schedule the account for deletion").
+		body(DeleteAccountRequest{}, "password").
+		reply(http.StatusAccepted, DeleteAccountResponse{})
+	s.route("POST", "/users/me/deletion/cancel", CancelAccountDeletionHandler, "Keep an account scheduled for deletion").
+		reply(ok, success)
+	s.route("GET", "/users/me/export", ExportAccountHandler, "Download a zip of the account's data").
+		replySchema(ok, "application/zip", openapi.Binary())
+	s.route("GET", "/users/me/security-events", GetMySecurityEventsHandler, "Sign-ins and other security events of the account, newest first").
+		paged().
+		reply(ok, service.AuditEventPage{})
+
+	s.section("Posts", "Posts, comments, likes, reposts and polls")
+	s.route("GET", "/posts/{postID}/likes/users", GetPostLikesHandler, "Users who liked a post").
+		reply(ok, []repository.LikeUserInfo{})
+
+	s.section("Bookmarks", "Saved posts and their collections")
+	s.route("GET", "/users/me/bookmarks", GetMyBookmarksHandler, "Bookmarked posts, newest first").
+		query("collection_id", idParam, "only this collection").
+		paged().
+		reply(ok, BookmarksPageResponse{})
+	s.route("GET", "/users/me/bookmarks/collections", GetBookmarkCollectionsHandler, "Bookmark collections").
+		reply(ok, []service.BookmarkCollectionView{})
+	s.route("POST", "/users/me/bookmarks/collections", CreateBookmarkCollectionHandler, "Create a bookmark collection").
+		body(CollectionRequest{}, "name").
+		reply(created, service.BookmarkCollectionView{})
+	s.route("PUT", "/users/me/bookmarks/collections/{collectionID}", RenameBookmarkCollectionHandler, "Rename a bookmark collection").
+		body(CollectionRequest{}, "name").
+		reply(ok, success)
+	s.route("DELETE", "/users/me/bookmarks/collections/{collectionID}", DeleteBookmarkCollectionHandler, "Delete a bookmark collection").
+		reply(ok, success)
+
+	s.section("Profile", "Professional profile, skills and endorsements")
+	s.route("GET", "/users/me/profile", GetMyProfileHandler, "The current user's professional profile").
+		reply(ok, service.ProfileView{})
+	s.route("PUT", "/users/me/profile", UpdateAboutHandler, "Change the headline and bio").
+		body(AboutRequest{}).
+		reply(ok, success)
+	s.route("PUT", "/users/me/profile/skills", ReplaceSkillsHandler, "Replace the skill list").
+		body(SkillsRequest{}, "skills").
+		reply(ok, []service.SkillView{})
+	s.route("POST", "/users/me/profile/skills", AddSkillHandler, "Add a skill").
+		body(SkillRequest{}, "name").
+		reply(created, service.SkillView{})
+	s.route("DELETE", "/users/me/profile/skills/{skill}", RemoveSkillHandler, "Remove a skill").
+		reply(ok, success)
+	s.route("POST", "/users/me/profile/experience", AddExperienceHandler, "Add a job to the experience section").
+		body(ExperienceRequest{}).
+		reply(created, service.ExperienceView{})
+	s.route("PUT", "/users/me/profile/experience/{entryID}", UpdateExperienceHandler, "Edit an experience entry").
+		body(ExperienceRequest{}).
+		reply(ok, service.ExperienceView{})
+	s.route("DELETE", "/users/me/profile/experience/{entryID}", DeleteExperienceHandler, "Delete an experience entry").
+		reply(ok, success)
+	s.route("POST", "/users/me/profile/education", AddEducationHandler, "Add an education entry").
+		body(EducationRequest{}).
+		reply(created, service.EducationView{})
+	s.route("PUT", "/users/me/profile/education/{entryID}", UpdateEducationHandler, "Edit an education entry").
+		body(EducationRequest{}).
+		reply(ok, service.EducationView{})
+	s.route("DELETE", "/users/me/profile/education/{entryID}", DeleteEducationHandler, "Delete an education entry").
+		reply(ok, success)
+	s.route("POST", "/users/me/profile/links", AddLinkHandler, "Add a profile link").
+		body(LinkRequest{}).
+		reply(created, service.LinkView{})
+	s.route("PUT", "/users/me/profile/links/{entryID}", UpdateLinkHandler, "Edit a profile link").
+		body(LinkRequest{}).
+		reply(ok, service.LinkView{})
+	s.route("DELETE", "/users/me/profile/links/{entryID}", DeleteLinkHandler, "Delete a profile link").
+		reply(ok, success)
+	s.route("GET", "/users/search", SearchUsersHandler, "Search people by name, skill, company or school").
+		query("q", textParam, "name or username").
+		query("skill", textParam, "").
+		query("company", textParam, "").
+		query("school", textParam, "").
+		paged().
+		reply(ok, service.UserSearchPage{})
+	s.route("POST", "/users/id/{userID}/skills/{skill}/endorse", EndorseSkillHandler, "Endorse a user's skill").
+		reply(ok, success)
+	s.route("DELETE", "/users/id/{userID}/skills/{skill}/endorse", UnendorseSkillHandler, "Take back an endorsement").
+		reply(ok, success)
+
+	s.section("Users", "")
+	s.route("GET", "/users/{username}", GetUserByUsername, "A user's public profile").
+		reply(ok, service.UserWithProfile{})
+	s.route("GET", "/users/{username}/posts", GetUserPosts, "A user's posts").
+		reply(ok, []model.Post{})
+	s.route("GET", "/users/{username}/followers", GetFollowers, "A user's followers").
+		reply(ok, users)
+	s.route("GET", "/users/{username}/following", GetFollowing, "Users a user follows").
+		reply(ok, users)
+
+	s.section("Posts", "")
+	s.route("POST", "/posts", CreatePost, "Publish, schedule or draft a post").
+		body(PostRequest{}).
+		enum("status", model.PostStatusDraft, model.PostStatusScheduled, model.PostStatusPublished).
+		reply(created, success).
+		held(success)
+	s.route("GET", "/posts/{postID}", GetPost, "A post").
+		reply(ok, model.Post{})
+	s.route("PUT", "/posts/{postID}", UpdatePostHandler, "Edit a draft or scheduled post").
+		body(PostRequest{}).
+		enum("status", model.PostStatusDraft, model.PostStatusScheduled, model.PostStatusPublished).
+		reply(ok, post).
+		held(post)
+	s.route("DELETE", "/posts/{postID}", DeletePost, "Delete a post; it can be restored for a while").
+		reply(ok, DeletedResponse{})
+	s.route("POST", "/posts/{postID}/restore", RestorePostHandler, "Undo deleting a post").
+		reply(ok, post)
+	s.route("POST", "/posts/{postID}/publish", PublishPostHandler, "Publish a draft or scheduled post now").
+		reply(ok, post).
+		held(post)
+	s.route("GET", "/posts/all", GetAllPostsHandler, "Every published post, newest first").
+		reply(ok, posts)
+	s.route("GET", "/posts/{postID}/comments", GetCommentsForPostHandler, "Comments on a post").
+		reply(ok, []service.CommentResponse{})
+
+	s.section("Social", "Following and blocking")
+	s.route("POST", "/follow/{userID}", FollowHandler, "Follow a user").
+		reply(created, success)
+	s.route("DELETE", "/unfollow/{userID}", UnfollowHandler, "Unfollow a user").
+		reply(ok, success)
+
+	s.section("Posts", "")
+	s.route("POST", "/posts/{postID}/like", LikePostHandler, "Like a post").
+		reply(ok, success)
+	s.route("POST", "/posts/{postID}/unlike", UnlikePostHandler, "Remove a like").
+		reply(ok, success)
+	s.route("POST", "/posts/{postID}/bookmark", BookmarkPostHandler, "Bookmark a post").
+		body(BookmarkRequest{}).
+		optionalBody().
+		reply(ok, success)
+	s.route("DELETE", "/posts/{postID}/bookmark", RemoveBookmarkHandler, "Remove a bookmark").
+		reply(ok, success)
+	s.route("POST", "/posts/{postID}/repost", RepostHandler, "Repost a post").
+		reply(created, success)
+	s.route("DELETE", "/posts/{postID}/repost", UndoRepostHandler, "Undo a repost").
+		reply(ok, success)
+	s.route("POST", "/posts/{postID}/quote", QuotePostHandler, "Share a post with a comment").
+		body(QuoteRequest{}).
+		reply(created, post).
+		held(post)
+	s.route("POST", "/posts/{postID}/vote", VotePollHandler, "Vote in a poll").
+		body(PollVoteRequest{}, "option_ids").
+		reply(ok, service.PollView{})
+	s.route("POST", "/posts/{postID}/comment", CommentOnPostHandler, "Comment on a post").
+		body(CommentRequest{}, "content").
+		reply(created, model.Comment{}).
+		held(model.Comment{})
+	s.route("DELETE", "/posts/{postID}/comments/{commentID}", DeleteCommentFromPostHandler, "Delete a comment; it can be restored for a while").
+		reply(ok, DeletedResponse{})
+	s.route("POST", "/posts/{postID}/comments/{commentID}/restore", RestoreCommentHandler, "Undo deleting a comment").
+		reply(ok, model.Comment{})
+
+	s.section("Reports", "Reporting posts, comments and users to moderators")
+	s.route("POST", "/posts/{postID}/report", ReportPostHandler, "Report a post").
+		body(ReportRequest{}, "reason").enum("reason", model.ReportReasons...).
+		reply(created, success)
+	s.route("POST", "/posts/{postID}/comments/{commentID}/report", ReportCommentHandler, "Report a comment").
+		body(ReportRequest{}, "reason").enum("reason", model.ReportReasons...).
+		reply(created, success)
+	s.route("POST", "/users/id/{userID}/report", ReportUserHandler, "Report a user").
+		body(ReportRequest{}, "reason").enum("reason", model.ReportReasons...).
+		reply(created, success)
+
+	s.section("Social", "")
+	s.route("GET", "/users/me/blocks", GetMyBlocksHandler, "Users the current user blocked").
+		reply(ok, users)
+	s.route("POST", "/users/id/{userID}/block", BlockUserHandler, "Block a user").
+		reply(ok, success)
+	s.route("DELETE", "/users/id/{userID}/block", UnblockUserHandler, "Unblock a user").
+		reply(ok, success)
+
+	s.section("Messages", "Direct and group conversations")
+	s.route("GET", "/ws", WebSocketHandler, "Upgrade to a WebSocket streaming message, typing and read events").
+		reply(http.StatusSwitchingProtocols, nil)
+	s.route("GET", "/conversations", GetConversationsHandler, "Conversations, most recent first").
+		paged().
+		reply(ok, service.ConversationPage{})
+	s.route("POST", "/conversations", StartConversationHandler, "Start a direct or group conversation").
+		body(StartConversationRequest{}, "member_ids").
+		reply(created, service.ConversationView{})
+	s.route("GET", "/conversations/requests", GetMessageRequestsHandler, "Message requests waiting for an answer").
+		paged().
+		reply(ok, service.ConversationPage{})
+	s.route("GET", "/conversations/unread-count", GetUnreadMessagesCountHandler, "Unread message badges").
+		reply(ok, service.UnreadMessageBadges{})
+	s.route("GET", "/conversations/{conversationID}", GetConversationHandler, "A conversation").
+		reply(ok, service.ConversationView{})
+	s.route("GET", "/conversations/{conversationID}/messages", GetMessagesHandler, "Messages, newest first").
+		paged().
+		reply(ok, service.MessagePage{})
+	s.route("POST", "/conversations/{conversationID}/messages", SendMessageHandler, "Send a message").
+		body(SendMessageRequest{}, "content").
+		reply(created, service.MessageView{})
+	s.route("POST", "/conversations/{conversationID}/read", MarkConversationReadHandler, "Mark messages as read up to one").
+		body(MarkReadRequest{}, "message_id").
+		reply(ok, success)
+	s.route("POST", "/conversations/{conversationID}/accept", AcceptMessageRequestHandler, "Accept a message request").
+		reply(ok, success)
+	s.route("POST", "/conversations/{conversationID}/decline", DeclineMessageRequestHandler, "Decline a message request").
+		reply(ok, success)
+	s.route("DELETE", "/conversations/{conversationID}/members/me", LeaveConversationHandler, "Leave a group conversation").
+		reply(ok, success)
+
+	s.section("Jobs", "Job postings and applications")
+	s.route("GET", "/jobs", SearchJobsHandler, "Search job postings").
+		query("q", textParam, "title or description").
+		query("location", textParam, "").
+		query("company", textParam, "").
+		query("remote_type", &openapi.Schema{Type: "string", Enum: model.JobRemoteTypes}, "").
+		query("seniority", &openapi.Schema{Type: "string", Enum: model.JobSeniorities}, "").
+		query("job_position", textParam, "").
+		query("job_position_type", textParam, "").
+		query("status", &openapi.Schema{Type: "string", Enum: []string{model.JobStatusOpen, model.JobStatusClosed}}, "").
+		paged().
+		reply(ok, service.JobPage{})
+	s.route("POST", "/jobs", CreateJobHandler, "Post a job as a recruiter").
+		body(JobRequest{}, "title", "company", "job_position", "description").
+		reply(created, service.JobView{})
+	s.route("GET", "/jobs/{jobID}", GetJobHandler, "A job posting").
+		reply(ok, service.JobView{})
+	s.route("PUT", "/jobs/{jobID}", UpdateJobHandler, "Edit a job posting").
+		body(JobRequest{}, "title", "company", "job_position", "description").
+		reply(ok, service.JobView{})
+	s.route("POST", "/jobs/{jobID}/close", CloseJobHandler, "Stop taking applications").
+		reply(ok, service.JobView{})
+	s.route("POST", "/jobs/{jobID}/save", SaveJobHandler, "Save a job").
+		reply(ok, success)
+	s.route("DELETE", "/jobs/{jobID}/save", UnsaveJobHandler, "Remove a saved job").
+		reply(ok, success)
+	s.route("GET", "/users/me/jobs", GetMyJobsHandler, "Jobs the current user posted").
+		query("status", &openapi.Schema{Type: "string", Enum: []string{model.JobStatusOpen, model.JobStatusClosed}}, "").
+		paged().
+		reply(ok, service.JobPage{})
+	s.route("GET", "/users/me/saved-jobs", GetSavedJobsHandler, "Saved jobs").
+		paged().
+		reply(ok, service.JobPage{})
+	s.route("POST", "/jobs/{jobID}/apply", ApplyToJobHandler, "Apply to a job").
+		body(ApplyRequest{}, "cv_url").
+		reply(created, service.ApplicationView{})
+	s.route("GET", "/jobs/{jobID}/applications", GetJobApplicantsHandler, "Applicants of a job").
+		query("stage", textParam, "").
+		query("q", textParam, "applicant name or username").
+		paged().
+		reply(ok, service.ApplicantPage{})
+	s.route("GET", "/users/me/applications", GetMyApplicationsHandler, "The current user's applications").
+		query("stage", textParam, "").
+		paged().
+		reply(ok, service.ApplicationPage{})
+	s.route("GET", "/applications/{applicationID}", GetApplicationHandler, "An application").
+		reply(ok, service.ApplicationView{})
+	s.route("POST", "/applications/{applicationID}/withdraw", WithdrawApplicationHandler, "Withdraw an application").
+		reply(ok, service.ApplicationView{})
+	s.route("PUT", "/applications/{applicationID}/stage", ChangeApplicationStageHandler, "Move an application to another stage").
+		body(ChangeStageRequest{}, "stage").
+		enum("stage", model.ApplicationStageScreening, model.ApplicationStageInterview, model.ApplicationStageOffer, model.ApplicationStageRejected).
+		reply(ok, service.ApplicationView{})
+	s.route("PUT", "/applications/{applicationID}/notes", UpdateApplicationNotesHandler, "Replace the recruiter notes of an application").
+		body(ApplicationNotesRequest{}).
+		reply(ok, service.ApplicationView{})
+
+	companyRoles := []string{model.CompanyRoleOwner, model.CompanyRoleRecruiter, model.CompanyRoleViewer}
+
+	s.section("Companies", "Company pages, members and invites")
+	s.route("POST", "/companies", CreateCompanyHandler, "Create a company page").
+		body(CompanyRequest{}, "name").
+		reply(created, service.CompanyView{})
+	s.route("POST", "/companies/invites/accept", AcceptCompanyInviteHandler, "Join a company with an invite code").
+		body(AcceptInviteRequest{}, "code").
+		reply(ok, service.CompanyView{})
+	s.route("GET", "/users/me/companies", GetMyCompaniesHandler, "Companies the current user belongs to").
+		reply(ok, []service.CompanyView{})
+	s.route("GET", "/companies/{companyID}", GetCompanyHandler, "A company page").
+		reply(ok, service.CompanyView{})
+	s.route("PUT", "/companies/{companyID}", UpdateCompanyHandler, "Edit a company page").
+		body(CompanyRequest{}, "name").
+		reply(ok, service.CompanyView{})
+	s.route("GET", "/companies/{companyID}/jobs", GetCompanyJobsHandler, "A company's jobs").
+		query("status", &openapi.Schema{Type: "string", Enum: []string{model.JobStatusOpen, model.JobStatusClosed}}, "").
+		paged().
+		reply(ok, service.JobPage{})
+	s.route("POST", "/companies/{companyID}/jobs", CreateCompanyJobHandler, "Post a job for a company").
+		body(JobRequest{}, "title", "company", "job_position", "description").
+		reply(created, service.JobView{})
+	s.route("GET", "/companies/{companyID}/members", GetCompanyMembersHandler, "A company's members").
+		reply(ok, []service.CompanyMemberView{})
+	s.route("DELETE", "/companies/{companyID}/members/me", LeaveCompanyHandler, "Leave a company").
+		reply(ok, success)
+	s.route("PUT", "/companies/{companyID}/members/{userID}", SetCompanyMemberRoleHandler, "Change a member's role").
+		body(CompanyRoleRequest{}, "role").
+		enum("role", companyRoles...).
+		reply(ok, success)
+	s.route("DELETE", "/companies/{companyID}/members/{userID}", RemoveCompanyMemberHandler, "Remove a member").
+		reply(ok, success)
+	s.route("GET", "/companies/{companyID}/invites", GetCompanyInvitesHandler, "Pending invites").
+		reply(ok, []service.CompanyInviteView{})
+	s.route("POST", "/companies/{companyID}/invites", InviteCompanyMemberHandler, "Invite someone by email").
+		body(CompanyInviteRequest{}, "email", "role").
+		enum("role", companyRoles...).
+		reply(created, service.CompanyInviteView{})
+	s.route("DELETE", "/companies/{companyID}/invites/{inviteID}", RevokeCompanyInviteHandler, "Revoke an invite").
+		reply(ok, success)
+
+	s.section("Posts", "")
+	s.route("GET", "/users/feed", GetFeedHandler, "Posts of followed users, newest first").
+		reply(ok, posts)
+
+	s.section("Notifications", "Activity notifications and badges")
+	s.route("GET", "/notifications", GetNotificationsHandler, "Notifications, newest first").
+		query("limit", openapi.Integer(), "defaults to 50").
+		reply(ok, openapi.Array(s.component("Notification", notificationSchema())))
+	s.route("GET", "/notifications/unread-count", GetUnreadCountHandler, "Unread notification and message badges").
+		reply(ok, UnreadCountResponse{})
+	s.route("PUT", "/notifications/{notificationID}/read", MarkNotificationReadHandler, "Mark a notification as read").
+		reply(ok, success)
+	s.route("PUT", "/notifications/mark-all-read", MarkAllNotificationsReadHandler, "Mark every notification as read").
+		reply(ok, success)
+
+	s.section("Admin", "Moderation tools, admins only")
+	s.route("GET", "/admin/reports", GetReportsHandler, "Reports, oldest open first").
+		query("status", &openapi.Schema{Type: "string", Enum: []string{model.ReportStatusOpen, model.ReportStatusActioned, model.ReportStatusDismissed}}, "").
+		query("target_type", &openapi.Schema{Type: "string", Enum: []string{model.ReportTargetPost, model.ReportTargetComment, model.ReportTargetUser}}, "").
+		paged().
+		reply(ok, service.ReportPage{})
+	s.route("POST", "/admin/reports/{reportID}/dismiss", DismissReportHandler, "Dismiss a report").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/posts/{postID}/hide", HidePostHandler, "Hide a post").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/posts/{postID}/unhide", UnhidePostHandler, "Show a hidden post again").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("DELETE", "/admin/posts/{postID}", RemovePostHandler, "Remove a post").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/comments/{commentID}/hide", HideCommentHandler, "Hide a comment").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/comments/{commentID}/unhide", UnhideCommentHandler, "Show a hidden comment again").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("DELETE", "/admin/comments/{commentID}", RemoveCommentHandler, "Remove a comment").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/users/{userID}/suspend", SuspendUserHandler, "Suspend a user, until a date or indefinitely").
+		body(SuspendRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/users/{userID}/unsuspend", UnsuspendUserHandler, "Lift a suspension").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("POST", "/admin/users/{userID}/recruiter", GrantRecruiterHandler, "Let a user post jobs").
+		reply(ok, success)
+	s.route("DELETE", "/admin/users/{userID}/recruiter", RevokeRecruiterHandler, "Stop a user posting jobs").
+		reply(ok, success)
+	s.route("DELETE", "/admin/users/{userID}/2fa", AdminDisableTwoFactorHandler, "Turn off a user's two-factor authentication").
+		body(ModerationRequest{}).optionalBody().
+		reply(ok, success)
+	s.route("GET", "/admin/audit-log", GetModerationLogHandler, "Moderation actions, newest first").
+		query("target_type", textParam, "").
+		query("admin_id", idParam, "").
+		query("target_id", idParam, "").
+		paged().
+		reply(ok, service.ModerationLogPage{})
+	s.route("GET", "/admin/security-events", GetSecurityEventsHandler, "Security events of every account, newest first").
+		query("user_id", idParam, "account the event is about").
+		query("actor_id", idParam, "who caused the event").
+		query("type", textParam, "e.g. login_failed").
+		query("ip", textParam, "").
+		query("from", openapi.DateTime(), "inclusive").
+		query("to", openapi.DateTime(), "exclusive").
+		paged().
+		reply(ok, service.AuditEventPage{})
+	s.route("GET", "/admin/outbox/dead", GetDeadEmailsHandler, "Emails that ran out of delivery attempts").
+		paged().
+		reply(ok, service.OutboxEmailPage{})
+	s.route("POST", "/admin/outbox/{emailID}/retry", RetryDeadEmailHandler, "Queue a dead email again").
+		reply(ok, success)
+}
+
+// notificationSchema describes the rows GetUserNotificationsWithDetails returns
+func notificationSchema() *openapi.Schema {
+	props := openapi.Props{
+		"id":                 openapi.Integer(),
+		"user_id":            openapi.Integer(),
+		"from_user_id":       openapi.Integer(),
+		"post_id":            openapi.Nullable(openapi.Integer()),
+		"is_read":            openapi.Boolean(),
+		"type":               openapi.String(),
+		"message":            openapi.Nullable(openapi.String()),
+		"created_at":         openapi.DateTime(),
+		"from_user_name":     openapi.String(),
+		"from_user_username": openapi.String(),
+		"from_user_photo":    openapi.String(),
+	}
+	schema := openapi.Object(props)
+	for name := range props {
+		schema.Required = append(schema.Required, name)
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/mail"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/service"
 )
 
@@ -27,43 +28,31 @@ func SendPasswordResetCodeHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req SendPasswordResetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	// Validate email is not empty
 	if req.Email == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Email cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Email cannot be empty"))
 		return
 	}
 
 	// Validate email format
 	if _, err := mail.ParseAddress(req.Email); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid email format"})
+		apperr.Write(w, r, apperr.Invalid("Invalid email format"))
 		return
 	}
 
 	// ✅ All validations passed, now send the reset code
-	err := service.SendPasswordResetCode(req.Email)
+	err := service.SendPasswordResetCode(r.Context(), req.Email)
 	if err != nil {
-		switch err {
-		case service.ErrPasswordResetUserNotFound:
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(map[string]string{"error": "User not found"})
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to send reset code"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Password reset code sent to email",
-	})
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Password reset code sent to email"})
 }
 
 // ResetPasswordHandler - Step 2: Reset password and delete code from database
@@ -72,51 +61,32 @@ func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req ResetPasswordRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	// Validate required fields
 	if req.Email == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Email cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Email cannot be empty"))
 		return
 	}
 
 	if req.Code == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Code cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Code cannot be empty"))
 		return
 	}
 
 	if req.NewPassword == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "New password cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("New password cannot be empty"))
 		return
 	}
 
-	if len(req.NewPassword) < 8 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"error": "Password must be at least 8 characters"})
-		return
-	}
-
-	err := service.ResetPassword(req.Email, req.Code, req.NewPassword)
+	err := service.ResetPassword(r.Context(), req.Email, req.Code, req.NewPassword)
 	if err != nil {
-		switch err {
-		case service.ErrInvalidResetCode:
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Invalid or expired code"})
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(map[string]string{"error": "Failed to reset password"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message": "Password reset successfully",
-	})
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Password reset successfully"})
 }
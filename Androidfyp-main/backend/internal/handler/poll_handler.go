@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type PollVoteRequest struct {
+	OptionIDs []string `json:"option_ids"`
+}
+
+// POST /posts/{postID}/vote
+func VotePollHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req PollVoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	optionIDs := make([]uint64, 0, len(req.OptionIDs))
+	for _, raw := range req.OptionIDs {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Invalid option ID"))
+			return
+		}
+		optionIDs = append(optionIDs, id)
+	}
+
+	poll, err := service.VoteInPoll(userID, postID, optionIDs)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(poll)
+}
@@ -2,11 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/model"
 	"wazzafak_back/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -14,16 +17,138 @@ import (
 
 // Extended PostResponse for full Android UI support
 type PostResponse struct {
-	ID            string `json:"id"`
-	UserID        string `json:"user_id"`
-	PhotoURL      string `json:"photo_url"`
-	Content       string `json:"content"`
-	LikesCount    int    `json:"likes_count"`
-	CommentsCount int    `json:"comments_count"`
-	IsLiked       bool   `json:"is_liked"`
-	IsFollowing   bool   `json:"is_following"`
-	CreatedAt     string `json:"created_at"`
-	IsOwner       bool   `json:"is_owner"` // ✅ Add this line
+	ID              string            `json:"id"`
+	UserID          string            `json:"user_id"`
+	Kind            string            `json:"kind"`
+	PhotoURL        string            `json:"photo_url"`
+	Content         string            `json:"content"`
+	LikesCount      int               `json:"likes_count"`
+	CommentsCount   int               `json:"comments_count"`
+	RepostsCount    int               `json:"reposts_count"`
+	IsLiked         bool              `json:"is_liked"`
+	IsFollowing     bool              `json:"is_following"`
+	CreatedAt       string            `json:"created_at"`
+	IsOwner         bool              `json:"is_owner"` // ✅ Add this line
+	IsBookmarked    bool              `json:"is_bookmarked"`
+	IsReposted      bool              `json:"is_reposted"`
+	RepostOf        *PostResponse     `json:"repost_of,omitempty"`        // the shared post, for reposts and quotes
+	OriginalDeleted bool              `json:"original_deleted,omitempty"` // quote whose original was deleted
+	Status          string            `json:"status"`
+	PublishAt       string            `json:"publish_at,omitempty"` // scheduled posts only
+	Poll            *service.PollView `json:"poll,omitempty"`       // poll posts only
+	Job             *service.JobView  `json:"job,omitempty"`        // job posts only
+}
+
+type PostRequest struct {
+	PhotoURL  string       `json:"photo_url"`
+	Content   string       `json:"content"`
+	Status    string       `json:"status"`     // optional: draft, scheduled or published
+	PublishAt *time.Time   `json:"publish_at"` // optional: RFC3339, schedules the post
+	Poll      *PollRequest `json:"poll"`       // optional: makes this a poll, content is the question
+}
+
+type PollRequest struct {
+	Options        []string   `json:"options"`
+	MultipleChoice bool       `json:"multiple_choice"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+func (req PostRequest) toInput() service.PostInput {
+	input := service.PostInput{
+		PhotoURL:  req.PhotoURL,
+		Content:   req.Content,
+		Status:    req.Status,
+		PublishAt: req.PublishAt,
+	}
+	if req.Poll != nil {
+		input.Poll = &service.PollInput{
+			Options:        req.Poll.Options,
+			MultipleChoice: req.Poll.MultipleChoice,
+			ExpiresAt:      req.Poll.ExpiresAt,
+		}
+	}
+	return input
+}
+
+// buildPostResponse fills the counters and viewer-specific flags for one post,
+// and embeds the shared post (one level deep) for reposts and quotes.
+// An original that is no longer published is shown as deleted.
+func buildPostResponse(post model.Post, viewerID uint64) PostResponse {
+	response := buildPostSummary(post, viewerID)
+
+	if post.RepostOfID != nil && !post.OriginalDeleted {
+		original, err := service.GetPostByID(*post.RepostOfID)
+		switch {
+		case err == nil && original.Status == model.PostStatusPublished:
+			embedded := buildPostSummary(*original, viewerID)
+			response.RepostOf = &embedded
+		case err == nil || errors.Is(err, service.ErrPostNotFound):
+			response.OriginalDeleted = true
+		}
+	}
+
+	return response
+}
+
+func buildPostSummary(post model.Post, viewerID uint64) PostResponse {
+	likesCount, _ := service.GetLikesCount(post.ID)
+	commentsCount, _ := service.GetCommentsCount(post.ID)
+	repostsCount, _ := service.GetRepostsCount(post.ID)
+	isLiked, _ := service.HasUserLiked(viewerID, post.ID)
+	isBookmarked, _ := service.HasUserBookmarked(viewerID, post.ID)
+	isReposted, _ := service.HasUserReposted(viewerID, post.ID)
+
+	isFollowing := false
+	if post.UserID != viewerID {
+		isFollowing, _ = service.IsFollowing(viewerID, post.UserID)
+	}
+
+	kind := post.Kind
+	if kind == "" {
+		kind = model.PostKindPost
+	}
+
+	status := post.Status
+	if status == "" {
+		status = model.PostStatusPublished
+	}
+
+	publishAt := ""
+	if post.PublishAt != nil {
+		publishAt = post.PublishAt.Format(time.RFC3339)
+	}
+
+	var poll *service.PollView
+	if kind == model.PostKindPoll {
+		poll, _ = service.GetPollView(post.ID, viewerID, post.UserID)
+	}
+
+	var job *service.JobView
+	if kind == model.PostKindJob {
+		job, _ = service.GetJobForPost(post.ID, viewerID)
+	}
+
+	return PostResponse{
+		ID:              strconv.FormatUint(post.ID, 10),
+		UserID:          strconv.FormatUint(post.UserID, 10),
+		Kind:            kind,
+		PhotoURL:        post.PhotoURL,
+		Content:         post.Content,
+		LikesCount:      likesCount,
+		CommentsCount:   commentsCount,
+		RepostsCount:    repostsCount,
+		IsLiked:         isLiked,
+		IsFollowing:     isFollowing,
+		CreatedAt:       post.CreatedAt.Format(time.RFC3339),
+		IsOwner:         post.UserID == viewerID,
+		IsBookmarked:    isBookmarked,
+		IsReposted:      isReposted,
+		OriginalDeleted: post.OriginalDeleted,
+		Status:          status,
+		PublishAt:       publishAt,
+		Poll:            poll,
+		Job:             job,
+	}
 }
 
 // ============ Create Post ============
@@ -32,35 +157,127 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized: user ID not found in token", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
-	var input struct {
-		PhotoURL string `json:"photo_url"`
-		Content  string `json:"content"`
+	var input PostRequest
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
+		return
+	}
+
+	post, err := service.CreatePost(r.Context(), userID, input.toInput())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	status := http.StatusCreated
+	message := "Post created successfully"
+	switch post.Status {
+	case model.PostStatusDraft:
+		message = "Draft saved successfully"
+	case model.PostStatusScheduled:
+		message = "Post scheduled successfully"
+	case model.PostStatusHidden:
+		status = http.StatusAccepted
+		message = "Post submitted for review"
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: message})
+}
+
+// ============ Edit Draft / Scheduled Post ============
+func UpdatePostHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
 	}
 
+	var input PostRequest
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
-	err := service.CreatePost(userID, input.PhotoURL, input.Content)
+	post, err := service.UpdateUnpublishedPost(r.Context(), postID, userID, input.toInput())
 	if err != nil {
-		if err == service.ErrInvalidPostInput {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to create post"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(SuccessResponse{Message: "Post created successfully"})
+	w.WriteHeader(heldStatus(post.Status == model.PostStatusHidden, http.StatusOK))
+	json.NewEncoder(w).Encode(buildPostResponse(*post, userID))
+}
+
+// ============ Publish Draft / Scheduled Post Now ============
+func PublishPostHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
+	}
+
+	post, err := service.PublishPostNow(r.Context(), postID, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(heldStatus(post.Status == model.PostStatusHidden, http.StatusOK))
+	json.NewEncoder(w).Encode(buildPostResponse(*post, userID))
+}
+
+// heldStatus is 202 Accepted for content the filter held for review
+func heldStatus(held bool, status int) int {
+	if held {
+		return http.StatusAccepted
+	}
+	return status
+}
+
+// ============ Get My Drafts & Scheduled Posts ============
+func GetMyDraftsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	posts, err := service.GetMyUnpublishedPosts(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	response := []PostResponse{}
+	for _, post := range posts {
+		response = append(response, buildPostResponse(post, userID))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
 }
 
 // ============ Delete Post ============
@@ -69,37 +286,60 @@ func DeletePost(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
 		return
 	}
 
 	postIDStr := chi.URLParam(r, "postID")
 	postID, err := strconv.ParseUint(postIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid post ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
-	err = service.DeletePost(postID, userID)
+	restoreUntil, err := service.DeletePost(postID, userID)
 	if err != nil {
-		switch err {
-		case service.ErrPostNotFound:
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
-		case service.ErrUnauthorized:
-			w.WriteHeader(http.StatusForbidden)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "You can only delete your own posts"})
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to delete post"})
-		}
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(DeletedResponse{
+		Message:      "Post deleted successfully",
+		RestoreUntil: restoreUntil.Format(time.RFC3339),
+	})
+}
+
+// DeletedResponse tells the client how long "undo" is offered
+type DeletedResponse struct {
+	Message      string `json:"message"`
+	RestoreUntil string `json:"restore_until"`
+}
+
+// ============ Restore a Deleted Post (undo) ============
+func RestorePostHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
+		return
+	}
+
+	postID, err := strconv.ParseUint(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
+		return
+	}
+
+	post, err := service.RestorePost(postID, userID)
+	if err != nil {
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(SuccessResponse{Message: "Post deleted successfully"})
+	json.NewEncoder(w).Encode(buildPostResponse(*post, userID))
 }
 
 // ============ Get Feed (Following) ============
@@ -108,34 +348,19 @@ func GetFeedHandler(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized: user ID not found in token", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
 	posts, err := service.GetUserFeed(userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve feed"})
+		apperr.Write(w, r, err)
 		return
 	}
 
-	var response []PostResponse
+	response := []PostResponse{}
 	for _, post := range posts {
-		likesCount, _ := service.GetLikesCount(post.ID)
-		commentsCount, _ := service.GetCommentsCount(post.ID)
-		isLiked, _ := service.HasUserLiked(userID, post.ID)
-		isFollowing, _ := service.IsFollowing(userID, post.UserID)
-
-		response = append(response, PostResponse{
-			ID:            strconv.FormatUint(post.ID, 10),
-			UserID:        strconv.FormatUint(post.UserID, 10),
-			PhotoURL:      post.PhotoURL,
-			Content:       post.Content,
-			LikesCount:    likesCount,
-			CommentsCount: commentsCount,
-			IsLiked:       isLiked,
-			IsFollowing:   isFollowing,
-		})
+		response = append(response, buildPostResponse(post, userID))
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -148,15 +373,13 @@ func GetUserPosts(w http.ResponseWriter, r *http.Request) {
 
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Username is required"})
+		apperr.Write(w, r, apperr.Invalid("Username is required"))
 		return
 	}
 
 	posts, err := service.GetPostsByUsername(username)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve posts"})
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -170,27 +393,25 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 
 	postID := chi.URLParam(r, "postID")
 	if postID == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Post ID is required"})
+		apperr.Write(w, r, apperr.Invalid("Post ID is required"))
 		return
 	}
 
 	id, err := strconv.ParseUint(postID, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid post ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid post ID"))
 		return
 	}
 
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+
 	post, err := service.GetPostByID(id)
+	if err == nil && post.Status != model.PostStatusPublished && post.UserID != userID {
+		// Drafts and scheduled posts are only visible to their author
+		err = service.ErrPostNotFound
+	}
 	if err != nil {
-		if err.Error() == "post not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Post not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve post"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -204,36 +425,19 @@ func GetAllPostsHandler(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized: user ID not found in token", http.StatusUnauthorized)
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
 	posts, err := service.GetAllPosts()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve all posts"})
+		apperr.Write(w, r, err)
 		return
 	}
 
-	var response []PostResponse
+	response := []PostResponse{}
 	for _, post := range posts {
-		likesCount, _ := service.GetLikesCount(post.ID)
-		commentsCount, _ := service.GetCommentsCount(post.ID)
-		isLiked, _ := service.HasUserLiked(userID, post.ID)
-		isFollowing, _ := service.IsFollowing(userID, post.UserID)
-
-		response = append(response, PostResponse{
-			ID:            strconv.FormatUint(post.ID, 10),
-			UserID:        strconv.FormatUint(post.UserID, 10),
-			PhotoURL:      post.PhotoURL,
-			Content:       post.Content,
-			LikesCount:    likesCount,
-			CommentsCount: commentsCount,
-			IsLiked:       isLiked,
-			IsFollowing:   isFollowing,
-			CreatedAt:     post.CreatedAt.Format(time.RFC3339),
-			IsOwner:       post.UserID == userID, // ✅ added
-		})
+		response = append(response, buildPostResponse(post, userID))
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -246,35 +450,19 @@ func GetMyPostsHandler(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("User ID not found in token"))
 		return
 	}
 
 	posts, err := service.GetMyPosts(userID)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve posts"})
+		apperr.Write(w, r, err)
 		return
 	}
 
-	var response []PostResponse
+	response := []PostResponse{}
 	for _, post := range posts {
-		likesCount, _ := service.GetLikesCount(post.ID)
-		commentsCount, _ := service.GetCommentsCount(post.ID)
-
-		response = append(response, PostResponse{
-			ID:            strconv.FormatUint(post.ID, 10),
-			UserID:        strconv.FormatUint(post.UserID, 10),
-			PhotoURL:      post.PhotoURL,
-			Content:       post.Content,
-			LikesCount:    likesCount,
-			CommentsCount: commentsCount,
-			IsLiked:       false,
-			IsFollowing:   false,
-			CreatedAt:     post.CreatedAt.Format(time.RFC3339),
-			IsOwner:       true, // always true because JWT user
-		})
+		response = append(response, buildPostResponse(post, userID))
 	}
 
 	w.WriteHeader(http.StatusOK)
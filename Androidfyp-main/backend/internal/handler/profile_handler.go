@@ -0,0 +1,444 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AboutRequest struct {
+	Headline string `json:"headline"`
+	Bio      string `json:"bio"`
+}
+
+type SkillsRequest struct {
+	Skills []string `json:"skills"`
+}
+
+type SkillRequest struct {
+	Name string `json:"name"`
+}
+
+type ExperienceRequest struct {
+	Title       string `json:"title"`
+	Company     string `json:"company"`
+	Location    string `json:"location"`
+	StartDate   string `json:"start_date"` // YYYY-MM
+	EndDate     string `json:"end_date"`   // YYYY-MM, empty for the current job
+	Description string `json:"description"`
+}
+
+func (req ExperienceRequest) input() service.ExperienceInput {
+	return service.ExperienceInput{
+		Title:       req.Title,
+		Company:     req.Company,
+		Location:    req.Location,
+		StartDate:   req.StartDate,
+		EndDate:     req.EndDate,
+		Description: req.Description,
+	}
+}
+
+type EducationRequest struct {
+	School       string `json:"school"`
+	Degree       string `json:"degree"`
+	FieldOfStudy string `json:"field_of_study"`
+	StartDate    string `json:"start_date"` // YYYY-MM
+	EndDate      string `json:"end_date"`   // YYYY-MM, empty while studying
+	Description  string `json:"description"`
+}
+
+func (req EducationRequest) input() service.EducationInput {
+	return service.EducationInput{
+		School:       req.School,
+		Degree:       req.Degree,
+		FieldOfStudy: req.FieldOfStudy,
+		StartDate:    req.StartDate,
+		EndDate:      req.EndDate,
+		Description:  req.Description,
+	}
+}
+
+type LinkRequest struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// GET /users/me/profile
+func GetMyProfileHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := service.GetProfile(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(profile)
+}
+
+// PUT /users/me/profile
+func UpdateAboutHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req AboutRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	if err := service.UpdateAbout(userID, req.Headline, req.Bio); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Profile updated"})
+}
+
+// PUT /users/me/profile/skills — replaces the whole list
+func ReplaceSkillsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req SkillsRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	skills, err := service.ReplaceSkills(userID, req.Skills)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(skills)
+}
+
+// POST /users/me/profile/skills
+func AddSkillHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req SkillRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	skill, err := service.AddSkill(userID, req.Name)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(skill)
+}
+
+// DELETE /users/me/profile/skills/{skill}
+func RemoveSkillHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.RemoveSkill(userID, skillParam(r)); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Skill removed"})
+}
+
+// POST /users/me/profile/experience
+func AddExperienceHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req ExperienceRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	entry, err := service.AddExperience(userID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// PUT /users/me/profile/experience/{entryID}
+func UpdateExperienceHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, entryID, ok := profileEntryParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req ExperienceRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	entry, err := service.UpdateExperience(userID, entryID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// DELETE /users/me/profile/experience/{entryID}
+func DeleteExperienceHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, entryID, ok := profileEntryParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteExperience(userID, entryID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Experience deleted"})
+}
+
+// POST /users/me/profile/education
+func AddEducationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req EducationRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	entry, err := service.AddEducation(userID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// PUT /users/me/profile/education/{entryID}
+func UpdateEducationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, entryID, ok := profileEntryParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req EducationRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	entry, err := service.UpdateEducation(userID, entryID, req.input())
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// DELETE /users/me/profile/education/{entryID}
+func DeleteEducationHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, entryID, ok := profileEntryParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteEducation(userID, entryID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Education deleted"})
+}
+
+// POST /users/me/profile/links
+func AddLinkHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req LinkRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	link, err := service.AddLink(userID, req.Label, req.URL)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(link)
+}
+
+// PUT /users/me/profile/links/{entryID}
+func UpdateLinkHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, entryID, ok := profileEntryParams(w, r)
+	if !ok {
+		return
+	}
+
+	var req LinkRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	link, err := service.UpdateLink(userID, entryID, req.Label, req.URL)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(link)
+}
+
+// DELETE /users/me/profile/links/{entryID}
+func DeleteLinkHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, entryID, ok := profileEntryParams(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.DeleteLink(userID, entryID); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Link deleted"})
+}
+
+// GET /users/search?q=...&skill=...&company=...&school=...&cursor=...&limit=...
+func SearchUsersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	filter := repository.UserSearchFilter{
+		Query:   query.Get("q"),
+		Skill:   query.Get("skill"),
+		Company: query.Get("company"),
+		School:  query.Get("school"),
+	}
+
+	page, err := service.SearchUsers(filter, query.Get("cursor"), limit)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(page)
+}
+
+func profileUser(w http.ResponseWriter, r *http.Request) (uint64, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return 0, false
+	}
+	return userID, true
+}
+
+// profileEntryParams reads the caller and the {entryID} URL param
+func profileEntryParams(w http.ResponseWriter, r *http.Request) (uint64, uint64, bool) {
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return 0, 0, false
+	}
+
+	entryID, err := strconv.ParseUint(chi.URLParam(r, "entryID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid entry ID"))
+		return 0, 0, false
+	}
+	return userID, entryID, true
+}
+
+// skillParam reads the {skill} URL param; skill names may contain spaces
+// and other characters that arrive escaped
+func skillParam(r *http.Request) string {
+	raw := chi.URLParam(r, "skill")
+	if skill, err := url.PathUnescape(raw); err == nil {
+		return skill
+	}
+	return raw
+}
+
+func decodeProfileRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ReportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details"`
+}
+
+// POST /posts/{postID}/report
+func ReportPostHandler(w http.ResponseWriter, r *http.Request) {
+	reportContent(w, r, model.ReportTargetPost, "postID", "Invalid post ID")
+}
+
+// POST /posts/{postID}/comments/{commentID}/report
+func ReportCommentHandler(w http.ResponseWriter, r *http.Request) {
+	reportContent(w, r, model.ReportTargetComment, "commentID", "Invalid comment ID")
+}
+
+// POST /users/id/{userID}/report
+func ReportUserHandler(w http.ResponseWriter, r *http.Request) {
+	reportContent(w, r, model.ReportTargetUser, "userID", "Invalid user ID")
+}
+
+func reportContent(w http.ResponseWriter, r *http.Request, targetType, param, invalidIDMessage string) {
+	w.Header().Set("Content-Type", "application/json")
+
+	targetID, err := strconv.ParseUint(chi.URLParam(r, param), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid(invalidIDMessage))
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	var req ReportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid JSON body"))
+		return
+	}
+
+	if _, err := service.ReportContent(userID, targetType, targetID, req.Reason, req.Details); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Report submitted successfully"})
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code"` // authenticator or recovery code
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// GET /users/me/2fa
+func GetTwoFactorStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	status, err := service.GetTwoFactorStatus(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(status)
+}
+
+// POST /users/me/2fa/setup — returns the secret and otpauth:// URI
+func SetupTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store") // carries the authenticator secret
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	setup, err := service.SetupTwoFactor(userID)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(setup)
+}
+
+// POST /users/me/2fa/enable — returns the recovery codes once
+func EnableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store") // carries the recovery codes
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	codes, err := service.EnableTwoFactor(r.Context(), userID, req.Code)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// POST /users/me/2fa/recovery-codes — replaces every recovery code
+func RegenerateRecoveryCodesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store") // carries the recovery codes
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	codes, err := service.RegenerateRecoveryCodes(r.Context(), userID, req.Code)
+	if err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// POST /users/me/2fa/disable
+func DisableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, ok := profileUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if !decodeProfileRequest(w, r, &req) {
+		return
+	}
+
+	if err := service.DisableTwoFactor(r.Context(), userID, req.Password, req.Code); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Two-factor authentication disabled"})
+}
+
+// DELETE /admin/users/{userID}/2fa
+func AdminDisableTwoFactorHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := strconv.ParseUint(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
+		return
+	}
+
+	adminID, req, ok := decodeModerationRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := service.AdminDisableTwoFactor(r.Context(), adminID, userID, req.Reason); err != nil {
+		apperr.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(SuccessResponse{Message: "Two-factor authentication disabled"})
+}
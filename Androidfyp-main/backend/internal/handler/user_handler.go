@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"wazzafak_back/internal/apperr"
 	"wazzafak_back/internal/middleware"
 	"wazzafak_back/internal/service"
 
@@ -17,8 +18,12 @@ type SuccessResponse struct {
 	Message string `json:"message"`
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type PhotoResponse struct {
+	PhotoURL string `json:"photo_url"`
 }
 
 // Requires JWT
@@ -27,8 +32,7 @@ func UpdatePhoto(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
@@ -37,20 +41,17 @@ func UpdatePhoto(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	if input.PhotoURL == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Photo URL cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Photo URL cannot be empty"))
 		return
 	}
 
-	if err := service.UpdateUserPhoto(userID, input.PhotoURL); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to update photo"})
+	if err := service.UpdateUserPhoto(r.Context(), userID, input.PhotoURL); err != nil {
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -64,14 +65,12 @@ func DeletePhoto(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
-	if err := service.DeleteUserPhoto(userID, defaultPhotoURL); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to reset photo"})
+	if err := service.DeleteUserPhoto(r.Context(), userID, defaultPhotoURL); err != nil {
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -85,8 +84,7 @@ func UpdateUserName(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
@@ -95,25 +93,17 @@ func UpdateUserName(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid input"})
+		apperr.Write(w, r, apperr.Invalid("Invalid input"))
 		return
 	}
 
 	if input.Name == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Name cannot be empty"})
+		apperr.Write(w, r, apperr.Invalid("Name cannot be empty"))
 		return
 	}
 
-	if err := service.UpdateUserName(userID, input.Name); err != nil {
-		if err.Error() == "user not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "User not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to update name"})
-		}
+	if err := service.UpdateUserName(r.Context(), userID, input.Name); err != nil {
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -125,7 +115,7 @@ func UpdateUserName(w http.ResponseWriter, r *http.Request) {
 func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
 }
 
 // GetUserProfile returns the authenticated user's profile
@@ -134,20 +124,13 @@ func GetUserProfile(w http.ResponseWriter, r *http.Request) {
 
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Unauthorized: user ID not found in token"})
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized: user ID not found in token"))
 		return
 	}
 
 	user, err := service.GetUserByID(userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "User not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve user"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -161,20 +144,14 @@ func GetUserByUsername(w http.ResponseWriter, r *http.Request) {
 
 	username := chi.URLParam(r, "username")
 	if username == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Username is required"})
+		apperr.Write(w, r, apperr.Invalid("Username is required"))
 		return
 	}
 
-	user, err := service.GetUserByUsername(username)
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	user, err := service.GetUserWithProfile(username, viewerID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "User not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve user"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -188,28 +165,20 @@ func GetUserByIDHandler(w http.ResponseWriter, r *http.Request) {
 
 	userIDStr := chi.URLParam(r, "userID")
 	if userIDStr == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID is required"})
+		apperr.Write(w, r, apperr.Invalid("User ID is required"))
 		return
 	}
 
 	// Convert userID string to uint64
 	userID, err := strconv.ParseUint(userIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
 		return
 	}
 
 	user, err := service.GetUserByID(userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "User not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve user"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
@@ -223,31 +192,23 @@ func GetUserPhotoByID(w http.ResponseWriter, r *http.Request) {
 
 	userIDStr := chi.URLParam(r, "userID")
 	if userIDStr == "" {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "User ID is required"})
+		apperr.Write(w, r, apperr.Invalid("User ID is required"))
 		return
 	}
 
 	// Convert userID string to uint64
 	userID, err := strconv.ParseUint(userIDStr, 10, 64)
 	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Invalid user ID"})
+		apperr.Write(w, r, apperr.Invalid("Invalid user ID"))
 		return
 	}
 
 	photoURL, err := service.GetUserPhotoByID(userID)
 	if err != nil {
-		if err.Error() == "user not found" {
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "User not found"})
-		} else {
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: "Failed to retrieve user photo"})
-		}
+		apperr.Write(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{"photo_url": photoURL})
+	json.NewEncoder(w).Encode(PhotoResponse{PhotoURL: photoURL})
 }
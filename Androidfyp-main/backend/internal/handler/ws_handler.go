@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/realtime"
+	"wazzafak_back/internal/service"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsPongWait       = 60 * time.Second
+	wsPingPeriod     = wsPongWait * 9 / 10
+	wsMaxFrameLength = 8 * 1024
+)
+
+// The app authenticates with the Authorization header, not cookies, so
+// cross-origin upgrades carry no ambient credentials and can be allowed
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsFrame is what the app sends over the socket:
+//
+//	{"type": "send",   "conversation_id": "1", "content": "hi"}
+//	{"type": "typing", "conversation_id": "1"}
+//	{"type": "read",   "conversation_id": "1", "message_id": "42"}
+type wsFrame struct {
+	Type           string `json:"type"`
+	ConversationID string `json:"conversation_id"`
+	Content        string `json:"content"`
+	MessageID      string `json:"message_id"`
+}
+
+// GET /ws — upgrades to a WebSocket that streams message, typing and read events
+func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the error response
+		return
+	}
+
+	client := realtime.Default.Register(userID)
+	go writeSocket(conn, client)
+	readSocket(conn, client)
+}
+
+// readSocket handles frames from the app until the connection closes
+func readSocket(conn *websocket.Conn, client *realtime.Client) {
+	defer func() {
+		realtime.Default.Unregister(client)
+		conn.Close()
+	}()
+
+	conn.SetReadLimit(wsMaxFrameLength)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		var frame wsFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.Error("websocket failed", "user_id", client.UserID, "error", err)
+			}
+			return
+		}
+
+		if err := handleFrame(client.UserID, frame); err != nil {
+			realtime.Default.Reply(client, realtime.Event{
+				Type:           realtime.EventError,
+				ConversationID: frame.ConversationID,
+				Error:          frameErrorMessage(client.UserID, err),
+			})
+		}
+	}
+}
+
+func handleFrame(userID uint64, frame wsFrame) error {
+	conversationID, err := strconv.ParseUint(frame.ConversationID, 10, 64)
+	if err != nil {
+		return service.ErrConversationNotFound
+	}
+
+	switch frame.Type {
+	case "send":
+		_, err = service.SendMessage(userID, conversationID, frame.Content)
+	case "typing":
+		err = service.SendTyping(userID, conversationID)
+	case "read":
+		messageID, parseErr := strconv.ParseUint(frame.MessageID, 10, 64)
+		if parseErr != nil {
+			return service.ErrInvalidReadMarker
+		}
+		err = service.MarkConversationRead(userID, conversationID, messageID)
+	default:
+		return errUnknownFrame
+	}
+	return err
+}
+
+var errUnknownFrame = apperr.Invalid("unknown frame type")
+
+// frameErrorMessage keeps database errors out of what the app sees
+func frameErrorMessage(userID uint64, err error) string {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) && apperr.CodeOf(err) != apperr.CodeInternal {
+		return appErr.Message
+	}
+	slog.Error("websocket frame failed", "user_id", userID, "error", err)
+	return "Something went wrong"
+}
+
+// writeSocket sends queued events and keeps the connection alive with pings
+func writeSocket(conn *websocket.Conn, client *realtime.Client) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case frame, ok := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				// The hub dropped this connection
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package logging sets up log/slog for the service and carries the request
+// ID and the signed-in user through a request's context, so every record
+// logged with that context can be traced back to the request.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Init makes slog's default logger, and with it the log package, write JSON
+// records to stdout. LOG_LEVEL (debug, info, warn, error) sets the minimum
+// level; LOG_FORMAT=text switches to key=value lines for local runs.
+func Init() {
+	options := &slog.HandlerOptions{Level: parseLevel(os.Getenv("LOG_LEVEL"))}
+
+	var h slog.Handler = slog.NewJSONHandler(os.Stdout, options)
+	if strings.EqualFold(os.Getenv("LOG_FORMAT"), "text") {
+		h = slog.NewTextHandler(os.Stdout, options)
+	}
+	slog.SetDefault(slog.New(contextHandler{h}))
+}
+
+func parseLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+type contextKey struct{}
+
+// request is shared by everything that runs under one request. The user is
+// only known once AuthMiddleware ran, after the outer middleware stored it.
+type request struct {
+	id     string
+	userID atomic.Uint64
+}
+
+// WithRequestID starts the logging scope of a request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &request{id: id})
+}
+
+// RequestID of the request ctx belongs to, "" outside a request
+func RequestID(ctx context.Context) string {
+	if req, ok := ctx.Value(contextKey{}).(*request); ok {
+		return req.id
+	}
+	return ""
+}
+
+// SetUserID records who made the request; the access log and every later
+// record of the request include it
+func SetUserID(ctx context.Context, userID uint64) {
+	if req, ok := ctx.Value(contextKey{}).(*request); ok {
+		req.userID.Store(userID)
+	}
+}
+
+// UserID of the request ctx belongs to, 0 when nobody is signed in
+func UserID(ctx context.Context) uint64 {
+	if req, ok := ctx.Value(contextKey{}).(*request); ok {
+		return req.userID.Load()
+	}
+	return 0
+}
+
+// contextHandler adds request_id and user_id to records logged with a
+// request's context, e.g. slog.InfoContext(r.Context(), ...)
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if req, ok := ctx.Value(contextKey{}).(*request); ok {
+		record.AddAttrs(slog.String("request_id", req.id))
+		if userID := req.userID.Load(); userID != 0 {
+			record.AddAttrs(slog.Uint64("user_id", userID))
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,92 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const (
+	brevoURL        = "https://api.brevo.com/v3/smtp/email"
+	brevoAccountURL = "https://api.brevo.com/v3/account"
+)
+
+// brevoRequest represents the payload for Brevo API
+type brevoRequest struct {
+	Sender      map[string]string   `json:"sender"`
+	To          []map[string]string `json:"to"`
+	Subject     string              `json:"subject"`
+	HTMLContent string              `json:"htmlContent"`
+	TextContent string              `json:"textContent,omitempty"`
+}
+
+// BrevoMailer sends through Brevo's transactional email HTTP API
+type BrevoMailer struct {
+	APIKey string
+	From   string
+	Client *http.Client
+}
+
+func (m *BrevoMailer) Send(ctx context.Context, msg Message) error {
+	payload := brevoRequest{
+		Sender: map[string]string{
+			"name":  senderName,
+			"email": m.From,
+		},
+		To: []map[string]string{
+			{"email": msg.To},
+		},
+		Subject:     msg.Subject,
+		HTMLContent: msg.HTML,
+		TextContent: msg.Text,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, brevoURL, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("api-key", m.APIKey)
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to send email, status: %s", resp.Status)
+	}
+	return nil
+}
+
+// Check fetches the account behind the API key, which fails when Brevo is
+// down or the key was revoked
+func (m *BrevoMailer) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, brevoAccountURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("accept", "application/json")
+	req.Header.Set("api-key", m.APIKey)
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("brevo account check failed, status: %s", resp.Status)
+	}
+	return nil
+}
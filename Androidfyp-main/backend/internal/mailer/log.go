@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// LogMailer is for local development: it writes each message to a file in
+// Dir, or to the log when Dir is empty, instead of sending it
+type LogMailer struct {
+	Dir string
+}
+
+func (m *LogMailer) Send(_ context.Context, msg Message) error {
+	if m.Dir == "" {
+		slog.Info("mail", "to", msg.To, "subject", msg.Subject, "text", msg.Text)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	safeTo := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%s.txt", time.Now().Format("20060102-150405.000000000"), safeTo)
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n\n----- HTML -----\n%s\n", msg.To, msg.Subject, msg.Text, msg.HTML)
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o644)
+}
@@ -0,0 +1,80 @@
+// Package mailer renders transactional emails and hands them to a provider.
+// Mail is never sent from a request: the service layer renders a Message into
+// the outbox table and a worker delivers it with retries (service.RunMailOutbox).
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const senderName = "Wazzafak"
+
+// Message is one rendered email
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string // plain-text alternative
+}
+
+// Mailer delivers a message through some provider
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Checker is implemented by mailers that can tell whether their provider is
+// reachable without sending anything
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// FromEnv builds the mailer picked by MAIL_PROVIDER: "brevo" (default),
+// "smtp", or "log" for local development
+func FromEnv() (Mailer, error) {
+	provider := os.Getenv("MAIL_PROVIDER")
+	if provider == "" {
+		provider = "brevo"
+	}
+	from := os.Getenv("EMAIL_FROM")
+
+	switch provider {
+	case "brevo":
+		apiKey := os.Getenv("BREVO_API_KEY")
+		if apiKey == "" || from == "" {
+			return nil, fmt.Errorf("EMAIL_FROM and BREVO_API_KEY must be set")
+		}
+		return &BrevoMailer{
+			APIKey: apiKey,
+			From:   from,
+			Client: &http.Client{Timeout: 15 * time.Second},
+		}, nil
+	case "smtp":
+		host := os.Getenv("SMTP_HOST")
+		if host == "" || from == "" {
+			return nil, fmt.Errorf("EMAIL_FROM and SMTP_HOST must be set")
+		}
+		port := 587
+		if raw := os.Getenv("SMTP_PORT"); raw != "" {
+			var err error
+			if port, err = strconv.Atoi(raw); err != nil {
+				return nil, fmt.Errorf("SMTP_PORT: %w", err)
+			}
+		}
+		return &SMTPMailer{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}, nil
+	case "log":
+		return &LogMailer{Dir: os.Getenv("MAIL_LOG_DIR")}, nil
+	default:
+		return nil, fmt.Errorf("unknown MAIL_PROVIDER %q", provider)
+	}
+}
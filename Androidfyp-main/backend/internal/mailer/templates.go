@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template names
+const (
+	TemplateVerificationCode = "verification_code"
+	TemplatePasswordReset    = "password_reset"
+	TemplateEmailChanged     = "email_changed"
+	TemplateCompanyInvite    = "company_invite"
+	TemplateAccountDeletion  = "account_deletion"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+type pageTemplates struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template // defines "subject" and "body"
+}
+
+var templates = loadTemplates(TemplateVerificationCode, TemplatePasswordReset, TemplateEmailChanged, TemplateCompanyInvite, TemplateAccountDeletion)
+
+// loadTemplates parses every template at start-up so a broken one fails fast
+func loadTemplates(names ...string) map[string]pageTemplates {
+	loaded := make(map[string]pageTemplates, len(names))
+	for _, name := range names {
+		loaded[name] = pageTemplates{
+			html: htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")),
+			text: texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/"+name+".txt")),
+		}
+	}
+	return loaded
+}
+
+// Render builds the message for template name addressed to `to`
+func Render(name, to string, data interface{}) (Message, error) {
+	page, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := page.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := page.text.ExecuteTemplate(&text, "body", data); err != nil {
+		return Message{}, err
+	}
+	if err := page.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
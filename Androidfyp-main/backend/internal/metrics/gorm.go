@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	dbQueryDuration = NewHistogram(
+		"db_query_duration_seconds",
+		"Time spent in database statements issued through GORM, by operation and table.",
+		DefaultBuckets,
+		"operation", "table",
+	)
+	dbQueryErrors = NewCounter(
+		"db_query_errors_total",
+		"Database statements that failed, not counting lookups that found no record.",
+		"operation", "table",
+	)
+)
+
+const startedKey = "metrics:started"
+
+// GormPlugin times every statement GORM runs. Register it with db.Use.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string { return "metrics" }
+
+func (GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", startStatement),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", finishStatement("create")),
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", startStatement),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", finishStatement("query")),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", startStatement),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", finishStatement("update")),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", startStatement),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", finishStatement("delete")),
+		callbacks.Row().Before("gorm:row").Register("metrics:before_row", startStatement),
+		callbacks.Row().After("gorm:row").Register("metrics:after_row", finishStatement("row")),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", startStatement),
+		callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", finishStatement("raw")),
+	)
+}
+
+func startStatement(db *gorm.DB) {
+	db.InstanceSet(startedKey, time.Now())
+}
+
+func finishStatement(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startedKey)
+		if !ok {
+			return
+		}
+		started, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		dbQueryDuration.Observe(time.Since(started).Seconds(), operation, table)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			dbQueryErrors.Inc(operation, table)
+		}
+	}
+}
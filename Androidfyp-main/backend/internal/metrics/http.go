@@ -0,0 +1,13 @@
+package metrics
+
+// HTTPRequestDuration is observed once per request by middleware.Observe.
+// Its _count series doubles as the request count per route and status.
+var HTTPRequestDuration = NewHistogram(
+	"http_request_duration_seconds",
+	"Time from the start of a request until its handler returned, by route and status.",
+	DefaultBuckets,
+	"method", "route", "status",
+)
+
+// HTTPRequestsInFlight is the number of requests being served right now
+var HTTPRequestsInFlight = NewGauge("http_requests_in_flight", "Requests currently being served.")
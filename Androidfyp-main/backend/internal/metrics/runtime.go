@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+var startTime = time.Now()
+
+func init() {
+	register(runtimeCollector{})
+}
+
+// runtimeCollector reports the Go runtime and process metrics the standard
+// Prometheus client exposes under the same names. Memory statistics are read
+// once per scrape.
+type runtimeCollector struct{}
+
+func (runtimeCollector) write(w *bufio.Writer) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	fmt.Fprintf(w, "# HELP go_info Information about the Go environment.\n# TYPE go_info gauge\ngo_info{version=%q} 1\n", runtime.Version())
+	sample(w, "go_goroutines", "gauge", "Number of goroutines that currently exist.", float64(runtime.NumGoroutine()))
+	sample(w, "go_threads", "gauge", "Number of OS threads created.", float64(threadCount()))
+	sample(w, "go_gc_duration_seconds_total", "counter", "Total time spent in GC stop-the-world pauses.", float64(stats.PauseTotalNs)/1e9)
+	sample(w, "go_gc_cycles_total", "counter", "Number of completed GC cycles.", float64(stats.NumGC))
+	sample(w, "go_memstats_alloc_bytes", "gauge", "Number of bytes allocated and still in use.", float64(stats.Alloc))
+	sample(w, "go_memstats_alloc_bytes_total", "counter", "Total number of bytes allocated, even if freed.", float64(stats.TotalAlloc))
+	sample(w, "go_memstats_heap_inuse_bytes", "gauge", "Number of heap bytes that are in use.", float64(stats.HeapInuse))
+	sample(w, "go_memstats_heap_objects", "gauge", "Number of allocated objects.", float64(stats.HeapObjects))
+	sample(w, "go_memstats_sys_bytes", "gauge", "Number of bytes obtained from the OS.", float64(stats.Sys))
+	sample(w, "go_memstats_next_gc_bytes", "gauge", "Heap size at which the next GC cycle starts.", float64(stats.NextGC))
+	sample(w, "process_start_time_seconds", "gauge", "Start time of the process since unix epoch in seconds.", float64(startTime.Unix()))
+}
+
+func threadCount() int {
+	n, _ := runtime.ThreadCreateProfile(nil)
+	return n
+}
+
+func sample(w *bufio.Writer, name, kind, help string, value float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", name, help, name, kind, name, formatFloat(value))
+}
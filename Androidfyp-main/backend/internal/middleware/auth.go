@@ -6,6 +6,14 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/logging"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/service"
 
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -18,18 +26,21 @@ type contextKey string
 // Exported key for other packages to use
 var UserCtxKey = contextKey("userID")
 
+// userRecordCtxKey holds the authenticated *model.User
+var userRecordCtxKey = contextKey("user")
+
 // AuthMiddleware verifies JWT token and sets user ID (uint64) in context
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, "Missing Authorization header", http.StatusUnauthorized)
+			apperr.Write(w, r, apperr.Unauthenticated("Missing Authorization header"))
 			return
 		}
 
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			http.Error(w, "Invalid Authorization header format", http.StatusUnauthorized)
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid Authorization header format"))
 			return
 		}
 
@@ -43,30 +54,58 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		})
 
 		if err != nil || !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid token"))
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid token claims"))
+			return
+		}
+
+		// Login challenge tokens only work on /login/2fa
+		if _, typed := claims["typ"]; typed {
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid token"))
 			return
 		}
 
 		userIDStr, ok := claims["sub"].(string)
 		if !ok {
-			http.Error(w, "Invalid token subject", http.StatusUnauthorized)
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid token subject"))
 			return
 		}
 
 		userID, err := strconv.ParseUint(userIDStr, 10, 64)
 		if err != nil {
-			http.Error(w, "Invalid user ID in token", http.StatusUnauthorized)
+			apperr.Write(w, r, apperr.Unauthenticated("Invalid user ID in token"))
 			return
 		}
 
-		// Put userID (uint64) in context
+		// Tokens of deleted or suspended accounts stop working right away
+		user, err := repository.GetUserByID(db.DB, userID)
+		if err != nil || user.AccountDeletedAt != nil {
+			apperr.Write(w, r, apperr.Unauthenticated("User not found"))
+			return
+		}
+		if user.IsSuspended(time.Now()) {
+			apperr.Write(w, r, apperr.Forbidden("Account suspended"))
+			return
+		}
+
+		// A password change signs out every session issued before it
+		if user.PasswordChangedAt != nil {
+			if service.TokenIssuedAt(claims).Before(*user.PasswordChangedAt) {
+				apperr.Write(w, r, apperr.Unauthenticated("Session expired, please log in again"))
+				return
+			}
+		}
+
+		logging.SetUserID(r.Context(), userID)
+
+		// Put userID (uint64) and the user in context
 		ctx := context.WithValue(r.Context(), UserCtxKey, userID)
+		ctx = context.WithValue(ctx, userRecordCtxKey, user)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -76,3 +115,21 @@ func GetUserIDFromContext(ctx context.Context) (uint64, bool) {
 	userID, ok := ctx.Value(UserCtxKey).(uint64)
 	return userID, ok
 }
+
+// GetUserFromContext returns the authenticated user loaded by AuthMiddleware
+func GetUserFromContext(ctx context.Context) (*model.User, bool) {
+	user, ok := ctx.Value(userRecordCtxKey).(*model.User)
+	return user, ok
+}
+
+// AdminOnly rejects requests from non-admin users. Must run after AuthMiddleware.
+func AdminOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := GetUserFromContext(r.Context())
+		if !ok || !user.IsAdmin {
+			apperr.Write(w, r, apperr.Forbidden("Admin access required"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
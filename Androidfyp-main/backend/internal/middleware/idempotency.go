@@ -0,0 +1,182 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/service"
+)
+
+const (
+	// IdempotencyKeyHeader lets the app retry a POST, PUT or DELETE without
+	// doing it twice
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayHeader marks a response replayed from an earlier request
+	IdempotentReplayHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKey  = 255
+	maxIdempotentBody  = 1 << 20
+	maxIdempotentReply = 1 << 20
+)
+
+// Idempotency makes requests that carry an Idempotency-Key safe to retry.
+// The first response for a (user, key, method and path) is stored and
+// replayed for 24 hours; the same key with a different body is refused.
+// Server errors and rate limits are not stored, so their retries run again.
+// Neither are responses marked Cache-Control: no-store, which carry secrets
+// such as recovery codes that must not sit in the database in plain text.
+// Must run after AuthMiddleware; requests without a key pass through.
+func Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || !isMutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, ok := GetUserIDFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !validIdempotencyKey(key) {
+			apperr.Write(w, r, apperr.Invalid("Idempotency-Key must be 1 to 255 printable ASCII characters"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+		if err != nil {
+			apperr.Write(w, r, apperr.Invalid("Could not read the request body"))
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			apperr.Write(w, r, apperr.Invalid("Request body is too large to use with an Idempotency-Key"))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		route := r.Method + " " + r.URL.Path
+		stored, err := service.BeginIdempotentRequest(userID, key, route, hex.EncodeToString(sum[:]))
+		if err != nil {
+			if errors.Is(err, service.ErrIdempotencyKeyInUse) {
+				w.Header().Set("Retry-After", "1")
+			}
+			apperr.Write(w, r, err)
+			return
+		}
+		if stored != nil {
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set(IdempotentReplayHeader, "true")
+			w.WriteHeader(stored.Status)
+			w.Write(stored.Body)
+			return
+		}
+
+		recorder := &recordingWriter{ResponseWriter: w}
+		finished := false
+		defer func() {
+			// Also runs when the handler panics
+			if !finished {
+				if err := service.ReleaseIdempotentRequest(userID, key, route); err != nil {
+					slog.ErrorContext(r.Context(), "release idempotency key failed", "error", err)
+				}
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		status := recorder.statusCode()
+		if !replayable(status) || recorder.overflow || noStore(recorder.Header()) {
+			return
+		}
+		contentType := recorder.Header().Get("Content-Type")
+		if err := service.FinishIdempotentRequest(userID, key, route, status, contentType, recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(r.Context(), "store idempotent response failed", "error", err)
+			return
+		}
+		finished = true
+	})
+}
+
+func isMutating(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodDelete
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKey {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x20 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// replayable leaves out responses a retry may well change: server errors
+// and rate limits
+func replayable(status int) bool {
+	return status < http.StatusInternalServerError && status != http.StatusTooManyRequests
+}
+
+// noStore reports whether the handler asked for its response not to be kept
+func noStore(header http.Header) bool {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+// recordingWriter passes the response through and keeps a copy of it
+type recordingWriter struct {
+	http.ResponseWriter
+	status   int
+	body     bytes.Buffer
+	overflow bool // the body was too large to keep
+}
+
+func (w *recordingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.overflow {
+		if w.body.Len()+len(b) > maxIdempotentReply {
+			w.overflow = true
+			w.body.Reset()
+		} else {
+			w.body.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) statusCode() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
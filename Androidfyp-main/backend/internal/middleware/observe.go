@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/audit"
+	"wazzafak_back/internal/logging"
+	"wazzafak_back/internal/metrics"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// RequestIDHeader carries the request ID in both directions: a proxy's ID is
+// kept, otherwise one is made up, and the response always echoes it
+const RequestIDHeader = "X-Request-ID"
+
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Observe gives each request an ID, logs it once it is done and records its
+// latency per route and status. It also hands the caller's IP and user agent
+// to the service layer for the security audit log. It goes first so it sees
+// every response, including those of the rate limits and AuthMiddleware.
+func Observe(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		ctx := logging.WithRequestID(r.Context(), id)
+		ip := ClientIP(r)
+		ctx = audit.WithClient(ctx, audit.Client{IP: ip, UserAgent: r.UserAgent()})
+
+		// Subrouters replace Routes as the request goes down the tree
+		routes := chi.RouteContext(r.Context()).Routes
+
+		metrics.HTTPRequestsInFlight.Add(1)
+		defer metrics.HTTPRequestsInFlight.Add(-1)
+
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		elapsed := time.Since(started)
+		status := ww.Status()
+		if status == 0 {
+			// Nothing was written: an upgraded websocket or an empty 200
+			status = http.StatusOK
+			if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+				status = http.StatusSwitchingProtocols
+			}
+		}
+		route := routeLabel(routes, r)
+
+		metrics.HTTPRequestDuration.Observe(elapsed.Seconds(), r.Method, route, strconv.Itoa(status))
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Float64("duration_ms", float64(elapsed.Microseconds())/1000),
+			slog.String("ip", ip),
+		)
+	})
+}
+
+// routeLabel is the matched pattern, e.g. /posts/{postID}, so metrics don't
+// get a series per post. A request a middleware turned away before its group
+// routed it, such as a 401 from AuthMiddleware, is looked up in the tree.
+// Paths no route matches share one label.
+func routeLabel(routes chi.Routes, r *http.Request) string {
+	pattern := chi.RouteContext(r.Context()).RoutePattern()
+	if (pattern == "" || strings.HasSuffix(pattern, "*")) && routes != nil {
+		pattern = routes.Find(chi.NewRouteContext(), r.Method, r.URL.Path)
+	}
+	if pattern == "" || strings.HasSuffix(pattern, "*") {
+		return "unmatched"
+	}
+	return pattern
+}
+
+func newRequestID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/ratelimit"
+	"wazzafak_back/internal/service"
+)
+
+// maxPeekBody caps how much of a body AccountKey reads to find the account
+const maxPeekBody = 64 << 10
+
+// TrustedProxyHops is how many proxies in front of the server append to
+// X-Forwarded-For; ClientIP takes the address that many entries from the
+// right, the last one a trusted proxy wrote. Entries further left come from
+// the client and can be anything. 0 ignores the header.
+var TrustedProxyHops int
+
+// KeyFunc picks the subject a request is counted against; "" skips the limit
+type KeyFunc func(r *http.Request) string
+
+// RateLimit answers 429 once a subject goes over rule. If the store fails the
+// request is let through: an outage of the counter shouldn't lock everyone out.
+func RateLimit(limiter *ratelimit.Limiter, rule ratelimit.Rule, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			subject := key(r)
+			if subject == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			decision, err := limiter.Allow(r.Context(), rule, subject)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "rate limit store failed", "rule", rule.Name, "error", err)
+			}
+			if !decision.Allowed {
+				seconds := int(math.Ceil(decision.RetryAfter.Seconds()))
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				apperr.Write(w, r, apperr.New(apperr.CodeRateLimited, "Too many requests, try again later"))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ClientIP is the per-IP key
+func ClientIP(r *http.Request) string {
+	if TrustedProxyHops > 0 {
+		// Proxies may send several X-Forwarded-For headers; together they are one list
+		var hops []string
+		for _, header := range r.Header.Values("X-Forwarded-For") {
+			hops = append(hops, strings.Split(header, ",")...)
+		}
+		// Fewer entries means the request didn't come through every proxy
+		if len(hops) >= TrustedProxyHops {
+			if ip := strings.TrimSpace(hops[len(hops)-TrustedProxyHops]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// AccountKey is the per-account key of the public auth routes: the given
+// field of the JSON body, lower-cased. The body is put back for the handler.
+func AccountKey(field string) KeyFunc {
+	return func(r *http.Request) string {
+		return strings.ToLower(strings.TrimSpace(peekBodyField(r, field)))
+	}
+}
+
+// ChallengeKey is the per-account key of /login/2fa: the user the challenge
+// token was issued to, so new IPs and new challenges share one budget
+func ChallengeKey(r *http.Request) string {
+	userID, ok := service.ChallengeUserID(peekBodyField(r, "challenge_token"))
+	if !ok {
+		return ""
+	}
+	return strconv.FormatUint(userID, 10)
+}
+
+// peekBodyField reads a string field of the JSON body and puts the body back
+func peekBodyField(r *http.Request, field string) string {
+	if r.Body == nil {
+		return ""
+	}
+	original := r.Body
+	body, err := io.ReadAll(io.LimitReader(original, maxPeekBody))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), original), original}
+	if err != nil {
+		return ""
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	value, _ := fields[field].(string)
+	return value
+}
+
+// UserKey is the per-account key of authenticated routes
+func UserKey(r *http.Request) string {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		return ""
+	}
+	return strconv.FormatUint(userID, 10)
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		hops      int
+		forwarded []string
+		want      string
+	}{
+		{"header ignored without trusted proxies", 0, []string{"9.9.9.9"}, "10.0.0.1"},
+		{"no header", 1, nil, "10.0.0.1"},
+		{"one proxy", 1, []string{"1.1.1.1"}, "1.1.1.1"},
+		{"one proxy, spoofed entries on the left", 1, []string{"6.6.6.6, 7.7.7.7, 1.1.1.1"}, "1.1.1.1"},
+		{"two proxies", 2, []string{"6.6.6.6, 1.1.1.1, 2.2.2.2"}, "1.1.1.1"},
+		{"headers are joined in order", 2, []string{"6.6.6.6, 1.1.1.1", "2.2.2.2"}, "1.1.1.1"},
+		{"fewer entries than proxies", 2, []string{"1.1.1.1"}, "10.0.0.1"},
+		{"spaces are trimmed", 1, []string{"6.6.6.6,   1.1.1.1  "}, "1.1.1.1"},
+		{"empty entry", 1, []string{"1.1.1.1, "}, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(hops int) { TrustedProxyHops = hops }(TrustedProxyHops)
+			TrustedProxyHops = tt.hops
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = "10.0.0.1:51234"
+			for _, header := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", header)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPWithoutPort(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "10.0.0.1"
+	if got := ClientIP(r); got != "10.0.0.1" {
+		t.Errorf("ClientIP = %q, want the bare RemoteAddr", got)
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// companyRoleCtxKey holds the caller's role for the company, job or application in the URL
+var companyRoleCtxKey = contextKey("companyRole")
+
+// GetCompanyRoleFromContext returns the role checked by one of the Require*Role middlewares
+func GetCompanyRoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(companyRoleCtxKey).(string)
+	return role
+}
+
+// RequireCompanyRole lets the request through when the caller has at least
+// the given role in the {companyID} company. Must run after AuthMiddleware.
+func RequireCompanyRole(required string) func(http.Handler) http.Handler {
+	return requireRole(required, func(r *http.Request, userID uint64) (string, error) {
+		companyID, err := strconv.ParseUint(chi.URLParam(r, "companyID"), 10, 64)
+		if err != nil {
+			return "", repository.ErrCompanyNotFound
+		}
+		if _, err := repository.GetCompanyByID(db.DB, companyID); err != nil {
+			return "", err
+		}
+		return repository.GetCompanyRole(db.DB, companyID, userID)
+	})
+}
+
+// RequireJobRole lets the request through when the caller has at least the
+// given role for the {jobID} job: through its company, or as the recruiter
+// who posted a personal job. Must run after AuthMiddleware.
+func RequireJobRole(required string) func(http.Handler) http.Handler {
+	return requireRole(required, func(r *http.Request, userID uint64) (string, error) {
+		jobID, err := strconv.ParseUint(chi.URLParam(r, "jobID"), 10, 64)
+		if err != nil {
+			return "", repository.ErrJobNotFound
+		}
+		return jobRole(jobID, userID)
+	})
+}
+
+// RequireApplicationRole is RequireJobRole for the job of the {applicationID} application
+func RequireApplicationRole(required string) func(http.Handler) http.Handler {
+	return requireRole(required, func(r *http.Request, userID uint64) (string, error) {
+		applicationID, err := strconv.ParseUint(chi.URLParam(r, "applicationID"), 10, 64)
+		if err != nil {
+			return "", repository.ErrApplicationNotFound
+		}
+		application, err := repository.GetApplicationByID(db.DB, applicationID)
+		if err != nil {
+			return "", err
+		}
+		return jobRole(application.JobID, userID)
+	})
+}
+
+func jobRole(jobID, userID uint64) (string, error) {
+	job, err := repository.GetJobByID(db.DB, jobID)
+	if err != nil {
+		return "", err
+	}
+	return repository.GetJobRole(db.DB, job, userID)
+}
+
+func requireRole(required string, lookup func(r *http.Request, userID uint64) (string, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				apperr.Write(w, r, apperr.Unauthenticated("Unauthorized"))
+				return
+			}
+
+			role, err := lookup(r, userID)
+			if err != nil {
+				switch {
+				case errors.Is(err, repository.ErrCompanyNotFound):
+					apperr.Write(w, r, apperr.NotFound("Company not found"))
+				case errors.Is(err, repository.ErrJobNotFound):
+					apperr.Write(w, r, apperr.NotFound("Job not found"))
+				case errors.Is(err, repository.ErrApplicationNotFound):
+					apperr.Write(w, r, apperr.NotFound("Application not found"))
+				default:
+					apperr.Write(w, r, err)
+				}
+				return
+			}
+
+			if !model.CompanyRoleAllows(role, required) {
+				apperr.Write(w, r, apperr.Forbidden("This requires the "+required+" role"))
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), companyRoleCtxKey, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
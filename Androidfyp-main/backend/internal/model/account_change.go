@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// UsernameReservation — a username someone gave up. Until ReservedUntil only
+// its previous owner may take it back, so old profile links can't be hijacked.
+type UsernameReservation struct {
+	Username      string    `gorm:"primaryKey;size:255" json:"username"` // lower-cased
+	UserID        uint64    `gorm:"not null;index" json:"user_id"`
+	ReservedUntil time.Time `gorm:"not null;index" json:"reserved_until"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (UsernameReservation) TableName() string {
+	return "username_reservations"
+}
+
+// EmailChangeRequest — an email change waiting for the code sent to NewEmail.
+// The code is kept here rather than in email_verifications, so requesting a
+// change to an address can't touch someone's sign-up for it.
+type EmailChangeRequest struct {
+	UserID    uint64    `gorm:"primaryKey" json:"user_id"`
+	NewEmail  string    `gorm:"size:255;not null;index" json:"new_email"`
+	Code      string    `gorm:"size:6;not null" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	Attempts  int       `gorm:"not null;default:0" json:"-"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (EmailChangeRequest) TableName() string {
+	return "email_change_requests"
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Application stages. An application starts as "applied"; the recruiter moves
+// it forward or rejects it, and the candidate may withdraw it.
+const (
+	ApplicationStageApplied   = "applied"
+	ApplicationStageScreening = "screening"
+	ApplicationStageInterview = "interview"
+	ApplicationStageOffer     = "offer"
+	ApplicationStageRejected  = "rejected"
+	ApplicationStageWithdrawn = "withdrawn"
+)
+
+// ApplicationStages lists every stage in pipeline order
+var ApplicationStages = []string{
+	ApplicationStageApplied,
+	ApplicationStageScreening,
+	ApplicationStageInterview,
+	ApplicationStageOffer,
+	ApplicationStageRejected,
+	ApplicationStageWithdrawn,
+}
+
+// Application — a candidate's application to a job, with the CV they attached.
+// RecruiterNotes are never shown to the candidate.
+type Application struct {
+	ID             uint64    `gorm:"primaryKey" json:"id"`
+	JobID          uint64    `gorm:"not null;uniqueIndex:idx_applications_job_user" json:"job_id"`
+	UserID         uint64    `gorm:"not null;uniqueIndex:idx_applications_job_user;index" json:"user_id"`
+	CVURL          string    `gorm:"type:text;not null" json:"cv_url"`
+	CVFileName     string    `gorm:"size:255;default:''" json:"cv_file_name"`
+	CoverLetter    string    `gorm:"type:text;default:''" json:"cover_letter"`
+	Stage          string    `gorm:"type:varchar(20);not null;default:'applied';index" json:"stage"`
+	RecruiterNotes string    `gorm:"type:text;default:''" json:"-"`
+	StageChangedAt time.Time `gorm:"not null" json:"stage_changed_at"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Application) TableName() string {
+	return "applications"
+}
+
+// IsFinal reports whether the application left the pipeline
+func (a Application) IsFinal() bool {
+	return a.Stage == ApplicationStageRejected || a.Stage == ApplicationStageWithdrawn
+}
+
+// ApplicationStageChange — one move of an application between stages
+type ApplicationStageChange struct {
+	ID            uint64    `gorm:"primaryKey" json:"id"`
+	ApplicationID uint64    `gorm:"not null;index" json:"application_id"`
+	FromStage     string    `gorm:"type:varchar(20);default:''" json:"from_stage"` // empty for the initial "applied"
+	ToStage       string    `gorm:"type:varchar(20);not null" json:"to_stage"`
+	ChangedBy     uint64    `gorm:"not null" json:"changed_by"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ApplicationStageChange) TableName() string {
+	return "application_stage_changes"
+}
+
+func NewApplication(jobID, userID uint64, cvURL, cvFileName, coverLetter string) Application {
+	return Application{
+		ID:             db.GenerateID(),
+		JobID:          jobID,
+		UserID:         userID,
+		CVURL:          cvURL,
+		CVFileName:     cvFileName,
+		CoverLetter:    coverLetter,
+		Stage:          ApplicationStageApplied,
+		StageChangedAt: time.Now(),
+	}
+}
+
+func NewApplicationStageChange(applicationID uint64, fromStage, toStage string, changedBy uint64) ApplicationStageChange {
+	return ApplicationStageChange{
+		ID:            db.GenerateID(),
+		ApplicationID: applicationID,
+		FromStage:     fromStage,
+		ToStage:       toStage,
+		ChangedBy:     changedBy,
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Security-relevant account events recorded in the audit log
+const (
+	AuditLoginSucceeded           = "login_succeeded"
+	AuditLoginFailed              = "login_failed"
+	AuditAccountRegistered        = "account_registered"
+	AuditEmailVerified            = "email_verified"
+	AuditPasswordResetRequested   = "password_reset_requested"
+	AuditPasswordReset            = "password_reset"
+	AuditPasswordChanged          = "password_changed"
+	AuditNameChanged              = "name_changed"
+	AuditPhotoChanged             = "photo_changed"
+	AuditPhotoRemoved             = "photo_removed"
+	AuditUsernameChanged          = "username_changed"
+	AuditEmailChangeRequested     = "email_change_requested"
+	AuditEmailChanged             = "email_changed"
+	Audit2FAEnabled               = "2fa_enabled"
+	Audit2FADisabled              = "2fa_disabled"
+	AuditRecoveryCodesRegenerated = "recovery_codes_regenerated"
+	AuditFollowed                 = "followed"
+	AuditUnfollowed               = "unfollowed"
+	AuditDeletionRequested        = "account_deletion_requested"
+	AuditDeletionCancelled        = "account_deletion_cancelled"
+)
+
+// AuditAnonymizeSetting is the transaction setting that lets the account
+// purge blank the IP, user agent and metadata of the account's events. The
+// table rejects every other update and all deletes.
+const AuditAnonymizeSetting = "wazzafak.audit_anonymize"
+
+// AuditEvent — append-only log of security-relevant account events. UserID
+// is the account the event is about (0 when no account matched, e.g. a login
+// with an unknown email) and ActorID who caused it, which differs from
+// UserID when an admin acts on someone's account. Metadata never holds
+// emails, names or other personal data, only IDs and fixed values like
+// a failure reason.
+type AuditEvent struct {
+	ID        uint64            `gorm:"primaryKey" json:"id"`
+	UserID    uint64            `gorm:"not null;index" json:"user_id"`
+	ActorID   uint64            `gorm:"not null;index" json:"actor_id"`
+	Type      string            `gorm:"type:varchar(50);not null;index" json:"type"`
+	IP        string            `gorm:"type:varchar(64);default:''" json:"ip"`
+	UserAgent string            `gorm:"type:varchar(512);default:''" json:"user_agent"`
+	Metadata  map[string]string `gorm:"type:jsonb;serializer:json" json:"metadata,omitempty"`
+	CreatedAt time.Time         `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+func NewAuditEvent(userID, actorID uint64, eventType, ip, userAgent string, metadata map[string]string) AuditEvent {
+	return AuditEvent{
+		ID:        db.GenerateID(),
+		UserID:    userID,
+		ActorID:   actorID,
+		Type:      eventType,
+		IP:        ip,
+		UserAgent: userAgent,
+		Metadata:  metadata,
+	}
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Block — BlockerID doesn't want any contact with BlockedID
+type Block struct {
+	BlockerID uint64    `gorm:"primaryKey" json:"blocker_id"`
+	BlockedID uint64    `gorm:"primaryKey;index" json:"blocked_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Block) TableName() string {
+	return "blocks"
+}
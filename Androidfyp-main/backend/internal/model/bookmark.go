@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Bookmark — a post saved by a user, optionally filed into a collection
+type Bookmark struct {
+	UserID       uint64    `gorm:"primaryKey" json:"user_id"`
+	PostID       uint64    `gorm:"primaryKey;index" json:"post_id"`
+	CollectionID *uint64   `gorm:"index" json:"collection_id,omitempty"`
+	CreatedAt    time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (Bookmark) TableName() string {
+	return "bookmarks"
+}
+
+// BookmarkCollection — a named folder of bookmarks, private to its owner
+type BookmarkCollection struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_bookmark_collection_user_name" json:"user_id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_bookmark_collection_user_name" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (BookmarkCollection) TableName() string {
+	return "bookmark_collections"
+}
+
+func NewBookmarkCollection(userID uint64, name string) BookmarkCollection {
+	return BookmarkCollection{
+		ID:     db.GenerateID(),
+		UserID: userID,
+		Name:   name,
+	}
+}
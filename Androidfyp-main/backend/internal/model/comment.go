@@ -1,13 +1,19 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Comment struct {
-	ID        uint64    `gorm:"primaryKey" json:"id"`
-	PostID    uint64    `gorm:"not null" json:"post_id"`
-	UserID    uint64    `gorm:"not null" json:"user_id"`
-	Content   string    `gorm:"type:text;not null" json:"content"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID        uint64         `gorm:"primaryKey" json:"id"`
+	PostID    uint64         `gorm:"not null" json:"post_id"`
+	UserID    uint64         `gorm:"not null" json:"user_id"`
+	Content   string         `gorm:"type:text;not null" json:"content"`
+	Hidden    bool           `gorm:"not null;default:false" json:"-"` // hidden by a moderator
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"` // soft-deleted; restorable until purged
 }
 
 func (Comment) TableName() string {
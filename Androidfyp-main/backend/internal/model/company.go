@@ -0,0 +1,101 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Company membership roles, from most to least privileged:
+// owners manage the page and its members, recruiters post jobs and handle
+// applicants, viewers can only look at jobs and applicants.
+const (
+	CompanyRoleOwner     = "owner"
+	CompanyRoleRecruiter = "recruiter"
+	CompanyRoleViewer    = "viewer"
+)
+
+var companyRoleRank = map[string]int{
+	CompanyRoleViewer:    1,
+	CompanyRoleRecruiter: 2,
+	CompanyRoleOwner:     3,
+}
+
+// IsCompanyRole reports whether role is one of the membership roles
+func IsCompanyRole(role string) bool {
+	_, ok := companyRoleRank[role]
+	return ok
+}
+
+// CompanyRoleAllows reports whether role grants at least the required role
+func CompanyRoleAllows(role, required string) bool {
+	return role != "" && companyRoleRank[role] >= companyRoleRank[required]
+}
+
+// Company — a company page that recruiters post jobs for
+type Company struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"size:150;not null" json:"name"`
+	LogoURL     string    `gorm:"type:text;default:''" json:"logo_url"`
+	Description string    `gorm:"type:text;default:''" json:"description"`
+	Website     string    `gorm:"type:text;default:''" json:"website"`
+	CreatedBy   uint64    `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Company) TableName() string {
+	return "companies"
+}
+
+// CompanyMember — a user's role in a company
+type CompanyMember struct {
+	CompanyID uint64    `gorm:"primaryKey" json:"company_id"`
+	UserID    uint64    `gorm:"primaryKey;index" json:"user_id"`
+	Role      string    `gorm:"type:varchar(20);not null" json:"role"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (CompanyMember) TableName() string {
+	return "company_members"
+}
+
+// CompanyInvite — a pending invitation, accepted with the code emailed to Email.
+// Inviting the same email again replaces the previous invitation.
+type CompanyInvite struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	CompanyID uint64    `gorm:"not null;uniqueIndex:idx_company_invites_company_email" json:"company_id"`
+	Email     string    `gorm:"size:255;not null;uniqueIndex:idx_company_invites_company_email;index" json:"email"`
+	Role      string    `gorm:"type:varchar(20);not null" json:"role"`
+	Code      string    `gorm:"size:20;not null" json:"-"`
+	InvitedBy uint64    `gorm:"not null" json:"invited_by"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (CompanyInvite) TableName() string {
+	return "company_invites"
+}
+
+func NewCompany(createdBy uint64, name, logoURL, description, website string) Company {
+	return Company{
+		ID:          db.GenerateID(),
+		Name:        name,
+		LogoURL:     logoURL,
+		Description: description,
+		Website:     website,
+		CreatedBy:   createdBy,
+	}
+}
+
+func NewCompanyInvite(companyID uint64, email, role, code string, invitedBy uint64, expiresAt time.Time) CompanyInvite {
+	return CompanyInvite{
+		ID:        db.GenerateID(),
+		CompanyID: companyID,
+		Email:     email,
+		Role:      role,
+		Code:      code,
+		InvitedBy: invitedBy,
+		ExpiresAt: expiresAt,
+	}
+}
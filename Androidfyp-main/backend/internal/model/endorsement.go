@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// SkillEndorsement — a connection vouching for one skill on a profile
+type SkillEndorsement struct {
+	SkillID    uint64    `gorm:"primaryKey" json:"skill_id"`
+	EndorserID uint64    `gorm:"primaryKey;index" json:"endorser_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (SkillEndorsement) TableName() string {
+	return "skill_endorsements"
+}
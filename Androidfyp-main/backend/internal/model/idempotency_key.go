@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// IdempotencyKey — the outcome of the first request a user sent with an
+// Idempotency-Key header, replayed when the app retries it. Status is 0 while
+// that first request is still being handled.
+type IdempotencyKey struct {
+	UserID      uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Key         string    `gorm:"primaryKey;size:255"`
+	Route       string    `gorm:"primaryKey;size:512"` // method and path, e.g. POST /posts/42/comment
+	RequestHash string    `gorm:"size:64;not null"`    // SHA-256 of the body, hex
+	Status      int       `gorm:"not null;default:0"`
+	ContentType string    `gorm:"size:255;not null;default:''"`
+	Body        []byte    `gorm:"type:bytea"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	ExpiresAt   time.Time `gorm:"not null;index"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Job statuses
+const (
+	JobStatusOpen   = "open"
+	JobStatusClosed = "closed"
+)
+
+// JobRemoteTypes are the accepted values of Job.RemoteType
+var JobRemoteTypes = []string{"onsite", "hybrid", "remote"}
+
+// JobSeniorities are the accepted values of Job.Seniority
+var JobSeniorities = []string{"intern", "junior", "mid", "senior", "lead"}
+
+// Job — a job posting. JobPosition/JobPositionType use the same vocabulary
+// as the fields of the same name on User (e.g. "Software Engineering"/"Backend").
+type Job struct {
+	ID              uint64     `gorm:"primaryKey" json:"id"`
+	RecruiterID     uint64     `gorm:"not null;index" json:"recruiter_id"`
+	CompanyID       *uint64    `gorm:"index" json:"company_id,omitempty"`    // nil for jobs posted by an individual recruiter
+	PostID          *uint64    `gorm:"uniqueIndex" json:"post_id,omitempty"` // the "job" post announcing it in feeds
+	Title           string     `gorm:"size:150;not null" json:"title"`
+	Company         string     `gorm:"size:150;not null" json:"company"`
+	Location        string     `gorm:"size:150;default:''" json:"location"`
+	RemoteType      string     `gorm:"type:varchar(20);not null;index" json:"remote_type"`
+	Seniority       string     `gorm:"type:varchar(20);not null;index" json:"seniority"`
+	JobPosition     string     `gorm:"size:100;not null;index" json:"job_position"`
+	JobPositionType string     `gorm:"size:100;default:''" json:"job_position_type"`
+	Description     string     `gorm:"type:text;not null" json:"description"`
+	Status          string     `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	ClosedAt        *time.Time `json:"closed_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Job) TableName() string {
+	return "jobs"
+}
+
+// SavedJob — a job a user saved for later
+type SavedJob struct {
+	UserID    uint64    `gorm:"primaryKey" json:"user_id"`
+	JobID     uint64    `gorm:"primaryKey;index" json:"job_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (SavedJob) TableName() string {
+	return "saved_jobs"
+}
+
+func NewJob(recruiterID uint64, title, company, location, remoteType, seniority, jobPosition, jobPositionType, description string) Job {
+	return Job{
+		ID:              db.GenerateID(),
+		RecruiterID:     recruiterID,
+		Title:           title,
+		Company:         company,
+		Location:        location,
+		RemoteType:      remoteType,
+		Seniority:       seniority,
+		JobPosition:     jobPosition,
+		JobPositionType: jobPositionType,
+		Description:     description,
+		Status:          JobStatusOpen,
+	}
+}
+
+// FeedText is the content of the job's feed post
+func (j Job) FeedText() string {
+	return "We're hiring: " + j.Title + " at " + j.Company
+}
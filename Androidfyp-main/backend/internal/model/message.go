@@ -0,0 +1,88 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// MaxGroupMembers caps small-group conversations, creator included
+const MaxGroupMembers = 10
+
+// Conversation member statuses
+const (
+	MemberStatusActive   = "active"
+	MemberStatusRequest  = "request"  // message request not accepted yet
+	MemberStatusDeclined = "declined" // message request declined
+	MemberStatusLeft     = "left"     // left a group
+)
+
+// Conversation — a 1:1 or small-group chat
+type Conversation struct {
+	ID            uint64    `gorm:"primaryKey" json:"id"`
+	IsGroup       bool      `gorm:"not null;default:false" json:"is_group"`
+	Title         string    `gorm:"size:100;default:''" json:"title"`
+	DirectKey     *string   `gorm:"size:50;uniqueIndex" json:"-"` // "lowID:highID" for 1:1 chats, so each pair has one
+	CreatedBy     uint64    `gorm:"not null" json:"created_by"`
+	LastMessageAt time.Time `gorm:"not null;index" json:"last_message_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Conversation) TableName() string {
+	return "conversations"
+}
+
+// ConversationMember — a user's place in a conversation and how far they have read
+type ConversationMember struct {
+	ConversationID    uint64     `gorm:"primaryKey" json:"conversation_id"`
+	UserID            uint64     `gorm:"primaryKey;index" json:"user_id"`
+	Status            string     `gorm:"type:varchar(20);not null;default:'active'" json:"status"`
+	LastReadMessageID uint64     `gorm:"not null;default:0" json:"last_read_message_id"`
+	LastReadAt        *time.Time `json:"last_read_at,omitempty"`
+	JoinedAt          time.Time  `gorm:"autoCreateTime" json:"joined_at"`
+}
+
+func (ConversationMember) TableName() string {
+	return "conversation_members"
+}
+
+// Message — IDs are snowflakes, so they grow with time and double as read markers
+type Message struct {
+	ID             uint64    `gorm:"primaryKey" json:"id"`
+	ConversationID uint64    `gorm:"not null;index:idx_messages_conversation_created" json:"conversation_id"`
+	SenderID       uint64    `gorm:"not null" json:"sender_id"`
+	Content        string    `gorm:"type:text;not null" json:"content"`
+	CreatedAt      time.Time `gorm:"autoCreateTime;index:idx_messages_conversation_created" json:"created_at"`
+}
+
+func (Message) TableName() string {
+	return "messages"
+}
+
+func NewConversation(createdBy uint64, isGroup bool, title string) Conversation {
+	return Conversation{
+		ID:            db.GenerateID(),
+		IsGroup:       isGroup,
+		Title:         title,
+		CreatedBy:     createdBy,
+		LastMessageAt: time.Now(),
+	}
+}
+
+func NewMessage(conversationID, senderID uint64, content string) Message {
+	return Message{
+		ID:             db.GenerateID(),
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Content:        content,
+	}
+}
+
+// DirectConversationKey identifies the 1:1 conversation between two users
+func DirectConversationKey(a, b uint64) string {
+	if a > b {
+		a, b = b, a
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
@@ -0,0 +1,206 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AutoMigrate creates the tables owned by the social backend that are not
+// provisioned by hand in Supabase. Existing tables (users, posts, ...) are
+// only ever extended with new columns so GORM never tries to alter their
+// existing column types.
+func AutoMigrate(db *gorm.DB) error {
+	// Reports raced in before idx_reports_one_open existed; keep the oldest
+	// open one, or AutoMigrate can't create the index
+	if db.Migrator().HasTable(&Report{}) {
+		if err := db.Exec(`UPDATE reports SET status = ?, resolved_at = NOW() WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY reporter_id, target_type, target_id ORDER BY created_at, id) AS n
+				FROM reports WHERE status = ?
+			) open_reports WHERE n > 1)`, ReportStatusDismissed, ReportStatusOpen).Error; err != nil {
+			return err
+		}
+	}
+
+	// Before version 9 email change codes lived in email_verifications;
+	// requests from then can't be confirmed any more
+	if db.Migrator().HasTable(&EmailChangeRequest{}) && !db.Migrator().HasColumn(&EmailChangeRequest{}, "Code") {
+		if err := db.Exec(`DELETE FROM email_change_requests`).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := db.AutoMigrate(
+		&BookmarkCollection{},
+		&Bookmark{},
+		&Poll{},
+		&PollOption{},
+		&PollVote{},
+		&Report{},
+		&ModerationAction{},
+		&Block{},
+		&Conversation{},
+		&ConversationMember{},
+		&Message{},
+		&Job{},
+		&SavedJob{},
+		&Application{},
+		&ApplicationStageChange{},
+		&Company{},
+		&CompanyMember{},
+		&CompanyInvite{},
+		&UserSkill{},
+		&Experience{},
+		&Education{},
+		&ProfileLink{},
+		&SkillEndorsement{},
+		&UsernameReservation{},
+		&EmailChangeRequest{},
+		&TwoFactor{},
+		&RecoveryCode{},
+		&RateLimitBucket{},
+		&OutboxEmail{},
+		&SchemaMigration{},
+		&IdempotencyKey{},
+		&AuditEvent{},
+	); err != nil {
+		return err
+	}
+
+	var previousVersion int
+	if err := db.Raw(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&previousVersion).Error; err != nil {
+		return err
+	}
+
+	// The audit log is append-only, even for code that bypasses the
+	// repository; only the account purge may anonymize rows
+	if err := db.Exec(`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+		BEGIN
+			IF TG_OP = 'UPDATE' AND current_setting('` + AuditAnonymizeSetting + `', true) = 'on' THEN
+				RETURN NEW;
+			END IF;
+			RAISE EXCEPTION 'audit_events is append-only';
+		END;
+		$$ LANGUAGE plpgsql`).Error; err != nil {
+		return err
+	}
+	// CREATE OR REPLACE TRIGGER needs Postgres 14. DROP TRIGGER locks the
+	// table, so replicas starting together take turns.
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+			FOR EACH ROW EXECUTE PROCEDURE audit_events_append_only()`).Error
+	}); err != nil {
+		return err
+	}
+
+	// Before version 6 events stored emails, names and photo URLs
+	if previousVersion > 0 && previousVersion < 6 {
+		if err := scrubAuditMetadata(db); err != nil {
+			return err
+		}
+	}
+
+	// Before version 7 sent emails kept their bodies, codes included
+	if previousVersion > 0 && previousVersion < 7 {
+		if err := db.Exec(`UPDATE outbox_emails SET html_body = '', text_body = '' WHERE status = ?`, OutboxSent).Error; err != nil {
+			return err
+		}
+	}
+
+	// email_verifications has no model; it is only touched with raw SQL
+	if err := db.Exec(`ALTER TABLE email_verifications ADD COLUMN IF NOT EXISTS attempts integer NOT NULL DEFAULT 0`).Error; err != nil {
+		return err
+	}
+	if err := addMissingColumns(db, &PasswordReset{}, "Attempts"); err != nil {
+		return err
+	}
+
+	if err := addMissingColumns(db, &Post{}, "Kind", "RepostOfID", "OriginalDeleted", "Status", "PublishAt", "DeletedAt"); err != nil {
+		return err
+	}
+	if err := addMissingIndexes(db, &Post{}, "RepostOfID", "idx_posts_status_publish_at", "DeletedAt"); err != nil {
+		return err
+	}
+	// Reposts raced in before the unique index existed; keep the oldest
+	if err := db.Exec(`UPDATE posts SET deleted_at = NOW() WHERE id IN (
+		SELECT id FROM (
+			SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, repost_of_id ORDER BY created_at, id) AS n
+			FROM posts WHERE kind = 'repost' AND deleted_at IS NULL
+		) reposts WHERE n > 1)`).Error; err != nil {
+		return err
+	}
+	if err := addMissingIndexes(db, &Post{}, "idx_posts_one_repost"); err != nil {
+		return err
+	}
+	if err := addMissingColumns(db, &Comment{}, "Hidden", "DeletedAt"); err != nil {
+		return err
+	}
+	if err := addMissingIndexes(db, &Comment{}, "DeletedAt"); err != nil {
+		return err
+	}
+	if err := addMissingColumns(db, &Notification{}, "DeletedAt"); err != nil {
+		return err
+	}
+	if err := addMissingIndexes(db, &Notification{}, "DeletedAt"); err != nil {
+		return err
+	}
+	if err := addMissingColumns(db, &User{}, "SuspendedAt", "SuspendedUntil", "SuspendReason", "IsRecruiter", "Headline", "Bio", "UsernameChangedAt", "PasswordChangedAt", "DeleteAfter", "AccountDeletedAt"); err != nil {
+		return err
+	}
+	if err := addMissingIndexes(db, &User{}, "DeleteAfter"); err != nil {
+		return err
+	}
+
+	// Never lower the version: a newer replica may already have migrated further
+	return db.Exec(`INSERT INTO schema_migrations (id, version, migrated_at) VALUES (1, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET version = EXCLUDED.version, migrated_at = EXCLUDED.migrated_at
+		WHERE schema_migrations.version < EXCLUDED.version`, SchemaVersion, time.Now()).Error
+}
+
+// scrubAuditMetadata removes the personal data earlier versions put into
+// audit event metadata
+func scrubAuditMetadata(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`SELECT set_config('` + AuditAnonymizeSetting + `', 'on', true)`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE audit_events SET metadata =
+			CASE WHEN type = ? THEN metadata - 'reason' ELSE metadata END
+			- ARRAY['email', 'username', 'old_username', 'new_username', 'old_email', 'new_email', 'name', 'photo_url']::text[]
+			WHERE metadata IS NOT NULL AND metadata::text <> 'null'`, Audit2FADisabled).Error
+	})
+}
+
+// addMissingColumns adds the given struct fields to an existing table
+// when the column is not there yet
+func addMissingColumns(db *gorm.DB, table interface{}, fields ...string) error {
+	migrator := db.Migrator()
+	for _, field := range fields {
+		if migrator.HasColumn(table, field) {
+			continue
+		}
+		if err := migrator.AddColumn(table, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMissingIndexes creates the indexes declared on the given struct fields
+// when they are not there yet
+func addMissingIndexes(db *gorm.DB, table interface{}, fields ...string) error {
+	migrator := db.Migrator()
+	for _, field := range fields {
+		if migrator.HasIndex(table, field) {
+			continue
+		}
+		if err := migrator.CreateIndex(table, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
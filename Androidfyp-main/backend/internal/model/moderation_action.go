@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Moderation actions recorded in the audit log
+const (
+	ModerationHidePost        = "hide_post"
+	ModerationUnhidePost      = "unhide_post"
+	ModerationRemovePost      = "remove_post"
+	ModerationHideComment     = "hide_comment"
+	ModerationUnhideComment   = "unhide_comment"
+	ModerationRemoveComment   = "remove_comment"
+	ModerationSuspendUser     = "suspend_user"
+	ModerationUnsuspendUser   = "unsuspend_user"
+	ModerationDismissReport   = "dismiss_report"
+	ModerationGrantRecruiter  = "grant_recruiter"
+	ModerationRevokeRecruiter = "revoke_recruiter"
+	ModerationDisable2FA      = "disable_2fa"
+)
+
+// ModerationAction — append-only audit log of everything an admin does
+type ModerationAction struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	AdminID    uint64    `gorm:"not null;index" json:"admin_id"`
+	Action     string    `gorm:"type:varchar(50);not null" json:"action"`
+	TargetType string    `gorm:"type:varchar(20);not null;index:idx_moderation_actions_target" json:"target_type"`
+	TargetID   uint64    `gorm:"not null;index:idx_moderation_actions_target" json:"target_id"`
+	ReportID   *uint64   `json:"report_id,omitempty"`
+	Reason     string    `gorm:"type:text;default:''" json:"reason"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (ModerationAction) TableName() string {
+	return "moderation_actions"
+}
+
+func NewModerationAction(adminID uint64, action, targetType string, targetID uint64, reportID *uint64, reason string) ModerationAction {
+	return ModerationAction{
+		ID:         db.GenerateID(),
+		AdminID:    adminID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		ReportID:   reportID,
+		Reason:     reason,
+	}
+}
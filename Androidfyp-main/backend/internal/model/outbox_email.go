@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Outbox email statuses
+const (
+	OutboxPending = "pending"
+	OutboxSent    = "sent"
+	OutboxDead    = "dead" // gave up after too many failed attempts
+)
+
+// OutboxEmail — a rendered email waiting for the mail worker. It is written in
+// the same transaction as whatever it announces (a code, an invite), so one
+// never exists without the other.
+type OutboxEmail struct {
+	ID            uint64     `gorm:"primaryKey" json:"id"`
+	ToEmail       string     `gorm:"size:255;not null" json:"to_email"`
+	Template      string     `gorm:"size:50;not null" json:"template"`
+	Subject       string     `gorm:"type:text;not null" json:"subject"`
+	HTMLBody      string     `gorm:"type:text;not null" json:"-"`
+	TextBody      string     `gorm:"type:text;not null" json:"-"`
+	Status        string     `gorm:"type:varchar(10);not null;default:'pending';index:idx_outbox_emails_due,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_emails_due,priority:2" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text;default:''" json:"last_error"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (OutboxEmail) TableName() string {
+	return "outbox_emails"
+}
+
+func NewOutboxEmail(to, template, subject, htmlBody, textBody string) OutboxEmail {
+	return OutboxEmail{
+		ID:            db.GenerateID(),
+		ToEmail:       to,
+		Template:      template,
+		Subject:       subject,
+		HTMLBody:      htmlBody,
+		TextBody:      textBody,
+		Status:        OutboxPending,
+		NextAttemptAt: time.Now(),
+	}
+}
@@ -6,6 +6,7 @@ import "time"
 type PasswordReset struct {
 	Email     string    `gorm:"primaryKey"`
 	Code      string    `gorm:"not null"`
+	Attempts  int       `gorm:"not null;default:0"` // wrong guesses so far
 	ExpiresAt time.Time `gorm:"not null;index"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
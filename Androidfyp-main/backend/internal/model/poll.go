@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Poll — extra data for posts of kind "poll"; the post content is the question
+type Poll struct {
+	PostID         uint64     `gorm:"primaryKey" json:"post_id"`
+	MultipleChoice bool       `gorm:"not null;default:false" json:"multiple_choice"`
+	ExpiresAt      *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	ClosedNotified bool       `gorm:"not null;default:false" json:"-"` // author was told the poll closed
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Poll) TableName() string {
+	return "polls"
+}
+
+type PollOption struct {
+	ID       uint64 `gorm:"primaryKey" json:"id"`
+	PostID   uint64 `gorm:"not null;index" json:"post_id"`
+	Position int    `gorm:"not null" json:"position"`
+	Text     string `gorm:"size:100;not null" json:"text"`
+}
+
+func (PollOption) TableName() string {
+	return "poll_options"
+}
+
+// PollVote — one row per (voter, chosen option); a multiple-choice ballot has several rows
+type PollVote struct {
+	PostID    uint64    `gorm:"primaryKey"`
+	UserID    uint64    `gorm:"primaryKey"`
+	OptionID  uint64    `gorm:"primaryKey;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (PollVote) TableName() string {
+	return "poll_votes"
+}
+
+func NewPoll_structure(postID uint64, multipleChoice bool, expiresAt *time.Time, optionTexts []string) (Poll, []PollOption) {
+	poll := Poll{
+		PostID:         postID,
+		MultipleChoice: multipleChoice,
+		ExpiresAt:      expiresAt,
+	}
+
+	options := make([]PollOption, 0, len(optionTexts))
+	for i, text := range optionTexts {
+		options = append(options, PollOption{
+			ID:       db.GenerateID(),
+			PostID:   postID,
+			Position: i,
+			Text:     text,
+		})
+	}
+	return poll, options
+}
+
+// IsClosed reports whether voting has ended at the given time
+func (p Poll) IsClosed(now time.Time) bool {
+	return p.ExpiresAt != nil && !now.Before(*p.ExpiresAt)
+}
@@ -3,15 +3,43 @@ package model
 import (
 	"time"
 	db "wazzafak_back/internal/database"
+
+	"gorm.io/gorm"
+)
+
+// Post kinds — a repost/quote points at the shared post through RepostOfID
+const (
+	PostKindPost   = "post"
+	PostKindRepost = "repost"
+	PostKindQuote  = "quote"
+	PostKindPoll   = "poll"
+	PostKindJob    = "job" // feed entry for a job posting, see Job.PostID
 )
 
+// Post statuses — only published posts are visible to other users
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+	PostStatusHidden    = "hidden" // hidden by a moderator
+)
+
+// UnpublishedPostStatuses are the statuses an author can still edit and publish
+var UnpublishedPostStatuses = []string{PostStatusDraft, PostStatusScheduled}
+
 type Post struct {
-	ID        uint64    `gorm:"primaryKey" json:"id"`
-	UserID    uint64    `gorm:"not null;index" json:"user_id"`
-	PhotoURL  string    `gorm:"size:500;default:''" json:"photo_url"`
-	Content   string    `gorm:"type:text;default:''" json:"content"`
-	CreatedAt time.Time `json:"created_at"` // ✅ Add this
-	UpdatedAt time.Time `json:"updated_at"` // (optional, but useful)
+	ID              uint64         `gorm:"primaryKey" json:"id"`
+	UserID          uint64         `gorm:"not null;index;uniqueIndex:idx_posts_one_repost,where:kind = 'repost' AND deleted_at IS NULL" json:"user_id"`
+	PhotoURL        string         `gorm:"size:500;default:''" json:"photo_url"`
+	Content         string         `gorm:"type:text;default:''" json:"content"`
+	Kind            string         `gorm:"type:varchar(20);not null;default:'post'" json:"kind"`
+	RepostOfID      *uint64        `gorm:"index;uniqueIndex:idx_posts_one_repost" json:"repost_of_id,omitempty"` // a user reposts a post once
+	OriginalDeleted bool           `gorm:"not null;default:false" json:"original_deleted"`                       // quote whose original is gone
+	Status          string         `gorm:"type:varchar(20);not null;default:'published';index:idx_posts_status_publish_at" json:"status"`
+	PublishAt       *time.Time     `gorm:"index:idx_posts_status_publish_at" json:"publish_at,omitempty"` // set for scheduled posts
+	CreatedAt       time.Time      `json:"created_at"`                                                    // ✅ Add this
+	UpdatedAt       time.Time      `json:"updated_at"`                                                    // (optional, but useful)
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`                                                // soft-deleted; restorable until purged
 }
 
 func NewPost_structure(userID uint64, photoURL, content string) Post {
@@ -20,5 +48,24 @@ func NewPost_structure(userID uint64, photoURL, content string) Post {
 		UserID:   userID,
 		PhotoURL: photoURL,
 		Content:  content,
+		Kind:     PostKindPost,
+		Status:   PostStatusPublished,
 	}
 }
+
+// NewRepost_structure builds a pure repost (no content) or a quote post
+// (content and/or photo) that shares originalID
+func NewRepost_structure(userID, originalID uint64, photoURL, content string) Post {
+	post := NewPost_structure(userID, photoURL, content)
+	post.RepostOfID = &originalID
+	post.Kind = PostKindRepost
+	if content != "" || photoURL != "" {
+		post.Kind = PostKindQuote
+	}
+	return post
+}
+
+// IsUnpublished reports whether the post is still a draft or scheduled
+func (p Post) IsUnpublished() bool {
+	return p.Status == PostStatusDraft || p.Status == PostStatusScheduled
+}
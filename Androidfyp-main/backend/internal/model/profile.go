@@ -0,0 +1,106 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Profile section limits
+const (
+	MaxProfileSkills     = 50
+	MaxProfileExperience = 30
+	MaxProfileEducation  = 20
+	MaxProfileLinks      = 10
+)
+
+// UserSkill — a skill listed on a profile, kept in the order the user chose
+type UserSkill struct {
+	ID             uint64    `gorm:"primaryKey" json:"id"`
+	UserID         uint64    `gorm:"not null;uniqueIndex:idx_user_skills_user_name" json:"user_id"`
+	Name           string    `gorm:"size:50;not null" json:"name"`
+	NormalizedName string    `gorm:"size:50;not null;uniqueIndex:idx_user_skills_user_name;index" json:"-"` // lower-cased, for lookups and search
+	Position       int       `gorm:"not null;default:0" json:"position"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (UserSkill) TableName() string {
+	return "user_skills"
+}
+
+// NormalizeSkill is the key skills are matched by, e.g. "  Go " and "go"
+func NormalizeSkill(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func NewUserSkill(userID uint64, name string, position int) UserSkill {
+	return UserSkill{
+		ID:             db.GenerateID(),
+		UserID:         userID,
+		Name:           name,
+		NormalizedName: NormalizeSkill(name),
+		Position:       position,
+	}
+}
+
+// Experience — a work experience entry. EndDate is nil for the current job.
+type Experience struct {
+	ID          uint64     `gorm:"primaryKey" json:"id"`
+	UserID      uint64     `gorm:"not null;index" json:"user_id"`
+	Title       string     `gorm:"size:150;not null" json:"title"`
+	Company     string     `gorm:"size:150;not null" json:"company"`
+	Location    string     `gorm:"size:150;default:''" json:"location"`
+	StartDate   time.Time  `gorm:"type:date;not null" json:"start_date"`
+	EndDate     *time.Time `gorm:"type:date" json:"end_date,omitempty"`
+	Description string     `gorm:"type:text;default:''" json:"description"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Experience) TableName() string {
+	return "experiences"
+}
+
+// Education — a school or university entry. EndDate is nil while studying.
+type Education struct {
+	ID           uint64     `gorm:"primaryKey" json:"id"`
+	UserID       uint64     `gorm:"not null;index" json:"user_id"`
+	School       string     `gorm:"size:150;not null" json:"school"`
+	Degree       string     `gorm:"size:150;default:''" json:"degree"`
+	FieldOfStudy string     `gorm:"size:150;default:''" json:"field_of_study"`
+	StartDate    time.Time  `gorm:"type:date;not null" json:"start_date"`
+	EndDate      *time.Time `gorm:"type:date" json:"end_date,omitempty"`
+	Description  string     `gorm:"type:text;default:''" json:"description"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Education) TableName() string {
+	return "educations"
+}
+
+// ProfileLink — an external link such as a portfolio or GitHub profile
+type ProfileLink struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	UserID    uint64    `gorm:"not null;index" json:"user_id"`
+	Label     string    `gorm:"size:50;not null" json:"label"`
+	URL       string    `gorm:"type:text;not null" json:"url"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (ProfileLink) TableName() string {
+	return "profile_links"
+}
+
+func NewExperience(userID uint64) Experience {
+	return Experience{ID: db.GenerateID(), UserID: userID}
+}
+
+func NewEducation(userID uint64) Education {
+	return Education{ID: db.GenerateID(), UserID: userID}
+}
+
+func NewProfileLink(userID uint64, label, url string) ProfileLink {
+	return ProfileLink{ID: db.GenerateID(), UserID: userID, Label: label, URL: url}
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RateLimitBucket — one fixed-window request counter, used when rate limits
+// are shared between replicas (RATE_LIMIT_STORE=postgres)
+type RateLimitBucket struct {
+	Key     string    `gorm:"primaryKey;size:255"`
+	Count   int64     `gorm:"not null"`
+	ResetAt time.Time `gorm:"not null;index"`
+}
+
+func (RateLimitBucket) TableName() string {
+	return "rate_limit_buckets"
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// Report target types
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetUser    = "user"
+)
+
+// Report statuses
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusActioned  = "actioned"  // a moderator hid/removed the content or suspended the user
+	ReportStatusDismissed = "dismissed" // a moderator decided no action was needed
+)
+
+// Reports filed by the content filter rather than a user
+const (
+	SystemReporterID      uint64 = 0
+	ReportReasonAutomated        = "automated"
+)
+
+// ReportReasons are the reasons a user can pick when reporting
+var ReportReasons = []string{
+	"spam",
+	"harassment",
+	"hate_speech",
+	"misinformation",
+	"inappropriate",
+	"impersonation",
+	"other",
+}
+
+// Report — a user flagging a post, comment or user for moderator review.
+// A reporter has at most one open report per target.
+type Report struct {
+	ID         uint64     `gorm:"primaryKey" json:"id"`
+	ReporterID uint64     `gorm:"not null;index;uniqueIndex:idx_reports_one_open,where:status = 'open'" json:"reporter_id"`
+	TargetType string     `gorm:"type:varchar(20);not null;index:idx_reports_target;uniqueIndex:idx_reports_one_open" json:"target_type"`
+	TargetID   uint64     `gorm:"not null;index:idx_reports_target;uniqueIndex:idx_reports_one_open" json:"target_id"`
+	Reason     string     `gorm:"type:varchar(50);not null" json:"reason"`
+	Details    string     `gorm:"type:text;default:''" json:"details"`
+	Status     string     `gorm:"type:varchar(20);not null;default:'open';index" json:"status"`
+	ResolvedBy *uint64    `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (Report) TableName() string {
+	return "reports"
+}
+
+func NewReport(reporterID uint64, targetType string, targetID uint64, reason, details string) Report {
+	return Report{
+		ID:         db.GenerateID(),
+		ReporterID: reporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     reason,
+		Details:    details,
+		Status:     ReportStatusOpen,
+	}
+}
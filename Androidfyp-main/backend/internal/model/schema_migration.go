@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// SchemaVersion is the schema this build migrates to. Bump it whenever
+// AutoMigrate starts creating or changing something new.
+const SchemaVersion = 9
+
+// SchemaMigration — the single row recording the newest schema any replica
+// has migrated the database to. /health/ready compares it to SchemaVersion.
+type SchemaMigration struct {
+	ID         uint      `gorm:"primaryKey"`
+	Version    int       `gorm:"not null"`
+	MigratedAt time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
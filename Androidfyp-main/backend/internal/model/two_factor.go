@@ -0,0 +1,49 @@
+package model
+
+import (
+	"time"
+
+	db "wazzafak_back/internal/database"
+)
+
+// TwoFactor — a user's TOTP setup. The row exists from enrollment on, but
+// login only asks for a code once EnabledAt is set.
+type TwoFactor struct {
+	UserID         uint64     `gorm:"primaryKey" json:"user_id"`
+	Secret         string     `gorm:"size:64;not null" json:"-"` // base32
+	EnabledAt      *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep   int64      `gorm:"not null;default:0" json:"-"` // a code can't be replayed
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"` // wrong codes since the last lockout
+	LockedUntil    *time.Time `json:"-"`                           // codes are refused until then
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (TwoFactor) TableName() string {
+	return "two_factors"
+}
+
+func (t TwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// RecoveryCode — a one-time code for when the authenticator is lost.
+// Only a SHA-256 hash is stored; the codes are shown once.
+type RecoveryCode struct {
+	ID        uint64     `gorm:"primaryKey" json:"id"`
+	UserID    uint64     `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+func NewRecoveryCode(userID uint64, codeHash string) RecoveryCode {
+	return RecoveryCode{
+		ID:       db.GenerateID(),
+		UserID:   userID,
+		CodeHash: codeHash,
+	}
+}
@@ -8,17 +8,30 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// DefaultPhotoURL is the profile picture of users who have not uploaded one
+const DefaultPhotoURL = "https://upload.wikimedia.org/wikipedia/commons/9/99/Sample_User_Icon.png"
+
 type User struct {
-	ID              uint64    `gorm:"primaryKey" json:"id"`
-	Name            string    `gorm:"not null" json:"name"`
-	Username        string    `gorm:"unique;not null" json:"username"`
-	Email           string    `gorm:"unique;not null" json:"email"`
-	Password        string    `gorm:"not null" json:"-"` // Hide password in JSON
-	PhotoURL        string    `gorm:"not null;default:'https://upload.wikimedia.org/wikipedia/commons/9/99/Sample_User_Icon.png'" json:"photo_url"`
-	IsAdmin         bool      `gorm:"default:false" json:"is_admin"`
-	JobPosition     string    `gorm:"not null" json:"job_position"`
-	JobPositionType string    `gorm:"not null" json:"job_position_type"`
-	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID                uint64     `gorm:"primaryKey" json:"id"`
+	Name              string     `gorm:"not null" json:"name"`
+	Username          string     `gorm:"unique;not null" json:"username"`
+	Email             string     `gorm:"unique;not null" json:"email"`
+	Password          string     `gorm:"not null" json:"-"` // Hide password in JSON
+	PhotoURL          string     `gorm:"not null;default:'https://upload.wikimedia.org/wikipedia/commons/9/99/Sample_User_Icon.png'" json:"photo_url"`
+	IsAdmin           bool       `gorm:"default:false" json:"is_admin"`
+	IsRecruiter       bool       `gorm:"not null;default:false" json:"is_recruiter"` // may post jobs; granted by an admin
+	JobPosition       string     `gorm:"not null" json:"job_position"`
+	JobPositionType   string     `gorm:"not null" json:"job_position_type"`
+	Headline          string     `gorm:"size:150;default:''" json:"headline"`
+	Bio               string     `gorm:"type:text;default:''" json:"bio"`
+	SuspendedAt       *time.Time `json:"-"` // set by a moderator
+	SuspendedUntil    *time.Time `json:"-"` // nil while suspended means indefinitely
+	SuspendReason     string     `gorm:"type:text;default:''" json:"-"`
+	UsernameChangedAt *time.Time `json:"-"`              // for the username change cooldown
+	PasswordChangedAt *time.Time `json:"-"`              // tokens issued before this are rejected
+	DeleteAfter       *time.Time `gorm:"index" json:"-"` // pending deletion; purged once this passes
+	AccountDeletedAt  *time.Time `json:"-"`              // set when the account was purged and anonymized
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
 // NewUser creates a new User with hashed password and generated ID
@@ -36,7 +49,7 @@ func NewUser(username, name, email, password, jobPosition, jobPositionType strin
 		Password:        string(hashedPassword),
 		JobPosition:     jobPosition,
 		JobPositionType: jobPositionType,
-		PhotoURL:        "https://upload.wikimedia.org/wikipedia/commons/9/99/Sample_User_Icon.png",
+		PhotoURL:        DefaultPhotoURL,
 		IsAdmin:         false,
 	}, nil
 }
@@ -46,3 +59,11 @@ func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
 	return err == nil
 }
+
+// IsSuspended reports whether a moderator has suspended the account at the given time
+func (u *User) IsSuspended(now time.Time) bool {
+	if u.SuspendedAt == nil {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+
+	"wazzafak_back/internal/apperr"
+)
+
+// maxValidatedBody caps how much of a body ValidateRequests buffers. Larger
+// bodies go to the handler unchecked; the handler's own decoding still applies.
+const maxValidatedBody = 1 << 20
+
+// ValidateRequests rejects JSON bodies that don't fit the request schema the
+// document gives their route, before the handler decodes them. Routes the
+// document doesn't know, bodiless operations and non-JSON uploads pass through.
+func ValidateRequests(doc *Document) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			op, _ := doc.Find(r.Method, r.URL.Path)
+			if op == nil || op.RequestBody == nil || r.Body == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			media, ok := op.RequestBody.Content["application/json"]
+			if !ok || media.Schema == nil || !isJSONRequest(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxValidatedBody+1))
+			if err != nil {
+				apperr.Write(w, r, apperr.Invalid("Could not read the request body"))
+				return
+			}
+			if len(body) > maxValidatedBody {
+				r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+				next.ServeHTTP(w, r)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if len(bytes.TrimSpace(body)) == 0 {
+				if op.RequestBody.Required {
+					apperr.Write(w, r, apperr.Invalid("Request body is required"))
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			value, err := Decode(body)
+			if err != nil {
+				apperr.Write(w, r, apperr.Invalid("Request body is not valid JSON"))
+				return
+			}
+			if err := doc.Validate(media.Schema, value); err != nil {
+				apperr.Write(w, r, apperr.Invalid(err.Error()))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isJSONRequest treats a missing Content-Type as JSON, which is what the app sends
+func isJSONRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && isJSON(mediaType)
+}
+
+// readCloser puts already-read bytes back in front of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// Handler serves the document as JSON
+func Handler(doc *Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(doc)
+	}
+}
@@ -0,0 +1,194 @@
+// Package openapi holds an OpenAPI 3.0 description of the HTTP API and uses
+// it at runtime: request bodies are validated against it, and the contract
+// tests check that handler responses match it.
+package openapi
+
+import (
+	"sort"
+	"strings"
+)
+
+// Version is the OpenAPI version the document is written in
+const Version = "3.0.3"
+
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Security   []Security           `json:"security,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// Security names the schemes an operation accepts, e.g. {"bearerAuth": []}
+type Security map[string][]string
+
+// PathItem maps a lower-case HTTP method to its operation
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Security    *[]Security          `json:"security,omitempty"` // empty for public routes, nil inherits the document's
+	Servers     []Server             `json:"servers,omitempty"`  // set for operations served by another service
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// New starts an empty document
+func New(info Info) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   map[string]*PathItem{},
+		Components: Components{
+			Schemas:         map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{},
+		},
+	}
+}
+
+// Add registers op under method and a path template such as /posts/{postID}
+func (d *Document) Add(method, path string, op *Operation) {
+	item, ok := d.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[path] = item
+	}
+	(*item)[strings.ToLower(method)] = op
+}
+
+// Operation looks up an operation by its exact path template
+func (d *Document) Operation(method, path string) *Operation {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	return (*item)[strings.ToLower(method)]
+}
+
+// Route is one method and path template of the document
+type Route struct {
+	Method    string
+	Path      string
+	Operation *Operation
+}
+
+// Routes lists every operation, sorted by path then method
+func (d *Document) Routes() []Route {
+	var routes []Route
+	for path, item := range d.Paths {
+		for method, op := range *item {
+			routes = append(routes, Route{Method: strings.ToUpper(method), Path: path, Operation: op})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Find matches a request path against the document's templates. Literal
+// segments win over parameters, the way the router picks /posts/all over
+// /posts/{postID}. Operations of other services are never matched.
+func (d *Document) Find(method, path string) (*Operation, string) {
+	segments := splitPath(path)
+	method = strings.ToLower(method)
+
+	var best *Operation
+	var bestPath, bestRank string
+	for template, item := range d.Paths {
+		op := (*item)[method]
+		if op == nil || len(op.Servers) > 0 {
+			continue
+		}
+		rank, ok := matchTemplate(splitPath(template), segments)
+		if ok && rank > bestRank {
+			best, bestPath, bestRank = op, template, rank
+		}
+	}
+	return best, bestPath
+}
+
+// matchTemplate reports whether segments fit template. The rank has one
+// character per segment, "1" for a literal and "0" for a parameter, so a
+// plain string comparison prefers the most literal match.
+func matchTemplate(template, segments []string) (string, bool) {
+	if len(template) != len(segments) {
+		return "", false
+	}
+	var rank strings.Builder
+	for i, part := range template {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			if segments[i] == "" {
+				return "", false
+			}
+			rank.WriteByte('0')
+			continue
+		}
+		if part != segments[i] {
+			return "", false
+		}
+		rank.WriteByte('1')
+	}
+	return rank.String(), true
+}
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
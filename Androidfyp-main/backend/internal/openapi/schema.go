@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Schema is the subset of the OpenAPI schema object the API uses
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// IDPattern is what decimal IDs sent as strings look like
+const IDPattern = "^[0-9]+$"
+
+// Ref points at a schema in the document's components
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// String, Integer, Number, Boolean and friends build the common schemas
+func String() *Schema   { return &Schema{Type: "string"} }
+func Integer() *Schema  { return &Schema{Type: "integer", Format: "int64"} }
+func Number() *Schema   { return &Schema{Type: "number", Format: "double"} }
+func Boolean() *Schema  { return &Schema{Type: "boolean"} }
+func DateTime() *Schema { return &Schema{Type: "string", Format: "date-time"} }
+func Binary() *Schema   { return &Schema{Type: "string", Format: "binary"} }
+func StringID() *Schema { return &Schema{Type: "string", Pattern: IDPattern} }
+
+func Array(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func Map(values *Schema) *Schema {
+	return &Schema{Type: "object", AdditionalProperties: values}
+}
+
+func Object(props Props) *Schema {
+	return &Schema{Type: "object", Properties: props}
+}
+
+// Nullable lets s be null as well; a $ref can't carry siblings, so refs are wrapped
+func Nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+// Describe sets a schema's description
+func Describe(s *Schema, text string) *Schema {
+	s.Description = text
+	return s
+}
+
+// Props is shorthand for a schema's properties
+type Props map[string]*Schema
+
+// Require marks properties as required; strings among them must also be non-empty
+func Require(s *Schema, names ...string) *Schema {
+	one := 1
+	for _, name := range names {
+		s.Required = append(s.Required, name)
+		if prop := s.Properties[name]; prop != nil && prop.Type == "string" && prop.MinLength == nil {
+			prop.MinLength = &one
+		}
+	}
+	return s
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Generator derives schemas from Go types by the rules encoding/json follows.
+// Named structs become components, so a type is described once however many
+// operations return it. Fields without omitempty are always present in
+// responses, so they are listed as required.
+type Generator struct {
+	doc   *Document
+	names map[reflect.Type]string
+	input bool // describing a request body
+}
+
+func NewGenerator(doc *Document) *Generator {
+	return &Generator{doc: doc, names: map[reflect.Type]string{}}
+}
+
+// Schema describes the JSON encoding of v's type
+func (g *Generator) Schema(v interface{}) *Schema {
+	return g.schema(reflect.TypeOf(v))
+}
+
+// Input describes a request body type. Unlike Schema nothing is required
+// unless listed, since handlers accept bodies with fields left out, and
+// nested structs are written inline rather than shared with responses.
+func (g *Generator) Input(v interface{}, required ...string) *Schema {
+	g.input = true
+	defer func() { g.input = false }()
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return Require(g.object(t), required...)
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return DateTime()
+	case rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return Nullable(g.schema(t.Elem()))
+	case reflect.String:
+		return String()
+	case reflect.Bool:
+		return Boolean()
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return Integer()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		s := Integer()
+		s.Minimum = &zero
+		return s
+	case reflect.Float32, reflect.Float64:
+		return Number()
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return Array(g.schema(t.Elem()))
+	case reflect.Map:
+		return Map(g.schema(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" || g.input {
+			return g.object(t)
+		}
+		return g.component(t)
+	}
+	panic(fmt.Sprintf("openapi: cannot describe %s", t))
+}
+
+// component registers a named struct once and refers to it
+func (g *Generator) component(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return Ref(name)
+	}
+
+	name := t.Name()
+	if _, taken := g.doc.Components.Schemas[name]; taken {
+		// Two packages use the same type name, e.g. model.X and service.X
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = string(unicode.ToUpper(rune(pkg[0]))) + pkg[1:] + name
+	}
+
+	// Register before describing the fields so recursive types terminate
+	g.names[t] = name
+	g.doc.Components.Schemas[name] = &Schema{}
+	*g.doc.Components.Schemas[name] = *g.object(t)
+	return Ref(name)
+}
+
+func (g *Generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.fields(t, s)
+	return s
+}
+
+func (g *Generator) fields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, s)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.schema(field.Type)
+		if hasOption(opts, "string") {
+			prop = String()
+		}
+		if isIDName(name) && prop.Type == "string" {
+			prop.Pattern = IDPattern
+		}
+		if isIDListName(name) && prop.Items != nil && prop.Items.Type == "string" {
+			prop.Items.Pattern = IDPattern
+		}
+		s.Properties[name] = prop
+		if !g.input && !hasOption(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func hasOption(opts, option string) bool {
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// isIDName spots fields such as id, post_id and last_read_message_id
+func isIDName(name string) bool {
+	return name == "id" || strings.HasSuffix(name, "_id")
+}
+
+func isIDListName(name string) bool {
+	return strings.HasSuffix(name, "_ids")
+}
@@ -0,0 +1,262 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ValidationError points at the first value that breaks a schema
+type ValidationError struct {
+	Path    string // e.g. poll.options[1], empty for the body itself
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return "body " + e.Message
+	}
+	return e.Path + " " + e.Message
+}
+
+// Decode parses a JSON document the way Validate expects it, with numbers
+// kept as json.Number so large IDs aren't rounded
+func Decode(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// Validate checks a decoded JSON value against schema
+func (d *Document) Validate(schema *Schema, value interface{}) error {
+	return d.validate(schema, value, "")
+}
+
+// ValidateResponse checks a response against what op documents for its
+// status: the status has to be listed (or covered by "default"), the content
+// type has to be one of the listed ones and the body has to fit its schema
+func (d *Document) ValidateResponse(op *Operation, status int, contentType string, body []byte) error {
+	response := op.Responses[strconv.Itoa(status)]
+	if response == nil {
+		response = op.Responses["default"]
+	}
+	if response == nil {
+		return fmt.Errorf("status %d is not documented", status)
+	}
+
+	if len(response.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return fmt.Errorf("status %d is documented without a body", status)
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	media, ok := response.Content[mediaType]
+	if !ok {
+		return fmt.Errorf("content type %q is not documented for status %d", contentType, status)
+	}
+	if media.Schema == nil || !isJSON(mediaType) {
+		return nil
+	}
+
+	value, err := Decode(body)
+	if err != nil {
+		return fmt.Errorf("body is not valid JSON: %v", err)
+	}
+	return d.Validate(media.Schema, value)
+}
+
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func (d *Document) validate(s *Schema, value interface{}, path string) error {
+	if s.Ref != "" {
+		resolved, ok := d.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+		if !ok {
+			return fmt.Errorf("openapi: unknown schema %s", s.Ref)
+		}
+		return d.validate(resolved, value, path)
+	}
+
+	if value == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return invalid(path, "must not be null")
+	}
+
+	for _, part := range s.AllOf {
+		if err := d.validate(part, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		return d.validateObject(s, value, path)
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return invalid(path, "must be an array")
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		return validateString(s, value, path)
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return invalid(path, "must be an integer")
+		}
+		if _, err := strconv.ParseInt(number.String(), 10, 64); err != nil {
+			if _, err := strconv.ParseUint(number.String(), 10, 64); err != nil {
+				return invalid(path, "must be an integer")
+			}
+		}
+		return checkMinimum(s, number, path)
+	case "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return invalid(path, "must be a number")
+		}
+		return checkMinimum(s, number, path)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid(path, "must be a boolean")
+		}
+	}
+	return nil
+}
+
+func (d *Document) validateObject(s *Schema, value interface{}, path string) error {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return invalid(path, "must be an object")
+	}
+
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			return invalid(join(path, name), "is required")
+		}
+	}
+
+	// Sorted so the same body always reports the same error
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop, ok := s.Properties[name]
+		if !ok {
+			prop = s.AdditionalProperties
+		}
+		if prop == nil {
+			continue
+		}
+		if err := d.validate(prop, object[name], join(path, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateString(s *Schema, value interface{}, path string) error {
+	text, ok := value.(string)
+	if !ok {
+		return invalid(path, "must be a string")
+	}
+
+	length := utf8.RuneCountInString(text)
+	if s.MinLength != nil && length < *s.MinLength {
+		if *s.MinLength == 1 {
+			return invalid(path, "must not be empty")
+		}
+		return invalid(path, fmt.Sprintf("must be at least %d characters", *s.MinLength))
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return invalid(path, fmt.Sprintf("must be at most %d characters", *s.MaxLength))
+	}
+
+	if len(s.Enum) > 0 && !contains(s.Enum, text) {
+		return invalid(path, "must be one of "+strings.Join(s.Enum, ", "))
+	}
+	if s.Pattern != "" && !compile(s.Pattern).MatchString(text) {
+		if s.Pattern == IDPattern {
+			return invalid(path, "must be a numeric ID")
+		}
+		return invalid(path, "must match "+s.Pattern)
+	}
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			return invalid(path, "must be an RFC 3339 date-time")
+		}
+	}
+	return nil
+}
+
+func checkMinimum(s *Schema, number json.Number, path string) error {
+	if s.Minimum == nil {
+		return nil
+	}
+	value, err := number.Float64()
+	if err != nil || value < *s.Minimum {
+		return invalid(path, fmt.Sprintf("must be at least %v", *s.Minimum))
+	}
+	return nil
+}
+
+var patterns sync.Map // pattern -> *regexp.Regexp
+
+func compile(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re := regexp.MustCompile(pattern)
+	patterns.Store(pattern, re)
+	return re
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func invalid(path, message string) error {
+	return &ValidationError{Path: path, Message: message}
+}
@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a settable time source for the store and the limiter
+type clock struct{ t time.Time }
+
+func newClock() *clock { return &clock{t: time.Unix(1700000000, 0)} }
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore(c *clock) *MemoryStore {
+	store := NewMemoryStore()
+	store.now = c.now
+	return store
+}
+
+func newTestLimiter(c *clock) *Limiter {
+	limiter := NewLimiter(newTestStore(c))
+	limiter.now = c.now
+	return limiter
+}
+
+func TestMemoryStoreCountsWithinWindow(t *testing.T) {
+	c := newClock()
+	store := newTestStore(c)
+	ctx := context.Background()
+
+	resetAt := c.t.Add(time.Minute)
+	for want := int64(1); want <= 3; want++ {
+		count, gotReset, err := store.Hit(ctx, "k", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != want || !gotReset.Equal(resetAt) {
+			t.Errorf("hit %d: Hit = %d, %v; want %d, %v", want, count, gotReset, want, resetAt)
+		}
+		c.advance(10 * time.Second)
+	}
+}
+
+func TestMemoryStoreWindowExpires(t *testing.T) {
+	c := newClock()
+	store := newTestStore(c)
+	ctx := context.Background()
+	start := c.t
+
+	store.Hit(ctx, "k", time.Minute)
+	store.Hit(ctx, "k", time.Minute)
+
+	tests := []struct {
+		at        time.Duration
+		wantCount int64
+		wantReset time.Time
+	}{
+		{59 * time.Second, 3, start.Add(time.Minute)},
+		{time.Minute, 1, start.Add(2 * time.Minute)}, // the window ends at resetAt itself
+		{90 * time.Second, 2, start.Add(2 * time.Minute)},
+	}
+	for _, tt := range tests {
+		c.t = start.Add(tt.at)
+		count, resetAt, _ := store.Hit(ctx, "k", time.Minute)
+		if count != tt.wantCount || !resetAt.Equal(tt.wantReset) {
+			t.Errorf("at +%v: Hit = %d, %v; want %d, %v", tt.at, count, resetAt, tt.wantCount, tt.wantReset)
+		}
+	}
+}
+
+func TestMemoryStoreKeysAreSeparate(t *testing.T) {
+	store := newTestStore(newClock())
+	ctx := context.Background()
+
+	store.Hit(ctx, "a", time.Minute)
+	store.Hit(ctx, "a", time.Minute)
+	if count, _, _ := store.Hit(ctx, "b", time.Minute); count != 1 {
+		t.Errorf("first hit on b counted %d", count)
+	}
+}
+
+func TestMemoryStoreSweepsExpiredWindows(t *testing.T) {
+	c := newClock()
+	store := newTestStore(c)
+	ctx := context.Background()
+
+	store.Hit(ctx, "old", time.Minute)
+	c.advance(time.Hour)
+	for i := 1; i < sweepEvery; i++ {
+		store.Hit(ctx, "live", time.Hour)
+	}
+
+	if _, ok := store.windows["old"]; ok {
+		t.Error("expired window survived the sweep")
+	}
+	if _, ok := store.windows["live"]; !ok {
+		t.Error("live window was swept")
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	c := newClock()
+	limiter := newTestLimiter(c)
+	rule := Rule{Name: "test", Limit: 2, Window: time.Minute}
+	ctx := context.Background()
+
+	tests := []struct {
+		after         time.Duration
+		wantAllowed   bool
+		wantRemaining int64
+		wantRetry     time.Duration
+	}{
+		{0, true, 1, 0},
+		{10 * time.Second, true, 0, 0},
+		{10 * time.Second, false, 0, 40 * time.Second},
+		{39*time.Second + 800*time.Millisecond, false, 0, time.Second}, // never less than a second
+		{time.Second, true, 1, 0},                                      // new window
+	}
+	for i, tt := range tests {
+		c.advance(tt.after)
+		decision, err := limiter.Allow(ctx, rule, "1.2.3.4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := Decision{Allowed: tt.wantAllowed, Remaining: tt.wantRemaining, RetryAfter: tt.wantRetry}
+		if decision != want {
+			t.Errorf("request %d: Allow = %+v, want %+v", i+1, decision, want)
+		}
+	}
+}
+
+func TestLimiterSeparatesRulesAndSubjects(t *testing.T) {
+	limiter := newTestLimiter(newClock())
+	ctx := context.Background()
+	login := Rule{Name: "login", Limit: 1, Window: time.Minute}
+	signup := Rule{Name: "signup", Limit: 1, Window: time.Minute}
+
+	limiter.Allow(ctx, login, "1.2.3.4")
+	for _, tt := range []struct {
+		rule    Rule
+		subject string
+		want    bool
+	}{
+		{login, "1.2.3.4", false},
+		{login, "5.6.7.8", true},
+		{signup, "1.2.3.4", true},
+	} {
+		if decision, _ := limiter.Allow(ctx, tt.rule, tt.subject); decision.Allowed != tt.want {
+			t.Errorf("%s/%s: allowed = %v, want %v", tt.rule.Name, tt.subject, decision.Allowed, tt.want)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostgresStore shares counters between replicas through the
+// rate_limit_buckets table (model.RateLimitBucket)
+type PostgresStore struct {
+	DB *gorm.DB
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{DB: db}
+}
+
+func (s *PostgresStore) Hit(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	now := time.Now()
+	var bucket struct {
+		Count   int64
+		ResetAt time.Time
+	}
+
+	// One statement, so concurrent hits on the same key can't lose updates
+	err := s.DB.WithContext(ctx).Raw(`
+		INSERT INTO rate_limit_buckets (key, count, reset_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			count    = CASE WHEN rate_limit_buckets.reset_at <= ? THEN 1 ELSE rate_limit_buckets.count + 1 END,
+			reset_at = CASE WHEN rate_limit_buckets.reset_at <= ? THEN EXCLUDED.reset_at ELSE rate_limit_buckets.reset_at END
+		RETURNING count, reset_at
+	`, key, now.Add(window), now, now).Scan(&bucket).Error
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return bucket.Count, bucket.ResetAt, nil
+}
+
+// RunPurger deletes ended windows every interval so keys that stop coming
+// back don't pile up. Blocks until ctx is cancelled.
+func (s *PostgresStore) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.DB.WithContext(ctx).Exec(`DELETE FROM rate_limit_buckets WHERE reset_at <= ?`, time.Now()).Error; err != nil {
+			slog.Error("rate limit purge failed", "error", err)
+		}
+	}
+}
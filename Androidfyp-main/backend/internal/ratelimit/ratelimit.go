@@ -0,0 +1,56 @@
+// Package ratelimit counts requests per key in fixed time windows.
+// The counting lives behind Store so a single replica can keep it in memory
+// while several replicas share it through Postgres (or anything with an
+// atomic increment-with-expiry, such as Redis INCR + PEXPIRE).
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Store counts hits per key
+type Store interface {
+	// Hit adds one to key's counter and returns the new count and when the
+	// current window ends. A key with no live window starts a new one.
+	Hit(ctx context.Context, key string, window time.Duration) (int64, time.Time, error)
+}
+
+// Rule allows Limit hits per Window for each subject (an IP, an email, ...)
+type Rule struct {
+	Name   string // keeps buckets of different rules apart
+	Limit  int64
+	Window time.Duration
+}
+
+// Decision is the outcome of one Allow call
+type Decision struct {
+	Allowed    bool
+	Remaining  int64
+	RetryAfter time.Duration // how long until the window resets, when denied
+}
+
+type Limiter struct {
+	Store Store
+	now   func() time.Time
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{Store: store, now: time.Now}
+}
+
+// Allow records one hit for subject under rule
+func (l *Limiter) Allow(ctx context.Context, rule Rule, subject string) (Decision, error) {
+	count, resetAt, err := l.Store.Hit(ctx, rule.Name+":"+subject, rule.Window)
+	if err != nil {
+		return Decision{Allowed: true}, err
+	}
+	if count > rule.Limit {
+		retryAfter := resetAt.Sub(l.now())
+		if retryAfter < time.Second {
+			retryAfter = time.Second
+		}
+		return Decision{RetryAfter: retryAfter}, nil
+	}
+	return Decision{Allowed: true, Remaining: rule.Limit - count}, nil
+}
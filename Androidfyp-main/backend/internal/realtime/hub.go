@@ -0,0 +1,160 @@
+// Package realtime fans events out to the WebSocket connections of online users.
+// The hub is in-memory, so with several replicas a user only gets events
+// produced by the replica their socket is connected to.
+package realtime
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+)
+
+// Event types pushed to clients
+const (
+	EventMessage = "message"
+	EventTyping  = "typing"
+	EventRead    = "read"
+	EventRequest = "message_request" // someone you don't follow wrote to you
+	EventError   = "error"
+)
+
+// Event is one JSON frame sent to a client
+type Event struct {
+	Type           string      `json:"type"`
+	ConversationID string      `json:"conversation_id,omitempty"`
+	UserID         string      `json:"user_id,omitempty"`
+	MessageID      string      `json:"message_id,omitempty"`
+	Message        interface{} `json:"message,omitempty"`
+	Error          string      `json:"error,omitempty"`
+}
+
+// sendBuffer is how many frames may queue for one connection before it is
+// considered too slow and dropped
+const sendBuffer = 64
+
+// Client is one connection of a user; the WebSocket writer drains Send
+type Client struct {
+	UserID uint64
+	Send   chan []byte
+}
+
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[uint64]map[*Client]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[uint64]map[*Client]struct{})}
+}
+
+// Default is the hub used by the service and handler layers
+var Default = NewHub()
+
+// Register adds a connection for the user
+func (h *Hub) Register(userID uint64) *Client {
+	client := &Client{UserID: userID, Send: make(chan []byte, sendBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*Client]struct{})
+	}
+	h.clients[userID][client] = struct{}{}
+	return client
+}
+
+// Unregister removes a connection and closes its Send channel
+func (h *Hub) Unregister(client *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(client)
+}
+
+// remove must be called with the write lock held
+func (h *Hub) remove(client *Client) {
+	conns, ok := h.clients[client.UserID]
+	if !ok {
+		return
+	}
+	if _, ok := conns[client]; !ok {
+		return
+	}
+	delete(conns, client)
+	close(client.Send)
+	if len(conns) == 0 {
+		delete(h.clients, client.UserID)
+	}
+}
+
+// CloseAll drops every connection, e.g. on shutdown; the app reconnects to
+// another replica
+func (h *Hub) CloseAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conns := range h.clients {
+		for client := range conns {
+			h.remove(client)
+		}
+	}
+}
+
+// DisconnectUser drops every connection of the user, e.g. once their
+// sessions are revoked. Sockets only check the token when they connect, so
+// without this a stolen session would keep chatting.
+func (h *Hub) DisconnectUser(userID uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients[userID] {
+		h.remove(client)
+	}
+}
+
+// Reply sends an event to a single connection, e.g. an error for a frame it sent
+func (h *Hub) Reply(client *Client, event Event) {
+	frame, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	// The connection may already have been dropped, which closes Send
+	if _, ok := h.clients[client.UserID][client]; !ok {
+		return
+	}
+	select {
+	case client.Send <- frame:
+	default:
+	}
+}
+
+// Publish sends the event to every connection of the given users. A
+// connection whose buffer is full is dropped; the app reloads history on reconnect.
+func (h *Hub) Publish(userIDs []uint64, event Event) {
+	frame, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("realtime: encode event failed", "type", event.Type, "error", err)
+		return
+	}
+
+	var slow []*Client
+	h.mu.RLock()
+	for _, userID := range userIDs {
+		for client := range h.clients[userID] {
+			select {
+			case client.Send <- frame:
+			default:
+				slow = append(slow, client)
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(slow) > 0 {
+		h.mu.Lock()
+		for _, client := range slow {
+			h.remove(client)
+		}
+		h.mu.Unlock()
+	}
+}
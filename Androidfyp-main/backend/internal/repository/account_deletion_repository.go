@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNoDeletionPending = errors.New("no account deletion pending")
+
+// DeletedUserName is shown in place of the name of a purged account
+const DeletedUserName = "Deleted user"
+
+// ScheduleAccountDeletion marks the account to be purged after deleteAfter
+func ScheduleAccountDeletion(db *gorm.DB, userID uint64, deleteAfter time.Time) error {
+	return db.Model(&model.User{}).
+		Where("id = ? AND account_deleted_at IS NULL", userID).
+		Update("delete_after", deleteAfter).Error
+}
+
+// CancelAccountDeletion clears a pending deletion
+func CancelAccountDeletion(db *gorm.DB, userID uint64) error {
+	result := db.Model(&model.User{}).
+		Where("id = ? AND delete_after IS NOT NULL AND account_deleted_at IS NULL", userID).
+		Update("delete_after", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNoDeletionPending
+	}
+	return nil
+}
+
+// PurgeDueAccount claims one account whose grace period is over and purges it
+// in a single transaction. The row is claimed with FOR UPDATE SKIP LOCKED so
+// replicas never purge the same account twice. found is false when nothing is due.
+func PurgeDueAccount(db *gorm.DB, now time.Time) (userID uint64, found bool, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var user model.User
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("delete_after <= ? AND account_deleted_at IS NULL", now).
+			Order("delete_after ASC").
+			Limit(1).
+			Find(&user)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := purgeAccount(tx, &user, now); err != nil {
+			return err
+		}
+		userID, found = user.ID, true
+		return nil
+	})
+	return userID, found, err
+}
+
+// purgeAccount removes what the user created and anonymizes the user row.
+// Messages stay so the other side keeps its conversations; they are shown
+// as sent by the anonymized account.
+func purgeAccount(tx *gorm.DB, user *model.User, now time.Time) error {
+	var postIDs []uint64
+	if err := tx.Unscoped().Model(&model.Post{}).Where("user_id = ?", user.ID).Pluck("id", &postIDs).Error; err != nil {
+		return err
+	}
+	for _, postID := range postIDs {
+		// Reposts of the user's own posts are purged along with the original
+		if err := PurgePost(tx, postID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+	}
+
+	// What the user did on other people's content and profiles
+	if err := tx.Unscoped().Where("user_id = ?", user.ID).Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.Like{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.PollVote{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("endorser_id = ?", user.ID).Delete(&model.SkillEndorsement{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("follower_id = ? OR following_id = ?", user.ID, user.ID).Delete(&model.Follow{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("blocker_id = ? OR blocked_id = ?", user.ID, user.ID).Delete(&model.Block{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("user_id = ? OR from_user_id = ?", user.ID, user.ID).Delete(&model.Notification{}).Error; err != nil {
+		return err
+	}
+
+	// Private collections
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.Bookmark{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.BookmarkCollection{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.SavedJob{}).Error; err != nil {
+		return err
+	}
+
+	// Applications carry the CV, so they are removed with their history
+	if err := tx.Where("application_id IN (?)",
+		tx.Model(&model.Application{}).Select("id").Where("user_id = ?", user.ID),
+	).Delete(&model.ApplicationStageChange{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.Application{}).Error; err != nil {
+		return err
+	}
+
+	// Jobs stay for the candidates who applied, but stop taking applications
+	if err := tx.Model(&model.Job{}).
+		Where("recruiter_id = ? AND status = ?", user.ID, model.JobStatusOpen).
+		Updates(map[string]interface{}{
+			"status":    model.JobStatusClosed,
+			"closed_at": now,
+		}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("user_id = ?", user.ID).Delete(&model.CompanyMember{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("LOWER(email) = LOWER(?)", user.Email).Delete(&model.CompanyInvite{}).Error; err != nil {
+		return err
+	}
+
+	// Profile sections
+	if err := tx.Where("skill_id IN (?)",
+		tx.Model(&model.UserSkill{}).Select("id").Where("user_id = ?", user.ID),
+	).Delete(&model.SkillEndorsement{}).Error; err != nil {
+		return err
+	}
+	for _, section := range []interface{}{&model.UserSkill{}, &model.Experience{}, &model.Education{}, &model.ProfileLink{}} {
+		if err := tx.Where("user_id = ?", user.ID).Delete(section).Error; err != nil {
+			return err
+		}
+	}
+
+	// Account and verification rows
+	for _, row := range []interface{}{&model.UsernameReservation{}, &model.EmailChangeRequest{}, &model.RecoveryCode{}, &model.TwoFactor{}} {
+		if err := tx.Where("user_id = ?", user.ID).Delete(row).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("email = ?", user.Email).Delete(&model.PasswordReset{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM email_verifications WHERE email = ?", user.Email).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("to_email = ?", user.Email).Delete(&model.OutboxEmail{}).Error; err != nil {
+		return err
+	}
+	if err := anonymizeAuditEvents(tx, user.ID); err != nil {
+		return err
+	}
+
+	return tx.Model(&model.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"name":                DeletedUserName,
+		"username":            fmt.Sprintf("deleted_%d", user.ID),
+		"email":               fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+		"password":            "!", // matches no bcrypt hash
+		"photo_url":           model.DefaultPhotoURL,
+		"job_position":        "",
+		"job_position_type":   "",
+		"headline":            "",
+		"bio":                 "",
+		"is_recruiter":        false,
+		"delete_after":        nil,
+		"account_deleted_at":  now,
+		"password_changed_at": now,
+	}).Error
+}
+
+// anonymizeAuditEvents keeps that the account's events happened but drops
+// where they came from. The setting is local to the purge's transaction.
+func anonymizeAuditEvents(tx *gorm.DB, userID uint64) error {
+	if err := tx.Exec(`SELECT set_config('` + model.AuditAnonymizeSetting + `', 'on', true)`).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&model.AuditEvent{}).Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"ip": "", "user_agent": "", "metadata": nil}).Error; err != nil {
+		return err
+	}
+	return tx.Exec(`SELECT set_config('` + model.AuditAnonymizeSetting + `', 'off', true)`).Error
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// AccountData is everything the social backend stores about one user,
+// gathered for a data export
+type AccountData struct {
+	User               model.User
+	Skills             []model.UserSkill
+	Experiences        []model.Experience
+	Educations         []model.Education
+	Links              []model.ProfileLink
+	EndorsementsGiven  []model.SkillEndorsement
+	Posts              []model.Post
+	Comments           []model.Comment
+	Likes              []model.Like
+	PollVotes          []model.PollVote
+	Following          []model.Follow
+	Followers          []model.Follow
+	Blocks             []model.Block
+	Notifications      []model.Notification
+	Bookmarks          []model.Bookmark
+	Collections        []model.BookmarkCollection
+	SavedJobs          []model.SavedJob
+	Applications       []model.Application
+	Jobs               []model.Job
+	Companies          []UserCompany
+	Conversations      []model.ConversationMember
+	Messages           []model.Message
+	Reports            []model.Report
+	UsernameHistory    []model.UsernameReservation
+	PendingEmailChange []model.EmailChangeRequest
+	SecurityEvents     []model.AuditEvent
+}
+
+// GetAccountData loads the user's rows from every table. Messages are limited
+// to the ones the user sent; the other side's messages belong to them.
+func GetAccountData(db *gorm.DB, userID uint64) (*AccountData, error) {
+	data := AccountData{}
+	if err := db.First(&data.User, userID).Error; err != nil {
+		return nil, err
+	}
+
+	byUser := []struct {
+		column string
+		dest   interface{}
+		order  string
+	}{
+		{"user_id", &data.Skills, "created_at"},
+		{"user_id", &data.Experiences, "created_at"},
+		{"user_id", &data.Educations, "created_at"},
+		{"user_id", &data.Links, "created_at"},
+		{"endorser_id", &data.EndorsementsGiven, "created_at"},
+		{"user_id", &data.Posts, "created_at"},
+		{"user_id", &data.Comments, "created_at"},
+		{"user_id", &data.Likes, "created_at"},
+		{"user_id", &data.PollVotes, "created_at"},
+		{"follower_id", &data.Following, "created_at"},
+		{"following_id", &data.Followers, "created_at"},
+		{"blocker_id", &data.Blocks, "created_at"},
+		{"user_id", &data.Notifications, "created_at"},
+		{"user_id", &data.Bookmarks, "created_at"},
+		{"user_id", &data.Collections, "created_at"},
+		{"user_id", &data.SavedJobs, "created_at"},
+		{"user_id", &data.Applications, "created_at"},
+		{"recruiter_id", &data.Jobs, "created_at"},
+		{"user_id", &data.Conversations, "joined_at"},
+		{"sender_id", &data.Messages, "created_at"},
+		{"reporter_id", &data.Reports, "created_at"},
+		{"user_id", &data.UsernameHistory, "created_at"},
+		{"user_id", &data.PendingEmailChange, "created_at"},
+		{"user_id", &data.SecurityEvents, "created_at"},
+	}
+	for _, section := range byUser {
+		if err := db.Where(section.column+" = ?", userID).Order(section.order + " ASC").Find(section.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	companies, err := GetUserCompanies(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	data.Companies = companies
+
+	return &data, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUsernameTaken         = errors.New("username taken")
+	ErrUsernameCooldown      = errors.New("username changed too recently")
+	ErrEmailTaken            = errors.New("email taken")
+	ErrNoEmailChange         = errors.New("no pending email change")
+	ErrEmailChangeUnverified = errors.New("email change not verified")
+)
+
+// IsUsernameTaken reports whether another account uses the username (ignoring
+// case) or still holds it as a reservation
+func IsUsernameTaken(db *gorm.DB, username string, userID uint64) (bool, error) {
+	normalized := strings.ToLower(username)
+
+	var count int64
+	if err := db.Model(&model.User{}).
+		Where("LOWER(username) = ? AND id <> ?", normalized, userID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := db.Model(&model.UsernameReservation{}).
+		Where("username = ? AND user_id <> ? AND reserved_until > ?", normalized, userID, time.Now()).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetActiveUsernameReservation returns the reservation still holding the username
+func GetActiveUsernameReservation(db *gorm.DB, username string) (*model.UsernameReservation, error) {
+	var reservation model.UsernameReservation
+	err := db.Where("username = ? AND reserved_until > ?", strings.ToLower(username), time.Now()).
+		First(&reservation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// ChangeUsername renames the user unless they did so within cooldown, and
+// reserves the old handle for them for reservation. Taking back one's own
+// reserved handle releases it.
+func ChangeUsername(db *gorm.DB, userID uint64, newUsername string, cooldown, reservation time.Duration, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Lock the row so two renames of the same account can't interleave
+		var user model.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", userID).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if user.UsernameChangedAt != nil && now.Before(user.UsernameChangedAt.Add(cooldown)) {
+			return ErrUsernameCooldown
+		}
+		oldUsername := user.Username
+
+		taken, err := IsUsernameTaken(tx, newUsername, userID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrUsernameTaken
+		}
+
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"username":            newUsername,
+			"username_changed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("username = ? AND user_id = ?", strings.ToLower(newUsername), userID).
+			Delete(&model.UsernameReservation{}).Error; err != nil {
+			return err
+		}
+
+		// A case-only change keeps the same handle, so there is nothing to reserve
+		if strings.EqualFold(oldUsername, newUsername) {
+			return nil
+		}
+		reservation := model.UsernameReservation{
+			Username:      strings.ToLower(oldUsername),
+			UserID:        userID,
+			ReservedUntil: now.Add(reservation),
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "username"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "reserved_until"}),
+		}).Create(&reservation).Error
+	})
+}
+
+// IsEmailTaken reports whether another account already uses the email (ignoring case)
+func IsEmailTaken(db *gorm.DB, email string, userID uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.User{}).
+		Where("LOWER(email) = ? AND id <> ?", strings.ToLower(email), userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveEmailChangeRequest stores the address the user wants to move to and the
+// code sent there, replacing any earlier pending request
+func SaveEmailChangeRequest(db *gorm.DB, userID uint64, newEmail, code string, expiresAt time.Time) error {
+	request := model.EmailChangeRequest{UserID: userID, NewEmail: newEmail, Code: code, ExpiresAt: expiresAt}
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"new_email":  newEmail,
+			"code":       code,
+			"expires_at": expiresAt,
+			"attempts":   0,
+			"created_at": time.Now(),
+		}),
+	}).Create(&request).Error
+}
+
+// CountEmailChangeAttempt records one guess at the code of the user's request.
+// It returns false once maxAttempts guesses were made.
+func CountEmailChangeAttempt(db *gorm.DB, userID uint64, maxAttempts int) (bool, error) {
+	result := db.Model(&model.EmailChangeRequest{}).
+		Where("user_id = ? AND attempts < ?", userID, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+func GetEmailChangeRequest(db *gorm.DB, userID uint64) (*model.EmailChangeRequest, error) {
+	var request model.EmailChangeRequest
+	if err := db.Where("user_id = ?", userID).First(&request).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoEmailChange
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+// CompleteEmailChange moves the user to the address of their pending request
+// and clears it. code is the one the caller checked; a request replaced since
+// then is left alone.
+func CompleteEmailChange(db *gorm.DB, userID uint64, code string) (*model.EmailChangeRequest, error) {
+	var request model.EmailChangeRequest
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&request).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoEmailChange
+			}
+			return err
+		}
+		if request.Code != code {
+			return ErrEmailChangeUnverified
+		}
+
+		taken, err := IsEmailTaken(tx, request.NewEmail, userID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrEmailTaken
+		}
+
+		if err := tx.Model(&model.User{}).Where("id = ?", userID).
+			Update("email", request.NewEmail).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&request).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrApplicationNotFound = errors.New("application not found")
+	ErrAlreadyApplied      = errors.New("already applied to this job")
+)
+
+// CreateApplication saves the application and its initial "applied" history entry.
+// A second application by the same user to the same job returns ErrAlreadyApplied.
+func CreateApplication(db *gorm.DB, application *model.Application) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(application)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyApplied
+		}
+
+		change := model.NewApplicationStageChange(application.ID, "", application.Stage, application.UserID)
+		return tx.Create(&change).Error
+	})
+}
+
+func GetApplicationByID(db *gorm.DB, id uint64) (*model.Application, error) {
+	var application model.Application
+	if err := db.First(&application, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, err
+	}
+	return &application, nil
+}
+
+// GetApplicationByJobAndUser returns the user's application to the job, if any
+func GetApplicationByJobAndUser(db *gorm.DB, jobID, userID uint64) (*model.Application, error) {
+	var application model.Application
+	if err := db.First(&application, "job_id = ? AND user_id = ?", jobID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, err
+	}
+	return &application, nil
+}
+
+// ChangeApplicationStage moves the application from one stage to another,
+// records the change and, when the recruiter made it, notifies the candidate.
+// It returns ErrApplicationNotFound if the application is no longer in fromStage.
+func ChangeApplicationStage(db *gorm.DB, application *model.Application, toStage string, changedBy uint64, notification *model.Notification, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Application{}).
+			Where("id = ? AND stage = ?", application.ID, application.Stage).
+			Updates(map[string]interface{}{"stage": toStage, "stage_changed_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrApplicationNotFound
+		}
+
+		change := model.NewApplicationStageChange(application.ID, application.Stage, toStage, changedBy)
+		if err := tx.Create(&change).Error; err != nil {
+			return err
+		}
+
+		if notification != nil {
+			if err := CreateNotification(tx, notification); err != nil {
+				return err
+			}
+		}
+
+		application.Stage = toStage
+		application.StageChangedAt = now
+		return nil
+	})
+}
+
+func UpdateApplicationNotes(db *gorm.DB, applicationID uint64, notes string) error {
+	result := db.Model(&model.Application{}).Where("id = ?", applicationID).Update("recruiter_notes", notes)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrApplicationNotFound
+	}
+	return nil
+}
+
+// GetApplicationStageChanges returns the application's history, oldest first
+func GetApplicationStageChanges(db *gorm.DB, applicationID uint64) ([]model.ApplicationStageChange, error) {
+	var changes []model.ApplicationStageChange
+	err := db.Where("application_id = ?", applicationID).Order("created_at ASC, id ASC").Find(&changes).Error
+	return changes, err
+}
+
+// ApplicantFilter narrows the applicants listed for a job
+type ApplicantFilter struct {
+	Stage string
+	Query string // searched in the applicant's name and username
+}
+
+// Applicant is an application with the candidate's public details
+type Applicant struct {
+	model.Application
+	Name     string
+	Username string
+	PhotoURL string
+}
+
+// GetJobApplicants returns one page of a job's applications, newest first
+func GetJobApplicants(db *gorm.DB, jobID uint64, filter ApplicantFilter, cursor *Cursor, limit int) ([]Applicant, error) {
+	var applicants []Applicant
+	query := db.Table("applications a").
+		Select("a.*, u.name, u.username, u.photo_url").
+		Joins("JOIN users u ON u.id = a.user_id").
+		Where("a.job_id = ?", jobID)
+
+	if filter.Stage != "" {
+		query = query.Where("a.stage = ?", filter.Stage)
+	}
+	if filter.Query != "" {
+		pattern := likePattern(filter.Query)
+		query = query.Where("u.name ILIKE ? OR u.username ILIKE ?", pattern, pattern)
+	}
+	if cursor != nil {
+		query = query.Where("(a.created_at, a.id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("a.created_at DESC, a.id DESC").Limit(limit).Scan(&applicants).Error
+	return applicants, err
+}
+
+// CountJobApplicantsByStage returns how many applications the job has per stage
+func CountJobApplicantsByStage(db *gorm.DB, jobID uint64) (map[string]int64, error) {
+	var rows []struct {
+		Stage string
+		Count int64
+	}
+	err := db.Model(&model.Application{}).
+		Select("stage, COUNT(*) AS count").
+		Where("job_id = ?", jobID).
+		Group("stage").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Stage] = row.Count
+	}
+	return counts, nil
+}
+
+// UserApplication is an application with the job it was made to
+type UserApplication struct {
+	model.Application
+	JobTitle   string
+	JobCompany string
+	JobStatus  string
+}
+
+// GetUserApplications returns one page of the user's applications, newest first
+func GetUserApplications(db *gorm.DB, userID uint64, stage string, cursor *Cursor, limit int) ([]UserApplication, error) {
+	var applications []UserApplication
+	query := db.Table("applications a").
+		Select("a.*, j.title AS job_title, j.company AS job_company, j.status AS job_status").
+		Joins("JOIN jobs j ON j.id = a.job_id").
+		Where("a.user_id = ?", userID)
+
+	if stage != "" {
+		query = query.Where("a.stage = ?", stage)
+	}
+	if cursor != nil {
+		query = query.Where("(a.created_at, a.id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("a.created_at DESC, a.id DESC").Limit(limit).Scan(&applications).Error
+	return applications, err
+}
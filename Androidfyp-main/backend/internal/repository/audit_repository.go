@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// CreateAuditEvent appends an event to the security audit log. There is no
+// update or delete: the table rejects both.
+func CreateAuditEvent(db *gorm.DB, event *model.AuditEvent) error {
+	return db.Create(event).Error
+}
+
+// AuditEventFilter narrows the audit log; zero values match everything
+type AuditEventFilter struct {
+	UserID  uint64
+	ActorID uint64
+	Type    string
+	IP      string
+	From    time.Time
+	To      time.Time
+}
+
+// GetAuditEvents returns one page of the audit log, newest first
+func GetAuditEvents(db *gorm.DB, filter AuditEventFilter, cursor *Cursor, limit int) ([]model.AuditEvent, error) {
+	var events []model.AuditEvent
+	query := db.Model(&model.AuditEvent{})
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.IP != "" {
+		query = query.Where("ip = ?", filter.IP)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
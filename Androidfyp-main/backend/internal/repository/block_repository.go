@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlockUser records the block and drops the follows between the two users
+func BlockUser(db *gorm.DB, blockerID, blockedID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		block := model.Block{BlockerID: blockerID, BlockedID: blockedID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&block).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).
+			Delete(&model.Follow{}).Error
+	})
+}
+
+func UnblockUser(db *gorm.DB, blockerID, blockedID uint64) error {
+	return db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&model.Block{}).Error
+}
+
+// IsBlockedEitherWay reports whether either user has blocked the other
+func IsBlockedEitherWay(db *gorm.DB, a, b uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetBlockedUsers lists the users someone has blocked
+func GetBlockedUsers(db *gorm.DB, blockerID uint64) ([]model.User, error) {
+	var users []model.User
+	err := db.Table("users").
+		Joins("JOIN blocks ON users.id = blocks.blocked_id").
+		Where("blocks.blocker_id = ?", blockerID).
+		Order("blocks.created_at DESC").
+		Find(&users).Error
+	return users, err
+}
+
+// AreMutualFollowers reports whether the two users follow each other
+func AreMutualFollowers(db *gorm.DB, a, b uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.Follow{}).
+		Where("(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)", a, b, b, a).
+		Count(&count).Error
+	return count == 2, err
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCollectionNotFound = errors.New("collection not found")
+)
+
+// SaveBookmark inserts a bookmark, or moves an existing one to another collection
+func SaveBookmark(db *gorm.DB, userID, postID uint64, collectionID *uint64) error {
+	bookmark := model.Bookmark{
+		UserID:       userID,
+		PostID:       postID,
+		CollectionID: collectionID,
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"collection_id"}),
+	}).Create(&bookmark).Error
+}
+
+// RemoveBookmark deletes a user's bookmark on a post
+func RemoveBookmark(db *gorm.DB, userID, postID uint64) error {
+	return db.Where("user_id = ? AND post_id = ?", userID, postID).
+		Delete(&model.Bookmark{}).Error
+}
+
+// HasUserBookmarked checks whether the user saved the post
+func HasUserBookmarked(db *gorm.DB, userID, postID uint64) (bool, error) {
+	var exists bool
+	err := db.Raw(`
+		SELECT EXISTS(SELECT 1 FROM bookmarks WHERE user_id = ? AND post_id = ?)
+	`, userID, postID).Scan(&exists).Error
+	return exists, err
+}
+
+// BookmarkedPost is a post together with when/where the user saved it
+type BookmarkedPost struct {
+	model.Post
+	BookmarkedAt time.Time
+	CollectionID *uint64
+}
+
+// GetUserBookmarks returns one page of a user's bookmarks, newest first.
+// collectionID narrows the page to a single collection when set.
+func GetUserBookmarks(db *gorm.DB, userID uint64, collectionID *uint64, cursor *Cursor, limit int) ([]BookmarkedPost, error) {
+	var results []BookmarkedPost
+	query := db.Table("bookmarks").
+		Select("posts.*, bookmarks.created_at AS bookmarked_at, bookmarks.collection_id").
+		Joins("JOIN posts ON posts.id = bookmarks.post_id AND posts.deleted_at IS NULL AND posts.status = ?", model.PostStatusPublished).
+		Where("bookmarks.user_id = ?", userID)
+
+	if collectionID != nil {
+		query = query.Where("bookmarks.collection_id = ?", *collectionID)
+	}
+	if cursor != nil {
+		query = query.Where("(bookmarks.created_at, bookmarks.post_id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("bookmarks.created_at DESC, bookmarks.post_id DESC").
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
+
+// DeleteBookmarksByPost removes every bookmark pointing at a post
+func DeleteBookmarksByPost(db *gorm.DB, postID uint64) error {
+	return db.Where("post_id = ?", postID).Delete(&model.Bookmark{}).Error
+}
+
+// =================== Collections ===================
+
+// CreateBookmarkCollection inserts a new collection
+func CreateBookmarkCollection(db *gorm.DB, collection *model.BookmarkCollection) error {
+	return db.Create(collection).Error
+}
+
+// GetBookmarkCollections lists a user's collections alphabetically
+func GetBookmarkCollections(db *gorm.DB, userID uint64) ([]model.BookmarkCollection, error) {
+	var collections []model.BookmarkCollection
+	err := db.Where("user_id = ?", userID).
+		Order("name ASC").
+		Find(&collections).Error
+	return collections, err
+}
+
+// GetBookmarkCollection finds a collection that belongs to the user
+func GetBookmarkCollection(db *gorm.DB, userID, collectionID uint64) (*model.BookmarkCollection, error) {
+	var collection model.BookmarkCollection
+	err := db.Where("id = ? AND user_id = ?", collectionID, userID).First(&collection).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCollectionNotFound
+	}
+	return &collection, err
+}
+
+// BookmarkCollectionNameExists checks if the user already has a collection
+// with this name other than excludeID (0 when creating one)
+func BookmarkCollectionNameExists(db *gorm.DB, userID uint64, name string, excludeID uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.BookmarkCollection{}).
+		Where("user_id = ? AND name = ? AND id <> ?", userID, name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// RenameBookmarkCollection changes the name of a user's collection
+func RenameBookmarkCollection(db *gorm.DB, userID, collectionID uint64, name string) error {
+	result := db.Model(&model.BookmarkCollection{}).
+		Where("id = ? AND user_id = ?", collectionID, userID).
+		Update("name", name)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCollectionNotFound
+	}
+	return nil
+}
+
+// DeleteBookmarkCollection deletes a collection; its bookmarks are kept
+// but moved back to the default (uncategorised) list
+func DeleteBookmarkCollection(db *gorm.DB, userID, collectionID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", collectionID, userID).
+			Delete(&model.BookmarkCollection{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCollectionNotFound
+		}
+
+		return tx.Model(&model.Bookmark{}).
+			Where("user_id = ? AND collection_id = ?", userID, collectionID).
+			Update("collection_id", nil).Error
+	})
+}
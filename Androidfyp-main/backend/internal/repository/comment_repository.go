@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"time"
 	"wazzafak_back/internal/model"
 
 	"gorm.io/gorm"
@@ -28,8 +29,9 @@ func CreateComment(db *gorm.DB, comment *model.Comment) error {
 			return err
 		}
 
-		// Don't create notification if user comments on their own post
-		if post.UserID == comment.UserID {
+		// Don't create notification if user comments on their own post, or
+		// while the comment is held for review
+		if post.UserID == comment.UserID || comment.Hidden {
 			return nil
 		}
 
@@ -73,7 +75,7 @@ func GetCommentByID(db *gorm.DB, commentID uint64) (*model.Comment, error) {
 	return &comment, nil
 }
 
-// DeleteComment deletes a comment by its ID
+// DeleteComment soft-deletes a comment; it can be restored until it is purged
 func DeleteComment(db *gorm.DB, commentID uint64) error {
 	result := db.Where("id = ?", commentID).Delete(&model.Comment{})
 	if result.Error != nil {
@@ -85,6 +87,56 @@ func DeleteComment(db *gorm.DB, commentID uint64) error {
 	return nil
 }
 
+// GetDeletedComment finds a soft-deleted comment
+func GetDeletedComment(db *gorm.DB, commentID uint64) (*model.Comment, error) {
+	var comment model.Comment
+	err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", commentID).First(&comment).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// RestoreComment undoes DeleteComment
+func RestoreComment(db *gorm.DB, comment *model.Comment) error {
+	result := db.Unscoped().Model(&model.Comment{}).
+		Where("id = ? AND deleted_at = ?", comment.ID, comment.DeletedAt.Time).
+		UpdateColumn("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// PurgeComment hard-deletes a comment right away, e.g. when a moderator removes it
+func PurgeComment(db *gorm.DB, commentID uint64) error {
+	result := db.Unscoped().Where("id = ?", commentID).Delete(&model.Comment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedComments hard-deletes up to batchSize comments soft-deleted before cutoff
+func PurgeDeletedComments(db *gorm.DB, cutoff time.Time, batchSize int) (int64, error) {
+	result := db.Unscoped().
+		Where("id IN (?)", db.Unscoped().Model(&model.Comment{}).
+			Select("id").
+			Where("deleted_at < ?", cutoff).
+			Limit(batchSize)).
+		Delete(&model.Comment{})
+	return result.RowsAffected, result.Error
+}
+
 // DeleteCommentByPost deletes a comment that belongs to a specific post
 // Deprecated: Use GetCommentByID + DeleteComment with service-layer authorization instead
 func DeleteCommentByPost(db *gorm.DB, postID, commentID uint64) error {
@@ -114,7 +166,7 @@ func GetCommentsByPostID(db *gorm.DB, postID uint64) ([]map[string]interface{},
 			c.created_at
 		FROM comments c
 		JOIN users u ON u.id = c.user_id
-		WHERE c.post_id = ?
+		WHERE c.post_id = ? AND c.hidden = false AND c.deleted_at IS NULL
 		ORDER BY c.created_at DESC
 	`
 
@@ -125,6 +177,20 @@ func GetCommentsByPostID(db *gorm.DB, postID uint64) ([]map[string]interface{},
 	return results, nil
 }
 
+// SetCommentHidden hides or unhides a comment (moderation)
+func SetCommentHidden(db *gorm.DB, commentID uint64, hidden bool) error {
+	result := db.Model(&model.Comment{}).
+		Where("id = ? AND hidden = ?", commentID, !hidden).
+		Update("hidden", hidden)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCommentNotFound
+	}
+	return nil
+}
+
 // UpdateComment updates a comment in the database
 func UpdateComment(db *gorm.DB, comment *model.Comment) error {
 	result := db.Model(&model.Comment{}).
@@ -143,10 +209,10 @@ func UpdateComment(db *gorm.DB, comment *model.Comment) error {
 	return nil
 }
 
-// PostExists checks if a post exists in the database
+// PostExists checks if a published post exists in the database
 func PostExists(db *gorm.DB, postID uint64) (bool, error) {
 	var count int64
-	err := db.Model(&model.Post{}).Where("id = ?", postID).Count(&count).Error
+	err := db.Model(&model.Post{}).Where("id = ? AND status = ?", postID, model.PostStatusPublished).Count(&count).Error
 	if err != nil {
 		return false, err
 	}
@@ -170,7 +236,7 @@ func GetCommentsWithUsers(db *gorm.DB, postID uint64) ([]*model.Comment, error)
 // CountCommentsByPost returns the total number of comments for a post
 func CountCommentsByPost(db *gorm.DB, postID uint64) (int64, error) {
 	var count int64
-	err := db.Model(&model.Comment{}).Where("post_id = ?", postID).Count(&count).Error
+	err := db.Model(&model.Comment{}).Where("post_id = ? AND hidden = ?", postID, false).Count(&count).Error
 	return count, err
 }
 
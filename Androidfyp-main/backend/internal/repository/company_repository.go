@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrCompanyNotFound      = errors.New("company not found")
+	ErrNotCompanyMember     = errors.New("user is not a member of this company")
+	ErrLastCompanyOwner     = errors.New("a company needs at least one owner")
+	ErrInviteNotFound       = errors.New("invite not found")
+	ErrAlreadyCompanyMember = errors.New("user is already a member of this company")
+)
+
+// CreateCompany saves the company with its creator as the first owner
+func CreateCompany(db *gorm.DB, company *model.Company) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(company).Error; err != nil {
+			return err
+		}
+		owner := model.CompanyMember{CompanyID: company.ID, UserID: company.CreatedBy, Role: model.CompanyRoleOwner}
+		return tx.Create(&owner).Error
+	})
+}
+
+func GetCompanyByID(db *gorm.DB, id uint64) (*model.Company, error) {
+	var company model.Company
+	if err := db.First(&company, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	return &company, nil
+}
+
+// UpdateCompany saves the editable fields of the company page
+func UpdateCompany(db *gorm.DB, company *model.Company) error {
+	result := db.Model(company).
+		Select("name", "logo_url", "description", "website").
+		Updates(company)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCompanyNotFound
+	}
+	return nil
+}
+
+// GetCompanyRole returns the user's role in the company, or "" if they are not a member
+func GetCompanyRole(db *gorm.DB, companyID, userID uint64) (string, error) {
+	var member model.CompanyMember
+	err := db.Select("role").First(&member, "company_id = ? AND user_id = ?", companyID, userID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	return member.Role, err
+}
+
+// GetJobRole returns the user's role for a job: the company role for company
+// jobs, and owner for the recruiter who posted a personal job
+func GetJobRole(db *gorm.DB, job *model.Job, userID uint64) (string, error) {
+	if job.CompanyID == nil {
+		if job.RecruiterID == userID {
+			return model.CompanyRoleOwner, nil
+		}
+		return "", nil
+	}
+	return GetCompanyRole(db, *job.CompanyID, userID)
+}
+
+// CompanyMemberDetails is a member with their public profile
+type CompanyMemberDetails struct {
+	model.CompanyMember
+	Name     string
+	Username string
+	PhotoURL string
+}
+
+func GetCompanyMembers(db *gorm.DB, companyID uint64) ([]CompanyMemberDetails, error) {
+	var members []CompanyMemberDetails
+	err := db.Table("company_members m").
+		Select("m.*, u.name, u.username, u.photo_url").
+		Joins("JOIN users u ON u.id = m.user_id").
+		Where("m.company_id = ?", companyID).
+		Order("m.created_at ASC").
+		Scan(&members).Error
+	return members, err
+}
+
+func CountCompanyMembers(db *gorm.DB, companyID uint64) (int64, error) {
+	var count int64
+	err := db.Model(&model.CompanyMember{}).Where("company_id = ?", companyID).Count(&count).Error
+	return count, err
+}
+
+// ChangeCompanyMember sets the member's role, or removes them when role is "".
+// The owners are locked first so two owners can't demote each other at once
+// and leave the company without one.
+func ChangeCompanyMember(db *gorm.DB, companyID, userID uint64, role string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var owners []model.CompanyMember
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("company_id = ? AND role = ?", companyID, model.CompanyRoleOwner).
+			Find(&owners).Error; err != nil {
+			return err
+		}
+
+		isOwner := false
+		for _, owner := range owners {
+			if owner.UserID == userID {
+				isOwner = true
+			}
+		}
+		if isOwner && len(owners) == 1 && role != model.CompanyRoleOwner {
+			return ErrLastCompanyOwner
+		}
+
+		query := tx.Where("company_id = ? AND user_id = ?", companyID, userID)
+		var result *gorm.DB
+		if role == "" {
+			result = query.Delete(&model.CompanyMember{})
+		} else {
+			result = query.Model(&model.CompanyMember{}).Update("role", role)
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotCompanyMember
+		}
+		return nil
+	})
+}
+
+// SaveCompanyInvite stores the invite, replacing any earlier one for the same email
+func SaveCompanyInvite(db *gorm.DB, invite *model.CompanyInvite) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("company_id = ? AND email = ?", invite.CompanyID, invite.Email).
+			Delete(&model.CompanyInvite{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(invite).Error
+	})
+}
+
+// GetCompanyInvites returns the invites that have not expired yet
+func GetCompanyInvites(db *gorm.DB, companyID uint64, now time.Time) ([]model.CompanyInvite, error) {
+	var invites []model.CompanyInvite
+	err := db.Where("company_id = ? AND expires_at > ?", companyID, now).
+		Order("created_at DESC").
+		Find(&invites).Error
+	return invites, err
+}
+
+func DeleteCompanyInvite(db *gorm.DB, companyID, inviteID uint64) error {
+	result := db.Where("id = ? AND company_id = ?", inviteID, companyID).Delete(&model.CompanyInvite{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// AcceptCompanyInvite turns a valid invite for the email into a membership.
+func AcceptCompanyInvite(db *gorm.DB, email, code string, userID uint64, now time.Time) (*model.CompanyInvite, error) {
+	var invite model.CompanyInvite
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("email = ? AND code = ? AND expires_at > ?", email, code, now).
+			First(&invite).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInviteNotFound
+			}
+			return err
+		}
+
+		if err := tx.Delete(&invite).Error; err != nil {
+			return err
+		}
+
+		member := model.CompanyMember{CompanyID: invite.CompanyID, UserID: userID, Role: invite.Role}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrAlreadyCompanyMember
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// UserCompany is a company with the user's role in it
+type UserCompany struct {
+	model.Company
+	Role string
+}
+
+func GetUserCompanies(db *gorm.DB, userID uint64) ([]UserCompany, error) {
+	var companies []UserCompany
+	err := db.Table("company_members m").
+		Select("c.*, m.role").
+		Joins("JOIN companies c ON c.id = m.company_id").
+		Where("m.user_id = ?", userID).
+		Order("c.name ASC").
+		Scan(&companies).Error
+	return companies, err
+}
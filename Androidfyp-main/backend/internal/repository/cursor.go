@@ -0,0 +1,11 @@
+package repository
+
+import "time"
+
+// Cursor marks the last row of a page for keyset pagination.
+// Rows are ordered by (created_at DESC, id DESC) so the next page
+// is everything strictly "older" than the cursor.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uint64
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EndorseSkill records the endorsement and notifies the skill's owner.
+// Endorsing twice is a no-op and sends no second notification.
+func EndorseSkill(db *gorm.DB, skillID, endorserID uint64, notification *model.Notification) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		endorsement := model.SkillEndorsement{SkillID: skillID, EndorserID: endorserID}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&endorsement)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return CreateNotification(tx, notification)
+	})
+}
+
+func UnendorseSkill(db *gorm.DB, skillID, endorserID uint64) error {
+	return db.Where("skill_id = ? AND endorser_id = ?", skillID, endorserID).Delete(&model.SkillEndorsement{}).Error
+}
+
+// CountSkillEndorsements returns the number of endorsements per skill ID
+func CountSkillEndorsements(db *gorm.DB, skillIDs []uint64) (map[uint64]int64, error) {
+	counts := make(map[uint64]int64, len(skillIDs))
+	if len(skillIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		SkillID uint64
+		Count   int64
+	}
+	err := db.Model(&model.SkillEndorsement{}).
+		Select("skill_id, COUNT(*) AS count").
+		Where("skill_id IN ?", skillIDs).
+		Group("skill_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.SkillID] = row.Count
+	}
+	return counts, nil
+}
+
+// Endorser is someone who endorsed a skill
+type Endorser struct {
+	SkillID    uint64
+	UserID     uint64
+	Name       string
+	Username   string
+	PhotoURL   string
+	EndorsedAt time.Time
+}
+
+// GetTopEndorsers returns up to perSkill endorsers of each skill. Endorsers
+// with the most followers come first, so well-known connections are shown.
+func GetTopEndorsers(db *gorm.DB, skillIDs []uint64, perSkill int) ([]Endorser, error) {
+	var endorsers []Endorser
+	if len(skillIDs) == 0 {
+		return endorsers, nil
+	}
+
+	err := db.Raw(`
+		SELECT skill_id, user_id, name, username, photo_url, endorsed_at
+		FROM (
+			SELECT e.skill_id, u.id AS user_id, u.name, u.username, u.photo_url, e.created_at AS endorsed_at,
+				ROW_NUMBER() OVER (
+					PARTITION BY e.skill_id
+					ORDER BY (SELECT COUNT(*) FROM follows f WHERE f.following_id = u.id) DESC, e.created_at ASC
+				) AS rank
+			FROM skill_endorsements e
+			JOIN users u ON u.id = e.endorser_id
+			WHERE e.skill_id IN ?
+		) ranked
+		WHERE rank <= ?
+		ORDER BY skill_id, rank
+	`, skillIDs, perSkill).Scan(&endorsers).Error
+	return endorsers, err
+}
+
+// GetEndorsedSkillIDs returns which of the skills the user has endorsed
+func GetEndorsedSkillIDs(db *gorm.DB, endorserID uint64, skillIDs []uint64) (map[uint64]bool, error) {
+	endorsed := make(map[uint64]bool)
+	if len(skillIDs) == 0 {
+		return endorsed, nil
+	}
+
+	var ids []uint64
+	err := db.Model(&model.SkillEndorsement{}).
+		Where("endorser_id = ? AND skill_id IN ?", endorserID, skillIDs).
+		Pluck("skill_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		endorsed[id] = true
+	}
+	return endorsed, nil
+}
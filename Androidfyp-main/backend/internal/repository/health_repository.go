@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// PingDB checks that a connection to the database can be used
+func PingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// GetSchemaVersion is the version AutoMigrate last recorded, 0 if it never ran
+func GetSchemaVersion(ctx context.Context, db *gorm.DB) (int, error) {
+	var migration model.SchemaMigration
+	err := db.WithContext(ctx).First(&migration, 1).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return migration.Version, err
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClaimIdempotencyKey inserts key unless a row for the same user, key and
+// route exists. Exactly one of several concurrent claims succeeds.
+func ClaimIdempotencyKey(db *gorm.DB, key *model.IdempotencyKey) (bool, error) {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	return result.RowsAffected == 1, result.Error
+}
+
+func GetIdempotencyKey(db *gorm.DB, userID uint64, key, route string) (*model.IdempotencyKey, error) {
+	var stored model.IdempotencyKey
+	err := db.Where("user_id = ? AND key = ? AND route = ?", userID, key, route).First(&stored).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stored, nil
+}
+
+// CompleteIdempotencyKey stores the response of the request that claimed the key
+func CompleteIdempotencyKey(db *gorm.DB, userID uint64, key, route string, status int, contentType string, body []byte) error {
+	return db.Model(&model.IdempotencyKey{}).
+		Where("user_id = ? AND key = ? AND route = ?", userID, key, route).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"content_type": contentType,
+			"body":         body,
+		}).Error
+}
+
+// ReleaseIdempotencyKey forgets a claim whose request did not finish, so a
+// retry runs the handler again
+func ReleaseIdempotencyKey(db *gorm.DB, userID uint64, key, route string) error {
+	return db.Where("user_id = ? AND key = ? AND route = ? AND status = 0", userID, key, route).
+		Delete(&model.IdempotencyKey{}).Error
+}
+
+// DeleteStaleIdempotencyKey removes one key if it has expired, or if its
+// request was claimed before claimCutoff and never finished, making room for
+// a new claim
+func DeleteStaleIdempotencyKey(db *gorm.DB, userID uint64, key, route string, now, claimCutoff time.Time) error {
+	return db.Where("user_id = ? AND key = ? AND route = ?", userID, key, route).
+		Where("expires_at <= ? OR (status = 0 AND created_at <= ?)", now, claimCutoff).
+		Delete(&model.IdempotencyKey{}).Error
+}
+
+// PurgeExpiredIdempotencyKeys deletes up to batchSize expired keys
+func PurgeExpiredIdempotencyKeys(db *gorm.DB, now time.Time, batchSize int) (int64, error) {
+	result := db.Exec(`DELETE FROM idempotency_keys WHERE ctid IN (
+		SELECT ctid FROM idempotency_keys WHERE expires_at <= ? LIMIT ?)`, now, batchSize)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// CreateJob stores a job together with the post that announces it in feeds
+func CreateJob(db *gorm.DB, job *model.Job, post *model.Post) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		job.PostID = &post.ID
+		return tx.Create(job).Error
+	})
+}
+
+func GetJobByID(db *gorm.DB, id uint64) (*model.Job, error) {
+	var job model.Job
+	err := db.Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	return &job, err
+}
+
+// GetJobByPostID finds the job behind a "job" feed post
+func GetJobByPostID(db *gorm.DB, postID uint64) (*model.Job, error) {
+	var job model.Job
+	err := db.Where("post_id = ?", postID).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrJobNotFound
+	}
+	return &job, err
+}
+
+// UpdateJob saves the editable fields of a job and refreshes its feed post
+func UpdateJob(db *gorm.DB, job *model.Job) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Job{}).
+			Where("id = ?", job.ID).
+			Updates(map[string]interface{}{
+				"title":             job.Title,
+				"company":           job.Company,
+				"location":          job.Location,
+				"remote_type":       job.RemoteType,
+				"seniority":         job.Seniority,
+				"job_position":      job.JobPosition,
+				"job_position_type": job.JobPositionType,
+				"description":       job.Description,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrJobNotFound
+		}
+
+		if job.PostID == nil {
+			return nil
+		}
+		return tx.Model(&model.Post{}).
+			Where("id = ?", *job.PostID).
+			Update("content", job.FeedText()).Error
+	})
+}
+
+// CloseJob stops a job taking applications; it returns ErrJobNotFound
+// if the job is not open
+func CloseJob(db *gorm.DB, jobID uint64, now time.Time) error {
+	result := db.Model(&model.Job{}).
+		Where("id = ? AND status = ?", jobID, model.JobStatusOpen).
+		Updates(map[string]interface{}{
+			"status":    model.JobStatusClosed,
+			"closed_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// detachJobPost unlinks a job from its deleted feed post; the job itself stays listed
+func detachJobPost(tx *gorm.DB, postID uint64) error {
+	return tx.Model(&model.Job{}).Where("post_id = ?", postID).Update("post_id", nil).Error
+}
+
+// JobFilter narrows the job listing; empty fields match everything
+type JobFilter struct {
+	Query           string // searched in title, company and description
+	Location        string // substring match
+	Company         string // substring match
+	RemoteType      string
+	Seniority       string
+	JobPosition     string
+	JobPositionType string
+	Status          string
+	RecruiterID     uint64
+	CompanyID       uint64
+}
+
+// likePattern escapes LIKE wildcards so user input is matched literally
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
+
+// SearchJobs returns one page of jobs matching the filter, newest first
+func SearchJobs(db *gorm.DB, filter JobFilter, cursor *Cursor, limit int) ([]model.Job, error) {
+	var jobs []model.Job
+	query := db.Model(&model.Job{})
+
+	if filter.Query != "" {
+		pattern := likePattern(filter.Query)
+		query = query.Where("title ILIKE ? OR company ILIKE ? OR description ILIKE ?", pattern, pattern, pattern)
+	}
+	if filter.Location != "" {
+		query = query.Where("location ILIKE ?", likePattern(filter.Location))
+	}
+	if filter.Company != "" {
+		query = query.Where("company ILIKE ?", likePattern(filter.Company))
+	}
+	if filter.RemoteType != "" {
+		query = query.Where("remote_type = ?", filter.RemoteType)
+	}
+	if filter.Seniority != "" {
+		query = query.Where("seniority = ?", filter.Seniority)
+	}
+	if filter.JobPosition != "" {
+		query = query.Where("job_position = ?", filter.JobPosition)
+	}
+	if filter.JobPositionType != "" {
+		query = query.Where("job_position_type = ?", filter.JobPositionType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.RecruiterID != 0 {
+		query = query.Where("recruiter_id = ?", filter.RecruiterID)
+	}
+	if filter.CompanyID != 0 {
+		query = query.Where("company_id = ?", filter.CompanyID)
+	}
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&jobs).Error
+	return jobs, err
+}
+
+// SaveJob saves a job for the user; saving twice is a no-op
+func SaveJob(db *gorm.DB, userID, jobID uint64) error {
+	saved := model.SavedJob{UserID: userID, JobID: jobID}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&saved).Error
+}
+
+func UnsaveJob(db *gorm.DB, userID, jobID uint64) error {
+	return db.Where("user_id = ? AND job_id = ?", userID, jobID).Delete(&model.SavedJob{}).Error
+}
+
+func IsJobSaved(db *gorm.DB, userID, jobID uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.SavedJob{}).Where("user_id = ? AND job_id = ?", userID, jobID).Count(&count).Error
+	return count > 0, err
+}
+
+// SavedJobEntry is a saved job with the time it was saved
+type SavedJobEntry struct {
+	model.Job
+	SavedAt time.Time
+}
+
+// GetSavedJobs returns one page of the user's saved jobs, most recently saved first
+func GetSavedJobs(db *gorm.DB, userID uint64, cursor *Cursor, limit int) ([]SavedJobEntry, error) {
+	var entries []SavedJobEntry
+	query := db.Table("saved_jobs s").
+		Select("j.*, s.created_at AS saved_at").
+		Joins("JOIN jobs j ON j.id = s.job_id").
+		Where("s.user_id = ?", userID)
+	if cursor != nil {
+		query = query.Where("(s.created_at, j.id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	err := query.Order("s.created_at DESC, j.id DESC").Limit(limit).Scan(&entries).Error
+	return entries, err
+}
@@ -67,6 +67,7 @@ func GetUsersWhoLikedPost(db *gorm.DB, postID uint64) ([]LikeUserInfo, error) {
 	err := db.Table("likes").
 		Select("likes.user_id, users.name as user_name, users.photo_url").
 		Joins("JOIN users ON likes.user_id = users.id").
+		Joins("JOIN posts ON posts.id = likes.post_id AND posts.deleted_at IS NULL").
 		Where("likes.post_id = ?", postID).
 		Find(&likes).Error
 	return likes, err
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrConversationNotFound  = errors.New("conversation not found")
+	ErrNotConversationMember = errors.New("not a member of this conversation")
+)
+
+// CreateConversation stores a conversation with its members
+func CreateConversation(db *gorm.DB, conversation *model.Conversation, members []model.ConversationMember) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+// GetDirectConversation finds the 1:1 conversation for a DirectConversationKey
+func GetDirectConversation(db *gorm.DB, key string) (*model.Conversation, error) {
+	var conversation model.Conversation
+	err := db.Where("direct_key = ?", key).First(&conversation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConversationNotFound
+	}
+	return &conversation, err
+}
+
+func GetConversationByID(db *gorm.DB, id uint64) (*model.Conversation, error) {
+	var conversation model.Conversation
+	err := db.Where("id = ?", id).First(&conversation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConversationNotFound
+	}
+	return &conversation, err
+}
+
+func GetConversationMember(db *gorm.DB, conversationID, userID uint64) (*model.ConversationMember, error) {
+	var member model.ConversationMember
+	err := db.Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotConversationMember
+	}
+	return &member, err
+}
+
+// ConversationMemberDetails is a member with the user's public info
+type ConversationMemberDetails struct {
+	model.ConversationMember
+	Name     string
+	Username string
+	PhotoURL string
+}
+
+// GetConversationMembers returns every member of a conversation, whatever their status
+func GetConversationMembers(db *gorm.DB, conversationID uint64) ([]ConversationMemberDetails, error) {
+	var members []ConversationMemberDetails
+	err := db.Table("conversation_members cm").
+		Select("cm.*, u.name, u.username, u.photo_url").
+		Joins("JOIN users u ON u.id = cm.user_id").
+		Where("cm.conversation_id = ?", conversationID).
+		Order("cm.joined_at ASC").
+		Scan(&members).Error
+	return members, err
+}
+
+// SetMemberStatus changes a member's status (accept/decline a request, leave)
+func SetMemberStatus(db *gorm.DB, conversationID, userID uint64, status string) error {
+	result := db.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotConversationMember
+	}
+	return nil
+}
+
+// CreateMessage stores a message, bumps the conversation and marks it read for the sender
+func CreateMessage(db *gorm.DB, message *model.Message) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Conversation{}).
+			Where("id = ?", message.ConversationID).
+			Update("last_message_at", message.CreatedAt).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&model.ConversationMember{}).
+			Where("conversation_id = ? AND user_id = ?", message.ConversationID, message.SenderID).
+			Updates(map[string]interface{}{
+				"last_read_message_id": message.ID,
+				"last_read_at":         message.CreatedAt,
+			}).Error
+	})
+}
+
+// GetMessages returns one page of a conversation's history, newest first
+func GetMessages(db *gorm.DB, conversationID uint64, cursor *Cursor, limit int) ([]model.Message, error) {
+	var messages []model.Message
+	query := db.Where("conversation_id = ?", conversationID)
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// MessageInConversation reports whether a message belongs to a conversation
+func MessageInConversation(db *gorm.DB, conversationID, messageID uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.Message{}).
+		Where("id = ? AND conversation_id = ?", messageID, conversationID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MarkConversationRead moves the member's read marker forward; it reports
+// false when the marker was already at or past messageID
+func MarkConversationRead(db *gorm.DB, conversationID, userID, messageID uint64, now time.Time) (bool, error) {
+	result := db.Model(&model.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND last_read_message_id < ?", conversationID, userID, messageID).
+		Updates(map[string]interface{}{
+			"last_read_message_id": messageID,
+			"last_read_at":         now,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ConversationSummary is a conversation as listed in a user's inbox
+type ConversationSummary struct {
+	model.Conversation
+	MemberStatus        string
+	LastReadMessageID   uint64
+	UnreadCount         int64
+	LastMessageID       *uint64
+	LastMessageSenderID *uint64
+	LastMessageContent  *string
+}
+
+// conversationSummaries selects the inbox rows of a user
+func conversationSummaries(db *gorm.DB, userID uint64) *gorm.DB {
+	return db.Table("conversation_members cm").
+		Select(`c.*,
+			cm.status AS member_status,
+			cm.last_read_message_id,
+			(SELECT COUNT(*) FROM messages m
+				WHERE m.conversation_id = c.id
+				AND m.id > cm.last_read_message_id
+				AND m.sender_id <> cm.user_id) AS unread_count,
+			lm.id AS last_message_id,
+			lm.sender_id AS last_message_sender_id,
+			lm.content AS last_message_content`).
+		Joins("JOIN conversations c ON c.id = cm.conversation_id").
+		Joins(`LEFT JOIN LATERAL (
+			SELECT id, sender_id, content FROM messages
+			WHERE conversation_id = c.id
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) lm ON true`).
+		Where("cm.user_id = ?", userID)
+}
+
+// GetUserConversations lists the user's conversations with the given member
+// status, most recently active first
+func GetUserConversations(db *gorm.DB, userID uint64, status string, cursor *Cursor, limit int) ([]ConversationSummary, error) {
+	var summaries []ConversationSummary
+	query := conversationSummaries(db, userID).Where("cm.status = ?", status)
+	if cursor != nil {
+		query = query.Where("(c.last_message_at, c.id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("c.last_message_at DESC, c.id DESC").Limit(limit).Scan(&summaries).Error
+	return summaries, err
+}
+
+// GetUserConversation returns one conversation as it appears in the user's inbox
+func GetUserConversation(db *gorm.DB, userID, conversationID uint64) (*ConversationSummary, error) {
+	var summaries []ConversationSummary
+	err := conversationSummaries(db, userID).Where("c.id = ?", conversationID).Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, ErrConversationNotFound
+	}
+	return &summaries[0], nil
+}
+
+// UnreadMessageCounts is what the app shows as message badges
+type UnreadMessageCounts struct {
+	UnreadConversations int64
+	UnreadMessages      int64
+	MessageRequests     int64
+}
+
+func GetUnreadMessageCounts(db *gorm.DB, userID uint64) (UnreadMessageCounts, error) {
+	var counts UnreadMessageCounts
+	err := db.Raw(`
+		SELECT
+			COUNT(DISTINCT m.conversation_id) FILTER (WHERE cm.status = ?) AS unread_conversations,
+			COUNT(m.id) FILTER (WHERE cm.status = ?) AS unread_messages,
+			(SELECT COUNT(*) FROM conversation_members
+				WHERE user_id = ? AND status = ?) AS message_requests
+		FROM conversation_members cm
+		LEFT JOIN messages m
+			ON m.conversation_id = cm.conversation_id
+			AND m.id > cm.last_read_message_id
+			AND m.sender_id <> cm.user_id
+		WHERE cm.user_id = ?
+	`, model.MemberStatusActive, model.MemberStatusActive,
+		userID, model.MemberStatusRequest, userID).Scan(&counts).Error
+	return counts, err
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"errors"
+	"time"
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrReportNotFound = errors.New("report not found")
+)
+
+// CreateReport stores a new report
+func CreateReport(db *gorm.DB, report *model.Report) error {
+	return db.Create(report).Error
+}
+
+// CreateReportOnce stores a report unless the reporter already has an open
+// report on the target
+func CreateReportOnce(db *gorm.DB, report *model.Report) error {
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(report).Error
+}
+
+// HasOpenReport checks if the reporter already has an open report on the target
+func HasOpenReport(db *gorm.DB, reporterID uint64, targetType string, targetID uint64) (bool, error) {
+	var count int64
+	err := db.Model(&model.Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status = ?",
+			reporterID, targetType, targetID, model.ReportStatusOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetReportByID retrieves a report by its ID
+func GetReportByID(db *gorm.DB, reportID uint64) (*model.Report, error) {
+	var report model.Report
+	err := db.Where("id = ?", reportID).First(&report).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	return &report, err
+}
+
+// ReportFilter narrows the moderation queue; empty fields match everything
+type ReportFilter struct {
+	Status     string
+	TargetType string
+}
+
+// GetReports returns one page of the moderation queue, newest first
+func GetReports(db *gorm.DB, filter ReportFilter, cursor *Cursor, limit int) ([]model.Report, error) {
+	var reports []model.Report
+	query := db.Model(&model.Report{})
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+// ResolveOpenReports closes every open report on a target with the given status
+func ResolveOpenReports(db *gorm.DB, targetType string, targetID uint64, status string, adminID uint64) error {
+	return db.Model(&model.Report{}).
+		Where("target_type = ? AND target_id = ? AND status = ?", targetType, targetID, model.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_by": adminID,
+			"resolved_at": gorm.Expr("NOW()"),
+		}).Error
+}
+
+// ResolveReport closes a single open report
+func ResolveReport(db *gorm.DB, reportID uint64, status string, adminID uint64) error {
+	result := db.Model(&model.Report{}).
+		Where("id = ? AND status = ?", reportID, model.ReportStatusOpen).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"resolved_by": adminID,
+			"resolved_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrReportNotFound
+	}
+	return nil
+}
+
+// CreateModerationAction appends an entry to the moderation audit log
+func CreateModerationAction(db *gorm.DB, action *model.ModerationAction) error {
+	return db.Create(action).Error
+}
+
+// ModerationActionFilter narrows the audit log; zero values match everything
+type ModerationActionFilter struct {
+	AdminID    uint64
+	TargetType string
+	TargetID   uint64
+}
+
+// GetModerationActions returns one page of the audit log, newest first
+func GetModerationActions(db *gorm.DB, filter ModerationActionFilter, cursor *Cursor, limit int) ([]model.ModerationAction, error) {
+	var actions []model.ModerationAction
+	query := db.Model(&model.ModerationAction{})
+
+	if filter.AdminID != 0 {
+		query = query.Where("admin_id = ?", filter.AdminID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&actions).Error
+	return actions, err
+}
+
+// CountRecentDuplicateContent counts the user's posts and comments created
+// since the given time whose whitespace-collapsed, lowercased text equals normalized
+func CountRecentDuplicateContent(db *gorm.DB, userID uint64, normalized string, since time.Time) (int64, error) {
+	var count int64
+	err := db.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM posts
+				WHERE user_id = ? AND created_at >= ?
+				AND lower(regexp_replace(btrim(content), '\s+', ' ', 'g')) = ?)
+			+
+			(SELECT COUNT(*) FROM comments
+				WHERE user_id = ? AND created_at >= ?
+				AND lower(regexp_replace(btrim(content), '\s+', ' ', 'g')) = ?)
+	`, userID, since, normalized, userID, since, normalized).Scan(&count).Error
+	return count, err
+}
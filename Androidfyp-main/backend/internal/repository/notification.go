@@ -8,9 +8,15 @@ import (
 
 // NotificationType constants
 const (
-	NotificationTypeFollow  = "follow"
-	NotificationTypeLike    = "like"
-	NotificationTypeComment = "comment"
+	NotificationTypeFollow        = "follow"
+	NotificationTypeLike          = "like"
+	NotificationTypeComment       = "comment"
+	NotificationTypeRepost        = "repost"
+	NotificationTypeQuote         = "quote"
+	NotificationTypePollClosed    = "poll_closed"
+	NotificationTypeApplication   = "application_stage"
+	NotificationTypeCompanyInvite = "company_invite"
+	NotificationTypeEndorsement   = "endorsement"
 )
 
 // CreateNotification creates a new notification
@@ -51,7 +57,7 @@ func GetUserNotificationsWithDetails(db *gorm.DB, userID uint64, limit int) ([]m
 			u.photo_url as from_user_photo
 		FROM notifications n
 		JOIN users u ON u.id = n.from_user_id
-		WHERE n.user_id = ?
+		WHERE n.user_id = ? AND n.deleted_at IS NULL
 		ORDER BY n.created_at DESC
 	`
 
@@ -81,7 +87,7 @@ func MarkAllNotificationsAsRead(db *gorm.DB, userID uint64) error {
 
 // DeleteNotification deletes a notification
 func DeleteNotification(db *gorm.DB, notificationID uint64) error {
-	return db.Delete(&model.Notification{}, notificationID).Error
+	return db.Unscoped().Delete(&model.Notification{}, notificationID).Error
 }
 
 // GetUnreadNotificationCount gets count of unread notifications
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOutboxEmailNotFound = errors.New("outbox email not found")
+
+func EnqueueEmail(db *gorm.DB, email *model.OutboxEmail) error {
+	return db.Create(email).Error
+}
+
+// ClaimDueEmails picks pending emails whose next attempt is due and pushes
+// that attempt lease into the future, so other replicas skip them while this
+// one sends. An email whose sender crashes is simply retried after the lease.
+func ClaimDueEmails(db *gorm.DB, now time.Time, lease time.Duration, batchSize int) ([]model.OutboxEmail, error) {
+	var emails []model.OutboxEmail
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxPending, now).
+			Order("next_attempt_at ASC").
+			Limit(batchSize).
+			Find(&emails).Error; err != nil {
+			return err
+		}
+		if len(emails) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, 0, len(emails))
+		for _, email := range emails {
+			ids = append(ids, email.ID)
+		}
+		return tx.Model(&model.OutboxEmail{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	return emails, err
+}
+
+// MarkEmailSent records a delivered email and drops its bodies, which may
+// hold verification and reset codes
+func MarkEmailSent(db *gorm.DB, id uint64, now time.Time) error {
+	return db.Model(&model.OutboxEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     model.OutboxSent,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"sent_at":    now,
+		"last_error": "",
+		"html_body":  "",
+		"text_body":  "",
+	}).Error
+}
+
+// MarkEmailFailed records a failed attempt and either schedules the next one
+// or, when nextAttemptAt is nil, dead-letters the email
+func MarkEmailFailed(db *gorm.DB, id uint64, attempts int, nextAttemptAt *time.Time, lastError string) error {
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": lastError,
+	}
+	if nextAttemptAt == nil {
+		updates["status"] = model.OutboxDead
+	} else {
+		updates["next_attempt_at"] = *nextAttemptAt
+	}
+	return db.Model(&model.OutboxEmail{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// GetDeadEmails lists dead-lettered emails, newest first
+func GetDeadEmails(db *gorm.DB, cursor *Cursor, limit int) ([]model.OutboxEmail, error) {
+	var emails []model.OutboxEmail
+	query := db.Where("status = ?", model.OutboxDead)
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&emails).Error
+	return emails, err
+}
+
+// RequeueEmail gives a dead email a fresh set of attempts
+func RequeueEmail(db *gorm.DB, id uint64, now time.Time) error {
+	result := db.Model(&model.OutboxEmail{}).
+		Where("id = ? AND status = ?", id, model.OutboxDead).
+		Updates(map[string]interface{}{
+			"status":          model.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOutboxEmailNotFound
+	}
+	return nil
+}
@@ -20,10 +20,10 @@ func SavePasswordResetCode(db *gorm.DB, email, code string, expiresAt time.Time)
 	return db.Save(reset).Error
 }
 
-// GetPasswordResetCode retrieves a password reset entry
-func GetPasswordResetCode(db *gorm.DB, email, code string) (*model.PasswordReset, error) {
+// GetPasswordReset retrieves the unexpired password reset entry of an email
+func GetPasswordReset(db *gorm.DB, email string) (*model.PasswordReset, error) {
 	var reset model.PasswordReset
-	err := db.Where("email = ? AND code = ? AND expires_at > ?", email, code, time.Now()).
+	err := db.Where("email = ? AND expires_at > ?", email, time.Now()).
 		First(&reset).Error
 	if err != nil {
 		return nil, err
@@ -31,16 +31,46 @@ func GetPasswordResetCode(db *gorm.DB, email, code string) (*model.PasswordReset
 	return &reset, nil
 }
 
+// ConsumePasswordResetAttempt counts one guess at the code. It returns false
+// once maxAttempts guesses were made, after which the code is dead.
+func ConsumePasswordResetAttempt(db *gorm.DB, email string, maxAttempts int) (bool, error) {
+	result := db.Model(&model.PasswordReset{}).
+		Where("email = ? AND attempts < ?", email, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected == 1, result.Error
+}
+
 // DeletePasswordResetCode removes the reset code after use
 func DeletePasswordResetCode(db *gorm.DB, email string) error {
 	return db.Where("email = ?", email).Delete(&model.PasswordReset{}).Error
 }
 
-// UpdateUserPassword updates a user's password
-func UpdateUserPassword(db *gorm.DB, email, hashedPassword string) error {
+// UpdateUserPassword updates a user's password and signs out every session
+func UpdateUserPassword(db *gorm.DB, email, hashedPassword string, changedAt time.Time) error {
 	result := db.Model(&model.User{}).
 		Where("email = ?", email).
-		Update("password", hashedPassword)
+		Updates(map[string]interface{}{
+			"password":            hashedPassword,
+			"password_changed_at": changedAt,
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateUserPasswordByID is UpdateUserPassword for a signed-in user
+func UpdateUserPasswordByID(db *gorm.DB, id uint64, hashedPassword string, changedAt time.Time) error {
+	result := db.Model(&model.User{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"password":            hashedPassword,
+			"password_changed_at": changedAt,
+		})
 
 	if result.Error != nil {
 		return result.Error
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPollNotFound      = errors.New("poll not found")
+	ErrPollClosed        = errors.New("poll is closed")
+	ErrAlreadyVoted      = errors.New("you have already voted in this poll")
+	ErrInvalidPollOption = errors.New("option does not belong to this poll")
+)
+
+// CreatePollPost stores a poll post together with its poll settings and options
+func CreatePollPost(db *gorm.DB, post *model.Post, poll *model.Poll, options []model.PollOption) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(poll).Error; err != nil {
+			return err
+		}
+		return tx.Create(&options).Error
+	})
+}
+
+// GetPoll retrieves the poll attached to a post
+func GetPoll(db *gorm.DB, postID uint64) (*model.Poll, error) {
+	var poll model.Poll
+	err := db.Where("post_id = ?", postID).First(&poll).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPollNotFound
+	}
+	return &poll, err
+}
+
+// PollOptionTally is a poll option with its current number of votes
+type PollOptionTally struct {
+	ID         uint64
+	Text       string
+	Position   int
+	VotesCount int64
+}
+
+// GetPollTallies returns every option of a poll with its live vote count
+func GetPollTallies(db *gorm.DB, postID uint64) ([]PollOptionTally, error) {
+	var tallies []PollOptionTally
+	err := db.Raw(`
+		SELECT
+			o.id,
+			o.text,
+			o.position,
+			COUNT(v.user_id) AS votes_count
+		FROM poll_options o
+		LEFT JOIN poll_votes v ON v.option_id = o.id
+		WHERE o.post_id = ?
+		GROUP BY o.id, o.text, o.position
+		ORDER BY o.position ASC
+	`, postID).Scan(&tallies).Error
+	return tallies, err
+}
+
+// CountPollVoters returns how many distinct users voted in a poll
+func CountPollVoters(db *gorm.DB, postID uint64) (int64, error) {
+	var count int64
+	err := db.Raw(`
+		SELECT COUNT(DISTINCT user_id) FROM poll_votes WHERE post_id = ?
+	`, postID).Scan(&count).Error
+	return count, err
+}
+
+// GetUserPollVotes returns the option IDs the user picked in a poll
+func GetUserPollVotes(db *gorm.DB, postID, userID uint64) ([]uint64, error) {
+	var optionIDs []uint64
+	err := db.Model(&model.PollVote{}).
+		Where("post_id = ? AND user_id = ?", postID, userID).
+		Pluck("option_id", &optionIDs).Error
+	return optionIDs, err
+}
+
+// CastPollVote records a user's ballot. The poll row is locked for the duration
+// so a user can never vote twice, even with concurrent requests.
+func CastPollVote(db *gorm.DB, postID, userID uint64, optionIDs []uint64, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var poll model.Poll
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("post_id = ?", postID).
+			First(&poll).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPollNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if poll.IsClosed(now) {
+			return ErrPollClosed
+		}
+
+		var existing int64
+		if err := tx.Model(&model.PollVote{}).
+			Where("post_id = ? AND user_id = ?", postID, userID).
+			Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrAlreadyVoted
+		}
+
+		var valid int64
+		if err := tx.Model(&model.PollOption{}).
+			Where("post_id = ? AND id IN ?", postID, optionIDs).
+			Count(&valid).Error; err != nil {
+			return err
+		}
+		if int(valid) != len(optionIDs) {
+			return ErrInvalidPollOption
+		}
+
+		votes := make([]model.PollVote, 0, len(optionIDs))
+		for _, optionID := range optionIDs {
+			votes = append(votes, model.PollVote{PostID: postID, UserID: userID, OptionID: optionID})
+		}
+		return tx.Create(&votes).Error
+	})
+}
+
+// DeletePollByPost removes a poll with its options and votes
+func DeletePollByPost(db *gorm.DB, postID uint64) error {
+	if err := db.Where("post_id = ?", postID).Delete(&model.PollVote{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("post_id = ?", postID).Delete(&model.PollOption{}).Error; err != nil {
+		return err
+	}
+	return db.Where("post_id = ?", postID).Delete(&model.Poll{}).Error
+}
+
+// NotifyClosedPolls tells authors that their poll has closed. Polls are claimed
+// with FOR UPDATE SKIP LOCKED so each author is notified exactly once even when
+// several replicas run the scheduler.
+func NotifyClosedPolls(db *gorm.DB, now time.Time, batchSize int) (int64, error) {
+	var notified int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		type closedPoll struct {
+			PostID  uint64
+			UserID  uint64
+			Content string
+		}
+
+		var polls []closedPoll
+		if err := tx.Table("polls").
+			Select("polls.post_id, posts.user_id, posts.content").
+			Joins("JOIN posts ON posts.id = polls.post_id AND posts.deleted_at IS NULL").
+			Where("polls.closed_notified = ? AND polls.expires_at <= ? AND posts.status = ?",
+				false, now, model.PostStatusPublished).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "polls"}, Options: "SKIP LOCKED"}).
+			Limit(batchSize).
+			Scan(&polls).Error; err != nil {
+			return err
+		}
+
+		for _, p := range polls {
+			question := p.Content
+			if utf8.RuneCountInString(question) > 100 {
+				// Cut on characters; bytes would split Arabic letters
+				question = string([]rune(question)[:97]) + "..."
+			}
+
+			postID := p.PostID
+			message := fmt.Sprintf("Your poll has closed: \"%s\"", question)
+			notification := &model.Notification{
+				UserID:     p.UserID, // recipient (poll author)
+				FromUserID: p.UserID, // the poll closing is not someone else's action
+				Type:       NotificationTypePollClosed,
+				PostID:     &postID,
+				Message:    &message,
+				IsRead:     false,
+			}
+			if err := CreateNotification(tx, notification); err != nil {
+				return err
+			}
+
+			if err := tx.Model(&model.Poll{}).
+				Where("post_id = ?", p.PostID).
+				Update("closed_notified", true).Error; err != nil {
+				return err
+			}
+			notified++
+		}
+		return nil
+	})
+
+	return notified, err
+}
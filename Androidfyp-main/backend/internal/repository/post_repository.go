@@ -2,31 +2,206 @@ package repository
 
 import (
 	"errors"
+	"time"
 	"wazzafak_back/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var ErrPostNotFound = errors.New("post not found")
+
 // =================== Create Post ===================
-func CreatePost(db *gorm.DB, userID uint64, photoURL string, content string) error {
-	post := model.NewPost_structure(userID, photoURL, content)
-	result := db.Create(&post)
+func CreatePost(db *gorm.DB, post *model.Post) error {
+	result := db.Create(post)
 	return result.Error
 }
 
 // =================== Delete Post ===================
+// DeletePost soft-deletes a post. Its pure reposts, comments and notifications
+// are stamped with the same deleted_at so RestorePost brings back exactly what
+// went with it, and quotes are flagged as having lost their original. Likes,
+// bookmarks and polls are only reachable through the post, so they stay until
+// the post is purged.
 func DeletePost(db *gorm.DB, postID uint64) error {
-	result := db.Delete(&model.Post{}, postID)
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+	deletedAt := time.Now().Truncate(time.Microsecond) // what Postgres stores, so it can be matched on restore
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.Post{}).Where("id = ?", postID).UpdateColumn("deleted_at", deletedAt)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		postIDs := []uint64{postID}
+		var repostIDs []uint64
+		if err := tx.Model(&model.Post{}).
+			Where("repost_of_id = ? AND kind = ?", postID, model.PostKindRepost).
+			Pluck("id", &repostIDs).Error; err != nil {
+			return err
+		}
+		if len(repostIDs) > 0 {
+			if err := tx.Model(&model.Post{}).Where("id IN ?", repostIDs).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+				return err
+			}
+			postIDs = append(postIDs, repostIDs...)
+		}
+
+		if err := tx.Model(&model.Post{}).
+			Where("repost_of_id = ? AND kind = ?", postID, model.PostKindQuote).
+			Update("original_deleted", true).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&model.Comment{}).Where("post_id IN ?", postIDs).UpdateColumn("deleted_at", deletedAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Notification{}).Where("post_id IN ?", postIDs).UpdateColumn("deleted_at", deletedAt).Error
+	})
+}
+
+// =================== Get a Deleted Post ===================
+func GetDeletedPost(db *gorm.DB, postID uint64) (*model.Post, error) {
+	var post model.Post
+	err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", postID).First(&post).Error
+	if err != nil {
+		return nil, err
 	}
-	return result.Error
+	return &post, nil
+}
+
+// =================== Restore a Deleted Post ===================
+// RestorePost undoes DeletePost. Only rows hidden together with the post
+// (same deleted_at) come back; comments deleted on their own stay deleted.
+func RestorePost(db *gorm.DB, post *model.Post) error {
+	deletedAt := post.DeletedAt.Time
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Model(&model.Post{}).
+			Where("id = ? AND deleted_at = ?", post.ID, deletedAt).
+			UpdateColumn("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound // purged in the meantime
+		}
+
+		postIDs := []uint64{post.ID}
+		var repostIDs []uint64
+		if err := tx.Unscoped().Model(&model.Post{}).
+			Where("repost_of_id = ? AND kind = ? AND deleted_at = ?", post.ID, model.PostKindRepost, deletedAt).
+			Pluck("id", &repostIDs).Error; err != nil {
+			return err
+		}
+		if len(repostIDs) > 0 {
+			if err := tx.Unscoped().Model(&model.Post{}).Where("id IN ?", repostIDs).UpdateColumn("deleted_at", nil).Error; err != nil {
+				return err
+			}
+			postIDs = append(postIDs, repostIDs...)
+		}
+
+		if err := tx.Unscoped().Model(&model.Post{}).
+			Where("repost_of_id = ? AND kind = ?", post.ID, model.PostKindQuote).
+			Update("original_deleted", false).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&model.Comment{}).
+			Where("post_id IN ? AND deleted_at = ?", postIDs, deletedAt).
+			UpdateColumn("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&model.Notification{}).
+			Where("post_id IN ? AND deleted_at = ?", postIDs, deletedAt).
+			UpdateColumn("deleted_at", nil).Error
+	})
+}
+
+// =================== Purge a Post ===================
+// PurgePost hard-deletes a post right away, e.g. when a moderator removes it
+func PurgePost(db *gorm.DB, postID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Delete(&model.Post{}, postID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return purgePostDependents(tx, postID)
+	})
+}
+
+// PurgeDeletedPosts hard-deletes up to batchSize posts soft-deleted before
+// cutoff. Rows are claimed with FOR UPDATE SKIP LOCKED like PublishDuePosts.
+func PurgeDeletedPosts(db *gorm.DB, cutoff time.Time, batchSize int) (int64, error) {
+	var purged int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint64
+		if err := tx.Unscoped().Model(&model.Post{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("deleted_at < ?", cutoff).
+			Order("deleted_at ASC").
+			Limit(batchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			result := tx.Unscoped().Delete(&model.Post{}, id)
+			if result.Error != nil {
+				return result.Error
+			}
+			if err := purgePostDependents(tx, id); err != nil {
+				return err
+			}
+			purged += result.RowsAffected
+		}
+		return nil
+	})
+
+	return purged, err
+}
+
+// purgePostDependents removes everything that points at a post that is gone.
+// Pure reposts go with it; quotes keep their own content.
+func purgePostDependents(tx *gorm.DB, postID uint64) error {
+	if err := tx.Where("post_id = ?", postID).Delete(&model.Like{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("post_id = ?", postID).Delete(&model.Comment{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Unscoped().Where("post_id = ?", postID).Delete(&model.Notification{}).Error; err != nil {
+		return err
+	}
+
+	// Bookmarks are private to their owners, so nobody else can clean them up
+	if err := DeleteBookmarksByPost(tx, postID); err != nil {
+		return err
+	}
+
+	if err := DeletePollByPost(tx, postID); err != nil {
+		return err
+	}
+
+	if err := detachJobPost(tx, postID); err != nil {
+		return err
+	}
+
+	return detachReposts(tx, postID)
 }
 
 // =================== Get All Posts ===================
 func GetAllPosts(db *gorm.DB) ([]model.Post, error) {
 	var posts []model.Post
-	result := db.Order("created_at DESC").Find(&posts)
+	result := db.Where("status = ?", model.PostStatusPublished).
+		Order("created_at DESC").
+		Find(&posts)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -39,7 +214,7 @@ func GetUserFeed(db *gorm.DB, userID uint64) ([]model.Post, error) {
 	result := db.Table("posts").
 		Select("posts.*").
 		Joins("INNER JOIN follows ON posts.user_id = follows.following_id").
-		Where("follows.follower_id = ?", userID).
+		Where("follows.follower_id = ? AND posts.status = ?", userID, model.PostStatusPublished).
 		Order("posts.created_at DESC").
 		Find(&posts)
 	if result.Error != nil {
@@ -48,12 +223,51 @@ func GetUserFeed(db *gorm.DB, userID uint64) ([]model.Post, error) {
 	return posts, nil
 }
 
+// =================== Hide / Unhide a Post (moderation) ===================
+func SetPostHidden(db *gorm.DB, postID uint64, hidden bool) error {
+	from, to := model.PostStatusPublished, model.PostStatusHidden
+	if !hidden {
+		from, to = model.PostStatusHidden, model.PostStatusPublished
+	}
+
+	result := db.Model(&model.Post{}).
+		Where("id = ? AND status = ?", postID, from).
+		Update("status", to)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// =================== Hold a Post for Review ===================
+// HoldPost hides a draft or scheduled post that the content filter flagged
+// when it was about to go out
+func HoldPost(db *gorm.DB, postID uint64) error {
+	result := db.Model(&model.Post{}).
+		Where("id = ? AND status IN ?", postID, model.UnpublishedPostStatuses).
+		Updates(map[string]interface{}{
+			"status":     model.PostStatusHidden,
+			"publish_at": nil,
+			"created_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // =================== Get Post by ID ===================
 func GetPostByID(db *gorm.DB, id uint64) (*model.Post, error) {
 	var post model.Post
 	result := db.Where("id = ?", id).First(&post)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, errors.New("post not found")
+		return nil, ErrPostNotFound
 	}
 	return &post, result.Error
 }
@@ -61,7 +275,7 @@ func GetPostByID(db *gorm.DB, id uint64) (*model.Post, error) {
 // =================== Get Posts by User ===================
 func GetPostsByUserID(db *gorm.DB, userID uint64) ([]model.Post, error) {
 	var posts []model.Post
-	result := db.Where("user_id = ?", userID).
+	result := db.Where("user_id = ? AND status = ?", userID, model.PostStatusPublished).
 		Order("created_at DESC").
 		Find(&posts)
 	if result.Error != nil {
@@ -70,6 +284,90 @@ func GetPostsByUserID(db *gorm.DB, userID uint64) ([]model.Post, error) {
 	return posts, nil
 }
 
+// =================== Get Drafts & Scheduled Posts by User ===================
+func GetUnpublishedPostsByUserID(db *gorm.DB, userID uint64) ([]model.Post, error) {
+	var posts []model.Post
+	result := db.Where("user_id = ? AND status IN ?", userID, model.UnpublishedPostStatuses).
+		Order("updated_at DESC").
+		Find(&posts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return posts, nil
+}
+
+// =================== Update an Unpublished Post ===================
+func UpdateUnpublishedPost(db *gorm.DB, post *model.Post) error {
+	result := db.Model(&model.Post{}).
+		Where("id = ? AND status IN ?", post.ID, model.UnpublishedPostStatuses).
+		Updates(map[string]interface{}{
+			"photo_url":  post.PhotoURL,
+			"content":    post.Content,
+			"status":     post.Status,
+			"publish_at": post.PublishAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// =================== Publish a Post Now ===================
+func PublishPost(db *gorm.DB, postID uint64) error {
+	result := db.Model(&model.Post{}).
+		Where("id = ? AND status IN ?", postID, model.UnpublishedPostStatuses).
+		Updates(map[string]interface{}{
+			"status":     model.PostStatusPublished,
+			"publish_at": nil,
+			"created_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// =================== Publish Due Scheduled Posts ===================
+// PublishDuePosts flips up to batchSize scheduled posts whose publish_at has
+// passed to published. Rows are claimed with FOR UPDATE SKIP LOCKED so that
+// several backend replicas running the scheduler never publish the same post twice.
+func PublishDuePosts(db *gorm.DB, now time.Time, batchSize int) (int64, error) {
+	var published int64
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint64
+		if err := tx.Model(&model.Post{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND publish_at <= ?", model.PostStatusScheduled, now).
+			Order("publish_at ASC").
+			Limit(batchSize).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		// The post shows up in feeds at its scheduled time, not its draft time
+		result := tx.Model(&model.Post{}).
+			Where("id IN ? AND status = ?", ids, model.PostStatusScheduled).
+			Updates(map[string]interface{}{
+				"status":     model.PostStatusPublished,
+				"created_at": gorm.Expr("publish_at"),
+			})
+		published = result.RowsAffected
+		return result.Error
+	})
+
+	return published, err
+}
+
 // =================== Count Likes ===================
 func GetLikesCount(db *gorm.DB, postID uint64) (int, error) {
 	var count int64
@@ -83,7 +381,7 @@ func GetLikesCount(db *gorm.DB, postID uint64) (int, error) {
 func GetCommentsCount(db *gorm.DB, postID uint64) (int, error) {
 	var count int64
 	err := db.Table("comments").
-		Where("post_id = ?", postID).
+		Where("post_id = ? AND hidden = ? AND deleted_at IS NULL", postID, false).
 		Count(&count).Error
 	return int(count), err
 }
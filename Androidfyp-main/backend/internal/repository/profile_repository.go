@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"errors"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrProfileEntryNotFound = errors.New("profile entry not found")
+	ErrSkillExists          = errors.New("skill already on profile")
+	ErrSkillNotFound        = errors.New("skill not found")
+)
+
+// UpdateUserAbout sets the headline and bio of the profile
+func UpdateUserAbout(db *gorm.DB, userID uint64, headline, bio string) error {
+	return db.Model(&model.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"headline": headline,
+		"bio":      bio,
+	}).Error
+}
+
+// =================== Skills ===================
+
+func GetUserSkills(db *gorm.DB, userID uint64) ([]model.UserSkill, error) {
+	var skills []model.UserSkill
+	err := db.Where("user_id = ?", userID).Order("position ASC, created_at ASC").Find(&skills).Error
+	return skills, err
+}
+
+func GetUserSkill(db *gorm.DB, userID uint64, normalizedName string) (*model.UserSkill, error) {
+	var skill model.UserSkill
+	if err := db.First(&skill, "user_id = ? AND normalized_name = ?", userID, normalizedName).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSkillNotFound
+		}
+		return nil, err
+	}
+	return &skill, nil
+}
+
+func CountUserSkills(db *gorm.DB, userID uint64) (int64, error) {
+	var count int64
+	err := db.Model(&model.UserSkill{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// AddUserSkill appends a skill to the profile
+func AddUserSkill(db *gorm.DB, skill *model.UserSkill) error {
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(skill)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSkillExists
+	}
+	return nil
+}
+
+// ReplaceUserSkills makes the profile list exactly these skills, in order.
+// Skills that stay keep their row, so whatever hangs off them is kept too.
+func ReplaceUserSkills(db *gorm.DB, userID uint64, skills []model.UserSkill) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		keep := make([]string, 0, len(skills))
+		for _, skill := range skills {
+			keep = append(keep, skill.NormalizedName)
+		}
+
+		var removed []uint64
+		query := tx.Model(&model.UserSkill{}).Where("user_id = ?", userID)
+		if len(keep) > 0 {
+			query = query.Where("normalized_name NOT IN ?", keep)
+		}
+		if err := query.Pluck("id", &removed).Error; err != nil {
+			return err
+		}
+		if err := deleteSkills(tx, removed); err != nil {
+			return err
+		}
+
+		if len(skills) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "normalized_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "position"}),
+		}).Create(&skills).Error
+	})
+}
+
+// DeleteUserSkill removes the skill and its endorsements
+func DeleteUserSkill(db *gorm.DB, userID uint64, normalizedName string) error {
+	skill, err := GetUserSkill(db, userID, normalizedName)
+	if err != nil {
+		return err
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return deleteSkills(tx, []uint64{skill.ID})
+	})
+}
+
+// deleteSkills removes skills by ID together with their endorsements
+func deleteSkills(tx *gorm.DB, skillIDs []uint64) error {
+	if len(skillIDs) == 0 {
+		return nil
+	}
+	if err := tx.Where("skill_id IN ?", skillIDs).Delete(&model.SkillEndorsement{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("id IN ?", skillIDs).Delete(&model.UserSkill{}).Error
+}
+
+// =================== Experience, education and links ===================
+
+func GetExperiences(db *gorm.DB, userID uint64) ([]model.Experience, error) {
+	var entries []model.Experience
+	// Current jobs first, then by most recent start
+	err := db.Where("user_id = ?", userID).Order("end_date DESC NULLS FIRST, start_date DESC").Find(&entries).Error
+	return entries, err
+}
+
+func GetEducations(db *gorm.DB, userID uint64) ([]model.Education, error) {
+	var entries []model.Education
+	err := db.Where("user_id = ?", userID).Order("end_date DESC NULLS FIRST, start_date DESC").Find(&entries).Error
+	return entries, err
+}
+
+func GetProfileLinks(db *gorm.DB, userID uint64) ([]model.ProfileLink, error) {
+	var links []model.ProfileLink
+	err := db.Where("user_id = ?", userID).Order("created_at ASC").Find(&links).Error
+	return links, err
+}
+
+// CountProfileEntries counts the user's rows of one section, e.g. &model.Experience{}
+func CountProfileEntries(db *gorm.DB, section interface{}, userID uint64) (int64, error) {
+	var count int64
+	err := db.Model(section).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// CreateProfileEntry saves a new experience, education or link entry
+func CreateProfileEntry(db *gorm.DB, entry interface{}) error {
+	return db.Create(entry).Error
+}
+
+// UpdateProfileEntry saves the given fields of an entry owned by the user.
+// entry must have its ID set.
+func UpdateProfileEntry(db *gorm.DB, entry interface{}, userID uint64, fields ...string) error {
+	result := db.Model(entry).Where("user_id = ?", userID).Select(fields).Updates(entry)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProfileEntryNotFound
+	}
+	return nil
+}
+
+// DeleteProfileEntry removes an entry of one section owned by the user
+func DeleteProfileEntry(db *gorm.DB, section interface{}, userID, entryID uint64) error {
+	result := db.Where("id = ? AND user_id = ?", entryID, userID).Delete(section)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrProfileEntryNotFound
+	}
+	return nil
+}
+
+// =================== Search ===================
+
+// UserSearchFilter narrows a people search; every set field must match
+type UserSearchFilter struct {
+	Query   string // name, username, headline or bio
+	Skill   string // normalized skill name, exact match
+	Company string // a past or current employer, substring match
+	School  string // substring match
+}
+
+// SearchUsers returns one page of users matching the filter, newest accounts first
+func SearchUsers(db *gorm.DB, filter UserSearchFilter, cursor *Cursor, limit int) ([]model.User, error) {
+	var users []model.User
+	// Purged accounts stay as anonymized rows for their messages; they aren't people to find
+	query := db.Model(&model.User{}).Where("account_deleted_at IS NULL")
+
+	if filter.Query != "" {
+		pattern := likePattern(filter.Query)
+		query = query.Where("name ILIKE ? OR username ILIKE ? OR headline ILIKE ? OR bio ILIKE ?",
+			pattern, pattern, pattern, pattern)
+	}
+	if filter.Skill != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM user_skills s WHERE s.user_id = users.id AND s.normalized_name = ?)", filter.Skill)
+	}
+	if filter.Company != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM experiences e WHERE e.user_id = users.id AND e.company ILIKE ?)", likePattern(filter.Company))
+	}
+	if filter.School != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM educations e WHERE e.user_id = users.id AND e.school ILIKE ?)", likePattern(filter.School))
+	}
+	if cursor != nil {
+		query = query.Where("(created_at, id) < (?, ?)", cursor.CreatedAt, cursor.ID)
+	}
+
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"fmt"
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// CreateRepost stores a repost/quote post and notifies the original author
+func CreateRepost(db *gorm.DB, post *model.Post) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+
+		// Get original post owner
+		var original model.Post
+		if err := tx.Select("user_id").Where("id = ?", *post.RepostOfID).First(&original).Error; err != nil {
+			return err
+		}
+
+		// Don't notify users who share their own post, or about a quote held for review
+		if original.UserID == post.UserID || post.Status != model.PostStatusPublished {
+			return nil
+		}
+
+		var reposter model.User
+		if err := tx.Select("name").Where("id = ?", post.UserID).First(&reposter).Error; err != nil {
+			return err
+		}
+
+		notifType := NotificationTypeRepost
+		message := fmt.Sprintf("%s reposted your post", reposter.Name)
+		if post.Kind == model.PostKindQuote {
+			notifType = NotificationTypeQuote
+			message = fmt.Sprintf("%s quoted your post", reposter.Name)
+		}
+
+		notification := &model.Notification{
+			UserID:     original.UserID, // recipient (original author)
+			FromUserID: post.UserID,     // actor (the one sharing)
+			Type:       notifType,
+			PostID:     post.RepostOfID,
+			Message:    &message,
+			IsRead:     false,
+		}
+
+		return CreateNotification(tx, notification)
+	})
+}
+
+// GetUserRepost finds the user's pure repost of a post, if any
+func GetUserRepost(db *gorm.DB, userID, originalID uint64) (*model.Post, error) {
+	var post model.Post
+	err := db.Where("user_id = ? AND repost_of_id = ? AND kind = ?", userID, originalID, model.PostKindRepost).
+		First(&post).Error
+	if err != nil {
+		return nil, err
+	}
+	return &post, nil
+}
+
+// HasUserReposted checks whether the user has a pure repost of the post
+func HasUserReposted(db *gorm.DB, userID, postID uint64) (bool, error) {
+	var exists bool
+	err := db.Raw(`
+		SELECT EXISTS(SELECT 1 FROM posts WHERE user_id = ? AND repost_of_id = ? AND kind = ? AND deleted_at IS NULL)
+	`, userID, postID, model.PostKindRepost).Scan(&exists).Error
+	return exists, err
+}
+
+// =================== Count Reposts (reposts + quotes) ===================
+// Held and hidden quotes aren't counted, so the count matches what followers see
+func GetRepostsCount(db *gorm.DB, postID uint64) (int, error) {
+	var count int64
+	err := db.Model(&model.Post{}).
+		Where("repost_of_id = ? AND status = ?", postID, model.PostStatusPublished).
+		Count(&count).Error
+	return int(count), err
+}
+
+// detachReposts runs when an original post is purged: pure reposts are
+// purged with it and quotes are flagged so clients can show "original deleted"
+func detachReposts(tx *gorm.DB, originalID uint64) error {
+	var repostIDs []uint64
+	if err := tx.Unscoped().Model(&model.Post{}).
+		Where("repost_of_id = ? AND kind = ?", originalID, model.PostKindRepost).
+		Pluck("id", &repostIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range repostIDs {
+		if err := tx.Unscoped().Delete(&model.Post{}, id).Error; err != nil {
+			return err
+		}
+		if err := purgePostDependents(tx, id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Model(&model.Post{}).
+		Where("repost_of_id = ? AND kind = ?", originalID, model.PostKindQuote).
+		Update("original_deleted", true).Error
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"wazzafak_back/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrTwoFactorNotFound = errors.New("two-factor setup not found")
+
+func GetTwoFactor(db *gorm.DB, userID uint64) (*model.TwoFactor, error) {
+	var twoFactor model.TwoFactor
+	if err := db.Where("user_id = ?", userID).First(&twoFactor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotFound
+		}
+		return nil, err
+	}
+	return &twoFactor, nil
+}
+
+// SaveTwoFactorSecret starts (or restarts) enrollment with a new secret.
+// An already enabled setup is left alone.
+func SaveTwoFactorSecret(db *gorm.DB, userID uint64, secret string) error {
+	twoFactor := model.TwoFactor{UserID: userID, Secret: secret}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"secret": secret, "last_used_step": 0}),
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "two_factors.enabled_at IS NULL"}}},
+	}).Create(&twoFactor).Error
+}
+
+// EnableTwoFactor turns 2FA on after the first good code and stores the
+// recovery codes in the same transaction
+func EnableTwoFactor(db *gorm.DB, userID uint64, step int64, codeHashes []string, now time.Time) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.TwoFactor{}).
+			Where("user_id = ? AND enabled_at IS NULL", userID).
+			Updates(map[string]interface{}{"enabled_at": now, "last_used_step": step})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTwoFactorNotFound
+		}
+		return ReplaceRecoveryCodes(tx, userID, codeHashes)
+	})
+}
+
+// UseTOTPStep records that the code for step was used. It fails when that
+// step or a later one was already used, which stops replays.
+func UseTOTPStep(db *gorm.DB, userID uint64, step int64) (bool, error) {
+	result := db.Model(&model.TwoFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ConsumeTwoFactorAttempt counts a code guess before it is checked, so
+// parallel guesses can't get past the limit. The guess that reaches
+// maxAttempts locks the account's second factor until lockedUntil. It
+// reports false while the account is locked.
+func ConsumeTwoFactorAttempt(db *gorm.DB, userID uint64, maxAttempts int, lockedUntil, now time.Time) (bool, error) {
+	result := db.Exec(`
+		UPDATE two_factors SET
+			failed_attempts = CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END,
+			locked_until = CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE NULL END
+		WHERE user_id = ? AND (locked_until IS NULL OR locked_until <= ?)
+	`, maxAttempts, maxAttempts, lockedUntil, userID, now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ResetTwoFactorAttempts forgets the wrong guesses after a successful login
+func ResetTwoFactorAttempts(db *gorm.DB, userID uint64) error {
+	return db.Model(&model.TwoFactor{}).
+		Where("user_id = ?", userID).
+		Update("failed_attempts", 0).Error
+}
+
+// UseRecoveryCode spends one unused recovery code
+func UseRecoveryCode(db *gorm.DB, userID uint64, codeHash string, now time.Time) (bool, error) {
+	result := db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	return result.RowsAffected == 1, result.Error
+}
+
+// ReplaceRecoveryCodes drops the user's old recovery codes and stores new ones
+func ReplaceRecoveryCodes(db *gorm.DB, userID uint64, codeHashes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]model.RecoveryCode, 0, len(codeHashes))
+		for _, hash := range codeHashes {
+			codes = append(codes, model.NewRecoveryCode(userID, hash))
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func CountUnusedRecoveryCodes(db *gorm.DB, userID uint64) (int64, error) {
+	var count int64
+	err := db.Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteTwoFactor turns 2FA off and removes the recovery codes
+func DeleteTwoFactor(db *gorm.DB, userID uint64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("user_id = ?", userID).Delete(&model.TwoFactor{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTwoFactorNotFound
+		}
+		return tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+	})
+}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"errors"
+	"time"
 	"wazzafak_back/internal/model"
 
 	"gorm.io/gorm"
 )
 
+var ErrUserNotFound = errors.New("user not found")
+
 // CreateUser inserts a new user into the database
 func CreateUser_indatabase(db *gorm.DB, user *model.User) error {
 	result := db.Create(&user)
@@ -30,7 +33,7 @@ func UpdateUserPhoto(db *gorm.DB, id uint64, photoURL string) error {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 	return nil
 }
@@ -42,7 +45,7 @@ func UpdateUserName(db *gorm.DB, id uint64, name string) error {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 	return nil
 }
@@ -52,7 +55,7 @@ func GetUserByEmail(db *gorm.DB, email string) (*model.User, error) {
 	var user model.User
 	result := db.Where("email = ?", email).First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	return &user, result.Error
 }
@@ -62,7 +65,7 @@ func GetUserByID(db *gorm.DB, id uint64) (*model.User, error) {
 	var user model.User
 	result := db.Where("id = ?", id).First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	return &user, result.Error
 }
@@ -72,7 +75,44 @@ func GetUserByUsername(db *gorm.DB, username string) (*model.User, error) {
 	var user model.User
 	result := db.Where("username = ?", username).First(&user)
 	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user not found")
+		return nil, ErrUserNotFound
 	}
 	return &user, result.Error
 }
+
+// SuspendUser marks a user as suspended until the given time (nil = indefinitely)
+func SuspendUser(db *gorm.DB, id uint64, until *time.Time, reason string) error {
+	result := db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"suspended_at":    gorm.Expr("NOW()"),
+		"suspended_until": until,
+		"suspend_reason":  reason,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// UnsuspendUser lifts a user's suspension
+func UnsuspendUser(db *gorm.DB, id uint64) error {
+	result := db.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"suspended_at":    nil,
+		"suspended_until": nil,
+		"suspend_reason":  "",
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// SetRecruiter grants or revokes the right to post jobs
+func SetRecruiter(db *gorm.DB, id uint64, isRecruiter bool) error {
+	return db.Model(&model.User{}).Where("id = ?", id).Update("is_recruiter", isRecruiter).Error
+}
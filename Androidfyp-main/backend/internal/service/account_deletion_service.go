@@ -0,0 +1,253 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/realtime"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// accountDeletionGrace is how long a deletion can still be cancelled
+const accountDeletionGrace = 14 * 24 * time.Hour
+
+var (
+	ErrDeletionPending   = apperr.Conflict("account deletion already requested")
+	ErrNoDeletionPending = apperr.NotFound("no account deletion pending")
+)
+
+// RequestAccountDeletion re-authenticates the user and schedules the account
+// to be purged once the grace period is over. Accounts with 2FA must also
+// pass an authenticator or recovery code.
+func RequestAccountDeletion(ctx context.Context, userID uint64, password, code string) (time.Time, error) {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return time.Time{}, ErrUserNotFound
+	}
+	if !checkPassword(password, user.Password) {
+		return time.Time{}, ErrWrongPassword
+	}
+	if user.DeleteAfter != nil {
+		return time.Time{}, ErrDeletionPending
+	}
+
+	twoFactor, err := enabledTwoFactor(userID)
+	if err != nil && !errors.Is(err, ErrTwoFactorNotEnabled) {
+		return time.Time{}, err
+	}
+	if twoFactor != nil {
+		if err := verifySecondFactor(twoFactor, code); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	deleteAfter := time.Now().Add(accountDeletionGrace)
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.ScheduleAccountDeletion(tx, userID, deleteAfter); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, user.Email, mailer.TemplateAccountDeletion, struct{ DeleteAfter string }{
+			DeleteAfter: deleteAfter.Format("January 2, 2006"),
+		})
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	wakeMailOutbox()
+	recordAuditEvent(ctx, userID, userID, model.AuditDeletionRequested, map[string]string{"delete_after": deleteAfter.Format(time.RFC3339)})
+	return deleteAfter, nil
+}
+
+// CancelAccountDeletion keeps the account during the grace period
+func CancelAccountDeletion(ctx context.Context, userID uint64) error {
+	err := repository.CancelAccountDeletion(db.DB, userID)
+	if errors.Is(err, repository.ErrNoDeletionPending) {
+		return ErrNoDeletionPending
+	}
+	if err != nil {
+		return err
+	}
+	recordAuditEvent(ctx, userID, userID, model.AuditDeletionCancelled, nil)
+	return nil
+}
+
+// RunAccountDeletion purges accounts whose grace period is over. Each account
+// is purged in one transaction and claimed with a row lock, so it is safe on
+// every replica. Blocks until ctx is cancelled.
+func RunAccountDeletion(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeDueAccounts(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeDueAccounts(ctx context.Context) {
+	for ctx.Err() == nil {
+		userID, found, err := repository.PurgeDueAccount(db.DB, time.Now())
+		if err != nil {
+			slog.Error("account deletion failed", "error", err)
+			return
+		}
+		if !found {
+			return
+		}
+		realtime.Default.DisconnectUser(userID)
+		slog.Info("account deletion: purged user", "user_id", userID)
+	}
+}
+
+// ExportAccountData builds a zip archive with one JSON file per kind of data
+// the social backend stores about the user, plus the uploaded files under
+// media/. media.json lists every file with its URL and where it is in the
+// archive, or why it couldn't be included.
+func ExportAccountData(ctx context.Context, userID uint64) ([]byte, error) {
+	data, err := repository.GetAccountData(db.DB, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"account.json", accountExport{
+			ID:              data.User.ID,
+			Name:            data.User.Name,
+			Username:        data.User.Username,
+			Email:           data.User.Email,
+			PhotoURL:        data.User.PhotoURL,
+			JobPosition:     data.User.JobPosition,
+			JobPositionType: data.User.JobPositionType,
+			Headline:        data.User.Headline,
+			Bio:             data.User.Bio,
+			IsRecruiter:     data.User.IsRecruiter,
+			CreatedAt:       data.User.CreatedAt,
+			DeleteAfter:     data.User.DeleteAfter,
+		}},
+		{"profile/skills.json", data.Skills},
+		{"profile/experience.json", data.Experiences},
+		{"profile/education.json", data.Educations},
+		{"profile/links.json", data.Links},
+		{"profile/endorsements_given.json", data.EndorsementsGiven},
+		{"posts.json", data.Posts},
+		{"comments.json", data.Comments},
+		{"likes.json", data.Likes},
+		{"poll_votes.json", data.PollVotes},
+		{"network/following.json", data.Following},
+		{"network/followers.json", data.Followers},
+		{"network/blocks.json", data.Blocks},
+		{"notifications.json", data.Notifications},
+		{"bookmarks/bookmarks.json", data.Bookmarks},
+		{"bookmarks/collections.json", data.Collections},
+		{"jobs/saved_jobs.json", data.SavedJobs},
+		{"jobs/applications.json", data.Applications},
+		{"jobs/posted_jobs.json", data.Jobs},
+		{"jobs/companies.json", data.Companies},
+		{"messages/conversations.json", data.Conversations},
+		{"messages/sent_messages.json", data.Messages},
+		{"reports.json", data.Reports},
+		{"account/username_history.json", data.UsernameHistory},
+		{"account/pending_email_change.json", data.PendingEmailChange},
+		{"account/security_events.json", data.SecurityEvents},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return nil, err
+		}
+	}
+
+	media := collectMediaURLs(data)
+	if err := addMediaFiles(ctx, archive, media); err != nil {
+		return nil, err
+	}
+	w, err := archive.Create("media.json")
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(media); err != nil {
+		return nil, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// accountExport is the user row without credentials and moderation fields
+type accountExport struct {
+	ID              uint64     `json:"id,string"`
+	Name            string     `json:"name"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	PhotoURL        string     `json:"photo_url"`
+	JobPosition     string     `json:"job_position"`
+	JobPositionType string     `json:"job_position_type"`
+	Headline        string     `json:"headline"`
+	Bio             string     `json:"bio"`
+	IsRecruiter     bool       `json:"is_recruiter"`
+	CreatedAt       time.Time  `json:"created_at"`
+	DeleteAfter     *time.Time `json:"delete_after,omitempty"`
+}
+
+// MediaFile points at an uploaded file referenced by the export
+type MediaFile struct {
+	Kind     string `json:"kind"`
+	SourceID uint64 `json:"source_id,string"`
+	URL      string `json:"url"`
+	File     string `json:"file,omitempty"`  // path in the archive
+	Error    string `json:"error,omitempty"` // why the file isn't in the archive
+}
+
+func collectMediaURLs(data *repository.AccountData) []MediaFile {
+	media := []MediaFile{}
+	add := func(kind string, sourceID uint64, url string) {
+		if url != "" {
+			media = append(media, MediaFile{Kind: kind, SourceID: sourceID, URL: url})
+		}
+	}
+
+	if data.User.PhotoURL != model.DefaultPhotoURL {
+		add("profile_photo", data.User.ID, data.User.PhotoURL)
+	}
+	for _, post := range data.Posts {
+		add("post_photo", post.ID, post.PhotoURL)
+	}
+	for _, application := range data.Applications {
+		add("cv", application.ID, application.CVURL)
+	}
+	return media
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxExportMediaFile  = 25 << 20  // bytes per file
+	maxExportMediaTotal = 100 << 20 // bytes per export, it is built in memory
+	// exportMediaBudget leaves the handler time to send the archive within
+	// the server's write timeout
+	exportMediaBudget = 40 * time.Second
+)
+
+var (
+	errMediaHostNotAllowed = errors.New("file is not hosted by the storage provider")
+	errMediaTooLarge       = errors.New("file is too large to include")
+	errExportSizeLimit     = errors.New("the export size limit was reached")
+
+	mediaExtension = regexp.MustCompile(`^\.[a-z0-9]{1,5}$`)
+)
+
+// exportMediaClient only follows redirects to allowed hosts, so a profile
+// photo URL can't make the server fetch something on the internal network
+var exportMediaClient = &http.Client{
+	Timeout: 20 * time.Second,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("too many redirects")
+		}
+		if !mediaHostAllowed(req.URL) {
+			return errMediaHostNotAllowed
+		}
+		return nil
+	},
+}
+
+// mediaHosts are the hosts uploads are served from (MEDIA_HOSTS, comma
+// separated; "*.example.com" matches subdomains). Photo URLs are set by the
+// app, so anything else is listed but not downloaded.
+func mediaHosts() []string {
+	hosts := os.Getenv("MEDIA_HOSTS")
+	if hosts == "" {
+		hosts = "*.supabase.co"
+	}
+	return strings.Split(hosts, ",")
+}
+
+func mediaHostAllowed(u *url.URL) bool {
+	if u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range mediaHosts() {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+		} else if allowed != "" && host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// addMediaFiles downloads each file into media/ and records in media where
+// it went. A file that can't be fetched is noted in its Error and skipped;
+// only a failure to write the archive fails the export.
+func addMediaFiles(ctx context.Context, archive *zip.Writer, media []MediaFile) error {
+	ctx, cancel := context.WithTimeout(ctx, exportMediaBudget)
+	defer cancel()
+
+	var total int64
+	for i := range media {
+		file := &media[i]
+		if total >= maxExportMediaTotal {
+			file.Error = errExportSizeLimit.Error()
+			continue
+		}
+
+		body, err := fetchMedia(ctx, file.URL, min(maxExportMediaFile, maxExportMediaTotal-total))
+		if err != nil {
+			file.Error = err.Error()
+			continue
+		}
+
+		name := fmt.Sprintf("media/%s-%d%s", file.Kind, file.SourceID, mediaFileExtension(file.URL))
+		w, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(body); err != nil {
+			return err
+		}
+		file.File = name
+		total += int64(len(body))
+	}
+	return nil
+}
+
+func fetchMedia(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || !mediaHostAllowed(u) {
+		return nil, errMediaHostNotAllowed
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := exportMediaClient.Do(req)
+	if err != nil {
+		if errors.Is(err, errMediaHostNotAllowed) {
+			return nil, errMediaHostNotAllowed
+		}
+		return nil, errors.New("the file could not be downloaded")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the storage provider answered %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, errors.New("the file could not be downloaded")
+	}
+	if int64(len(body)) > limit {
+		if limit < maxExportMediaFile {
+			return nil, errExportSizeLimit
+		}
+		return nil, errMediaTooLarge
+	}
+	return body, nil
+}
+
+// mediaFileExtension keeps a short, plain extension from the URL so the file
+// opens with the right program; anything odd is dropped
+func mediaFileExtension(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	ext := strings.ToLower(path.Ext(u.Path))
+	if !mediaExtension.MatchString(ext) {
+		return ""
+	}
+	return ext
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	usernameChangeCooldown    = 30 * 24 * time.Hour
+	usernameReservationPeriod = 90 * 24 * time.Hour
+	emailChangeCodeTTL        = 15 * time.Minute
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.]{3,30}$`)
+
+var (
+	ErrInvalidUsername    = apperr.Invalid("username must be 3 to 30 letters, digits, dots or underscores")
+	ErrUsernameNotChanged = apperr.Invalid("that is already your username")
+	ErrUsernameCooldown   = apperr.New(apperr.CodeRateLimited, "username can only be changed once every 30 days")
+	ErrInvalidEmail       = apperr.Invalid("invalid email")
+	ErrEmailNotChanged    = apperr.Invalid("that is already your email")
+	ErrNoEmailChange      = apperr.NotFound("no pending email change")
+	ErrInvalidEmailCode   = apperr.Invalid("invalid or expired verification code")
+)
+
+// =================== Username change ===================
+// The old handle stays reserved for the user for usernameReservationPeriod so
+// nobody else can pick it up while links to it are still around.
+func ChangeUsername(ctx context.Context, userID uint64, username string) (*model.User, error) {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Username == username {
+		return nil, ErrUsernameNotChanged
+	}
+
+	// The cooldown is checked under the row lock, so parallel renames can't both pass it
+	now := time.Now()
+	err = repository.ChangeUsername(db.DB, userID, username, usernameChangeCooldown, usernameReservationPeriod, now)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrUsernameCooldown):
+			return nil, ErrUsernameCooldown
+		case errors.Is(err, repository.ErrUsernameTaken):
+			return nil, ErrUsernameExists
+		case errors.Is(err, repository.ErrUserNotFound):
+			return nil, ErrUserNotFound
+		}
+		return nil, apperr.Unique(err, ErrUsernameExists)
+	}
+
+	recordAuditEvent(ctx, userID, userID, model.AuditUsernameChanged, nil)
+	user.Username = username
+	user.UsernameChangedAt = &now
+	return user, nil
+}
+
+// =================== Email change ===================
+// RequestEmailChange sends a code to the new address with the sign-up email
+// template. The account keeps its current email until ConfirmEmailChange.
+func RequestEmailChange(ctx context.Context, userID uint64, email string) error {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return ErrInvalidEmail
+	}
+
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if strings.EqualFold(user.Email, email) {
+		return ErrEmailNotChanged
+	}
+
+	taken, err := repository.IsEmailTaken(db.DB, email, userID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrEmailExists
+	}
+
+	code := generateCode()
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.SaveEmailChangeRequest(tx, userID, email, code, time.Now().Add(emailChangeCodeTTL)); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, email, mailer.TemplateVerificationCode, codeEmailData{Code: code, ExpiresIn: "15 minutes"})
+	})
+	if err != nil {
+		return err
+	}
+	wakeMailOutbox()
+	recordAuditEvent(ctx, userID, userID, model.AuditEmailChangeRequested, nil)
+	return nil
+}
+
+// ConfirmEmailChange checks the code sent to the new address, switches the
+// account over and lets the old address know
+func ConfirmEmailChange(ctx context.Context, userID uint64, code string) (*model.User, error) {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	request, err := repository.GetEmailChangeRequest(db.DB, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNoEmailChange) {
+			return nil, ErrNoEmailChange
+		}
+		return nil, err
+	}
+
+	if time.Now().After(request.ExpiresAt) {
+		return nil, ErrInvalidEmailCode
+	}
+	// Count the guess before comparing, so parallel guesses can't exceed the limit
+	allowed, err := repository.CountEmailChangeAttempt(db.DB, userID, maxCodeAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, ErrTooManyCodeAttempts
+	}
+	if subtle.ConstantTimeCompare([]byte(request.Code), []byte(strings.TrimSpace(code))) != 1 {
+		return nil, ErrInvalidEmailCode
+	}
+
+	completed, err := repository.CompleteEmailChange(db.DB, userID, request.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNoEmailChange):
+			return nil, ErrNoEmailChange
+		case errors.Is(err, repository.ErrEmailChangeUnverified):
+			return nil, ErrInvalidEmailCode
+		case errors.Is(err, repository.ErrEmailTaken):
+			return nil, ErrEmailExists
+		}
+		return nil, apperr.Unique(err, ErrEmailExists)
+	}
+
+	oldEmail := user.Email
+	user.Email = completed.NewEmail
+	recordAuditEvent(ctx, userID, userID, model.AuditEmailChanged, nil)
+
+	// The change already happened; a failed notice shouldn't undo it
+	if err := enqueueEmail(db.DB, oldEmail, mailer.TemplateEmailChanged, struct{ NewEmail string }{completed.NewEmail}); err != nil {
+		slog.Error("email change: notify old address failed", "user_id", userID, "error", err)
+	}
+	wakeMailOutbox()
+	return user, nil
+}
+
+// resolveReservedUsername finds the account that recently gave up username,
+// so old profile links keep working during the reservation
+func resolveReservedUsername(username string) (*model.User, error) {
+	reservation, err := repository.GetActiveUsernameReservation(db.DB, username)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return repository.GetUserByID(db.DB, reservation.UserID)
+}
@@ -0,0 +1,416 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+const (
+	maxCoverLetterLength    = 5000
+	maxRecruiterNotesLength = 5000
+	maxCVFileNameLength     = 255
+)
+
+var (
+	ErrApplicationNotFound  = apperr.NotFound("application not found")
+	ErrAlreadyApplied       = apperr.Conflict("you have already applied to this job")
+	ErrApplyToOwnJob        = apperr.Forbidden("you cannot apply to your own job")
+	ErrInvalidCV            = apperr.Invalid("cv_url must be an http(s) link to your CV")
+	ErrCoverLetterTooLong   = apperr.Invalid("cover letter is too long")
+	ErrNotesTooLong         = apperr.Invalid("notes are too long")
+	ErrInvalidStage         = apperr.Invalid("stage must be screening, interview, offer or rejected")
+	ErrInvalidStageFilter   = apperr.Invalid("stage must be applied, screening, interview, offer, rejected or withdrawn")
+	ErrStageUnchanged       = apperr.Conflict("application is already in this stage")
+	ErrApplicationWithdrawn = apperr.Conflict("application was withdrawn")
+	ErrApplicationFinal     = apperr.Conflict("application is already closed")
+)
+
+// ApplicationInput is what a candidate submits when applying
+type ApplicationInput struct {
+	CVURL       string
+	CVFileName  string
+	CoverLetter string
+}
+
+// StageChangeView is one entry of an application's history
+type StageChangeView struct {
+	FromStage string `json:"from_stage,omitempty"`
+	ToStage   string `json:"to_stage"`
+	ChangedBy string `json:"changed_by"`
+	ChangedAt string `json:"changed_at"`
+}
+
+// ApplicantView is the candidate behind an application
+type ApplicantView struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	PhotoURL string `json:"photo_url"`
+}
+
+// ApplicationJobView is the job an application was made to
+type ApplicationJobView struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Company string `json:"company"`
+	Status  string `json:"status"`
+}
+
+// ApplicationView is an application as shown to the candidate or the recruiter.
+// RecruiterNotes is only filled in for the recruiter.
+type ApplicationView struct {
+	ID             string              `json:"id"`
+	JobID          string              `json:"job_id"`
+	UserID         string              `json:"user_id"`
+	CVURL          string              `json:"cv_url"`
+	CVFileName     string              `json:"cv_file_name"`
+	CoverLetter    string              `json:"cover_letter"`
+	Stage          string              `json:"stage"`
+	StageChangedAt string              `json:"stage_changed_at"`
+	CreatedAt      string              `json:"created_at"`
+	RecruiterNotes *string             `json:"recruiter_notes,omitempty"`
+	Applicant      *ApplicantView      `json:"applicant,omitempty"`
+	Job            *ApplicationJobView `json:"job,omitempty"`
+	History        []StageChangeView   `json:"history,omitempty"`
+}
+
+func buildApplicationView(application model.Application, forRecruiter bool) ApplicationView {
+	view := ApplicationView{
+		ID:             strconv.FormatUint(application.ID, 10),
+		JobID:          strconv.FormatUint(application.JobID, 10),
+		UserID:         strconv.FormatUint(application.UserID, 10),
+		CVURL:          application.CVURL,
+		CVFileName:     application.CVFileName,
+		CoverLetter:    application.CoverLetter,
+		Stage:          application.Stage,
+		StageChangedAt: application.StageChangedAt.Format(time.RFC3339),
+		CreatedAt:      application.CreatedAt.Format(time.RFC3339),
+	}
+	if forRecruiter {
+		notes := application.RecruiterNotes
+		view.RecruiterNotes = &notes
+	}
+	return view
+}
+
+type ApplicantPage struct {
+	Applicants  []ApplicationView `json:"applicants"`
+	StageCounts map[string]int64  `json:"stage_counts"`
+	NextCursor  string            `json:"next_cursor,omitempty"`
+}
+
+type ApplicationPage struct {
+	Applications []ApplicationView `json:"applications"`
+	NextCursor   string            `json:"next_cursor,omitempty"`
+}
+
+// =================== Apply to a job ===================
+func ApplyToJob(userID, jobID uint64, input ApplicationInput) (*ApplicationView, error) {
+	job, err := repository.GetJobByID(db.DB, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	if job.Status != model.JobStatusOpen {
+		return nil, ErrJobClosed
+	}
+	if job.RecruiterID == userID {
+		return nil, ErrApplyToOwnJob
+	}
+
+	input.CVURL = strings.TrimSpace(input.CVURL)
+	input.CVFileName = strings.TrimSpace(input.CVFileName)
+	input.CoverLetter = strings.TrimSpace(input.CoverLetter)
+
+	if !isHTTPURL(input.CVURL) || len(input.CVFileName) > maxCVFileNameLength {
+		return nil, ErrInvalidCV
+	}
+	if len(input.CoverLetter) > maxCoverLetterLength {
+		return nil, ErrCoverLetterTooLong
+	}
+
+	application := model.NewApplication(jobID, userID, input.CVURL, input.CVFileName, input.CoverLetter)
+	if err := repository.CreateApplication(db.DB, &application); err != nil {
+		if errors.Is(err, repository.ErrAlreadyApplied) {
+			return nil, ErrAlreadyApplied
+		}
+		return nil, err
+	}
+
+	view := buildApplicationView(application, false)
+	return &view, nil
+}
+
+func isHTTPURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// =================== Withdraw (candidate) ===================
+func WithdrawApplication(userID, applicationID uint64) (*ApplicationView, error) {
+	application, err := getApplication(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.UserID != userID {
+		return nil, ErrApplicationNotFound
+	}
+	if application.IsFinal() {
+		return nil, ErrApplicationFinal
+	}
+
+	if err := repository.ChangeApplicationStage(db.DB, application, model.ApplicationStageWithdrawn, userID, nil, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrApplicationNotFound) {
+			// The stage changed underneath us; let the app reload it
+			return nil, ErrStageUnchanged
+		}
+		return nil, err
+	}
+
+	view := buildApplicationView(*application, false)
+	return &view, nil
+}
+
+// =================== Move through the pipeline (recruiter) ===================
+// Access is checked by middleware.RequireApplicationRole. The candidate is
+// notified of every change. A rejected application can be moved back into the
+// pipeline; a withdrawn one cannot.
+func ChangeApplicationStage(recruiterID, applicationID uint64, stage string) (*ApplicationView, error) {
+	stage = strings.ToLower(strings.TrimSpace(stage))
+	switch stage {
+	case model.ApplicationStageScreening, model.ApplicationStageInterview,
+		model.ApplicationStageOffer, model.ApplicationStageRejected:
+	default:
+		return nil, ErrInvalidStage
+	}
+
+	application, job, err := applicationWithJob(applicationID)
+	if err != nil {
+		return nil, err
+	}
+	if application.Stage == model.ApplicationStageWithdrawn {
+		return nil, ErrApplicationWithdrawn
+	}
+	if application.Stage == stage {
+		return nil, ErrStageUnchanged
+	}
+
+	message := fmt.Sprintf("Your application for %s at %s moved to %s", job.Title, job.Company, stage)
+	if stage == model.ApplicationStageRejected {
+		message = fmt.Sprintf("Your application for %s at %s was not selected", job.Title, job.Company)
+	}
+	notification := &model.Notification{
+		UserID:     application.UserID, // recipient (candidate)
+		FromUserID: recruiterID,
+		Type:       repository.NotificationTypeApplication,
+		PostID:     job.PostID,
+		Message:    &message,
+		IsRead:     false,
+	}
+
+	if err := repository.ChangeApplicationStage(db.DB, application, stage, recruiterID, notification, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrApplicationNotFound) {
+			return nil, ErrStageUnchanged
+		}
+		return nil, err
+	}
+
+	view := buildApplicationView(*application, true)
+	return &view, nil
+}
+
+// =================== Recruiter notes ===================
+// Access is checked by middleware.RequireApplicationRole.
+func UpdateApplicationNotes(applicationID uint64, notes string) (*ApplicationView, error) {
+	notes = strings.TrimSpace(notes)
+	if len(notes) > maxRecruiterNotesLength {
+		return nil, ErrNotesTooLong
+	}
+
+	application, err := getApplication(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repository.UpdateApplicationNotes(db.DB, applicationID, notes); err != nil {
+		if errors.Is(err, repository.ErrApplicationNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, err
+	}
+	application.RecruiterNotes = notes
+
+	view := buildApplicationView(*application, true)
+	return &view, nil
+}
+
+// =================== Get one application ===================
+// Visible to the candidate and to anyone with a role for the job, with its history.
+func GetApplication(viewerID, applicationID uint64) (*ApplicationView, error) {
+	application, job, err := applicationWithJob(applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := repository.GetJobRole(db.DB, job, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	isRecruiter := role != ""
+	if !isRecruiter && application.UserID != viewerID {
+		return nil, ErrApplicationNotFound
+	}
+
+	changes, err := repository.GetApplicationStageChanges(db.DB, applicationID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := buildApplicationView(*application, isRecruiter)
+	view.Job = &ApplicationJobView{
+		ID:      strconv.FormatUint(job.ID, 10),
+		Title:   job.Title,
+		Company: job.Company,
+		Status:  job.Status,
+	}
+	if isRecruiter {
+		if user, err := repository.GetUserByID(db.DB, application.UserID); err == nil {
+			view.Applicant = &ApplicantView{
+				ID:       strconv.FormatUint(user.ID, 10),
+				Name:     user.Name,
+				Username: user.Username,
+				PhotoURL: user.PhotoURL,
+			}
+		}
+	}
+	for _, change := range changes {
+		view.History = append(view.History, StageChangeView{
+			FromStage: change.FromStage,
+			ToStage:   change.ToStage,
+			ChangedBy: strconv.FormatUint(change.ChangedBy, 10),
+			ChangedAt: change.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return &view, nil
+}
+
+// =================== Applicants of a job (recruiter) ===================
+// Access is checked by middleware.RequireJobRole.
+func ListJobApplicants(jobID uint64, filter repository.ApplicantFilter, cursorToken string, limit int) (*ApplicantPage, error) {
+	if _, err := getJob(jobID); err != nil {
+		return nil, err
+	}
+
+	filter.Stage = strings.ToLower(strings.TrimSpace(filter.Stage))
+	if filter.Stage != "" && !containsString(model.ApplicationStages, filter.Stage) {
+		return nil, ErrInvalidStageFilter
+	}
+	filter.Query = strings.TrimSpace(filter.Query)
+
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	applicants, err := repository.GetJobApplicants(db.DB, jobID, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := repository.CountJobApplicantsByStage(db.DB, jobID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ApplicantPage{Applicants: []ApplicationView{}, StageCounts: counts}
+	for _, applicant := range applicants {
+		view := buildApplicationView(applicant.Application, true)
+		view.Applicant = &ApplicantView{
+			ID:       strconv.FormatUint(applicant.UserID, 10),
+			Name:     applicant.Name,
+			Username: applicant.Username,
+			PhotoURL: applicant.PhotoURL,
+		}
+		page.Applicants = append(page.Applicants, view)
+	}
+	if len(applicants) == limit {
+		last := applicants[len(applicants)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// =================== My applications (candidate) ===================
+func ListMyApplications(userID uint64, stage, cursorToken string, limit int) (*ApplicationPage, error) {
+	stage = strings.ToLower(strings.TrimSpace(stage))
+	if stage != "" && !containsString(model.ApplicationStages, stage) {
+		return nil, ErrInvalidStageFilter
+	}
+
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	applications, err := repository.GetUserApplications(db.DB, userID, stage, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ApplicationPage{Applications: []ApplicationView{}}
+	for _, application := range applications {
+		view := buildApplicationView(application.Application, false)
+		view.Job = &ApplicationJobView{
+			ID:      strconv.FormatUint(application.JobID, 10),
+			Title:   application.JobTitle,
+			Company: application.JobCompany,
+			Status:  application.JobStatus,
+		}
+		page.Applications = append(page.Applications, view)
+	}
+	if len(applications) == limit {
+		last := applications[len(applications)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+func getApplication(applicationID uint64) (*model.Application, error) {
+	application, err := repository.GetApplicationByID(db.DB, applicationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrApplicationNotFound) {
+			return nil, ErrApplicationNotFound
+		}
+		return nil, err
+	}
+	return application, nil
+}
+
+// applicationWithJob loads an application and the job it was made to
+func applicationWithJob(applicationID uint64) (*model.Application, *model.Job, error) {
+	application, err := getApplication(applicationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	job, err := getJob(application.JobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return application, job, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/audit"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+// maxAuditUserAgent is the user_agent column's size
+const maxAuditUserAgent = 512
+
+type AuditEventView struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	ActorID   string            `json:"actor_id"`
+	Type      string            `json:"type"`
+	IP        string            `json:"ip"`
+	UserAgent string            `json:"user_agent"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt string            `json:"created_at"`
+}
+
+func buildAuditEventView(event model.AuditEvent) AuditEventView {
+	return AuditEventView{
+		ID:        strconv.FormatUint(event.ID, 10),
+		UserID:    strconv.FormatUint(event.UserID, 10),
+		ActorID:   strconv.FormatUint(event.ActorID, 10),
+		Type:      event.Type,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		Metadata:  event.Metadata,
+		CreatedAt: event.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// AuditEventPage is one page of the security audit log
+type AuditEventPage struct {
+	Events     []AuditEventView `json:"events"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+}
+
+// recordAuditEvent appends an event about userID, caused by actorID, with the
+// caller's IP and user agent from ctx. The action it records has already
+// happened, so a failed write is logged rather than returned.
+func recordAuditEvent(ctx context.Context, userID, actorID uint64, eventType string, metadata map[string]string) {
+	client := audit.ClientFrom(ctx)
+	userAgent := client.UserAgent
+	if len(userAgent) > maxAuditUserAgent {
+		userAgent = strings.ToValidUTF8(userAgent[:maxAuditUserAgent], "")
+	}
+
+	event := model.NewAuditEvent(userID, actorID, eventType, client.IP, userAgent, metadata)
+	if err := repository.CreateAuditEvent(db.DB, &event); err != nil {
+		slog.ErrorContext(ctx, "record audit event failed", "type", eventType, "user_id", userID, "error", err)
+	}
+}
+
+// ListMySecurityEvents is the user's own security history. Events an admin
+// caused keep the admin's IP and user agent to themselves.
+func ListMySecurityEvents(userID uint64, cursorToken string, limit int) (*AuditEventPage, error) {
+	page, err := ListAuditEvents(repository.AuditEventFilter{UserID: userID}, cursorToken, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range page.Events {
+		if page.Events[i].ActorID != page.Events[i].UserID {
+			page.Events[i].IP = ""
+			page.Events[i].UserAgent = ""
+		}
+	}
+	return page, nil
+}
+
+func ListAuditEvents(filter repository.AuditEventFilter, cursorToken string, limit int) (*AuditEventPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	events, err := repository.GetAuditEvents(db.DB, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &AuditEventPage{Events: []AuditEventView{}}
+	for _, event := range events {
+		page.Events = append(page.Events, buildAuditEventView(event))
+	}
+	if len(events) == limit {
+		last := events[len(events)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
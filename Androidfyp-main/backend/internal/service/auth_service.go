@@ -1,23 +1,44 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
 	"fmt"
-	"math/rand"
+	"math/big"
 	"time"
 
+	"wazzafak_back/internal/apperr"
 	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
 	"wazzafak_back/internal/model"
-	"wazzafak_back/utils"
+	"wazzafak_back/internal/repository"
 
 	"gorm.io/gorm"
 )
 
+// maxCodeAttempts is how many guesses an emailed code allows before it
+// stops working and a new one has to be requested
+const maxCodeAttempts = 5
+
+var (
+	ErrTooManyCodeAttempts = apperr.New(apperr.CodeRateLimited, "too many wrong codes, request a new one")
+	ErrInvalidCode         = apperr.Invalid("invalid verification code or email")
+	ErrCodeExpired         = apperr.Invalid("verification code expired")
+	ErrEmailNotVerified    = apperr.Invalid("email not verified. Please verify your email first")
+	ErrAlreadyRegistered   = apperr.Conflict("username or email already registered")
+)
+
 // Generate a 6-digit numeric verification code
-var rnd = rand.New(rand.NewSource(time.Now().UnixNano()))
+var codeSpace = big.NewInt(1000000)
 
 func generateCode() string {
-	return fmt.Sprintf("%06d", rnd.Intn(1000000))
+	n, err := rand.Int(rand.Reader, codeSpace)
+	if err != nil {
+		// crypto/rand only fails when the OS has no usable entropy source
+		panic(fmt.Sprintf("generate code: %v", err))
+	}
+	return fmt.Sprintf("%06d", n.Int64())
 }
 
 // SendVerificationCode - Step 1: Generate code and send email (no password involved)
@@ -28,46 +49,84 @@ func SendVerificationCode(email string) (string, error) {
 		return "", err
 	}
 	if count > 0 {
-		return "", errors.New("email already registered")
+		return "", ErrEmailExists
 	}
 
+	return issueVerificationCode(email)
+}
+
+// issueVerificationCode stores a fresh 15-minute code for the email in
+// email_verifications and mails it there
+func issueVerificationCode(email string) (string, error) {
 	code := generateCode()
 	expiresAt := time.Now().Add(15 * time.Minute)
 
-	// Store the verification code
-	err := db.DB.Exec(`
-		INSERT INTO email_verifications (email, verification_code, created_at, expires_at, verified)
-		VALUES (?, ?, NOW(), ?, false)
-	`, email, code, expiresAt).Error
-	if err != nil {
-		return "", err
-	}
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		// Only the newest code counts, so drop the ones it replaces
+		if err := tx.Exec(`DELETE FROM email_verifications WHERE email = ? AND verified = false`, email).Error; err != nil {
+			return err
+		}
 
-	// Send verification email
-	err = utils.SendVerificationEmail(email, code)
+		// Store the verification code
+		if err := tx.Exec(`
+			INSERT INTO email_verifications (email, verification_code, created_at, expires_at, verified)
+			VALUES (?, ?, NOW(), ?, false)
+		`, email, code, expiresAt).Error; err != nil {
+			return err
+		}
+
+		// Queue the verification email with it
+		return enqueueEmail(tx, email, mailer.TemplateVerificationCode, codeEmailData{Code: code, ExpiresIn: "15 minutes"})
+	})
 	if err != nil {
 		return "", err
 	}
 
+	wakeMailOutbox()
 	return code, nil
 }
 
+// codeEmailData fills the verification and password reset templates
+type codeEmailData struct {
+	Code      string
+	ExpiresIn string
+}
+
 // VerifyEmailCode - Step 2: Verify code and mark email as verified (still no user created)
 func VerifyEmailCode(email, code string) error {
 	var id int
+	var storedCode string
 	var expiresAt time.Time
 
 	row := db.DB.Raw(`
-		SELECT id, expires_at FROM email_verifications
-		WHERE email = ? AND verification_code = ? AND verified = false
-	`, email, code).Row()
-
-	if err := row.Scan(&id, &expiresAt); err != nil {
-		return errors.New("invalid verification code or email")
+		SELECT id, verification_code, expires_at FROM email_verifications
+		WHERE email = ? AND verified = false
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, email).Row()
+
+	if err := row.Scan(&id, &storedCode, &expiresAt); err != nil {
+		return ErrInvalidCode
 	}
 
 	if time.Now().After(expiresAt) {
-		return errors.New("verification code expired")
+		return ErrCodeExpired
+	}
+
+	// Count the guess before comparing, so parallel guesses can't exceed the limit
+	result := db.DB.Exec(`
+		UPDATE email_verifications SET attempts = attempts + 1
+		WHERE id = ? AND attempts < ?
+	`, id, maxCodeAttempts)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTooManyCodeAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(storedCode), []byte(code)) != 1 {
+		return ErrInvalidCode
 	}
 
 	// Mark verification as used
@@ -80,7 +139,11 @@ func VerifyEmailCode(email, code string) error {
 }
 
 // CompleteUserRegistration - Step 3: Create user with password (after email verified)
-func CompleteUserRegistration(email, username, name, password, jobPosition, jobPositionType string) (uint64, error) {
+func CompleteUserRegistration(ctx context.Context, email, username, name, password, jobPosition, jobPositionType string) (uint64, error) {
+	if err := CheckPasswordPolicy(password, username, email); err != nil {
+		return 0, err
+	}
+
 	var userID uint64
 
 	err := db.DB.Transaction(func(tx *gorm.DB) error {
@@ -94,23 +157,32 @@ func CompleteUserRegistration(email, username, name, password, jobPosition, jobP
 		`, email).Row()
 
 		if err := row.Scan(&verificationID); err != nil {
-			return errors.New("email not verified. Please verify your email first")
+			return ErrEmailNotVerified
+		}
+
+		// A handle someone just gave up stays theirs while it is reserved
+		taken, err := repository.IsUsernameTaken(tx, username, 0)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrUsernameExists
 		}
 
 		// Create new user
 		user, err := model.NewUser(username, name, email, password, jobPosition, jobPositionType)
 		if err != nil {
-			return errors.New("failed to create user: " + err.Error())
+			return fmt.Errorf("failed to create user: %w", err)
 		}
 
 		// Save user in DB
 		if err := tx.Create(user).Error; err != nil {
-			return errors.New("registration failed: " + err.Error())
+			return apperr.Unique(err, ErrAlreadyRegistered)
 		}
 
 		// Delete verification record to prevent reuse
 		if err := tx.Exec(`DELETE FROM email_verifications WHERE id = ?`, verificationID).Error; err != nil {
-			return errors.New("failed to delete verification record: " + err.Error())
+			return fmt.Errorf("failed to delete verification record: %w", err)
 		}
 
 		userID = user.ID
@@ -121,5 +193,9 @@ func CompleteUserRegistration(email, username, name, password, jobPosition, jobP
 		return 0, err
 	}
 
+	// The address was verified before the account existed; the event goes
+	// to the account it ended up on
+	recordAuditEvent(ctx, userID, userID, model.AuditEmailVerified, nil)
+	recordAuditEvent(ctx, userID, userID, model.AuditAccountRegistered, nil)
 	return userID, nil
 }
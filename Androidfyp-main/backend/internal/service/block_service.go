@@ -0,0 +1,32 @@
+package service
+
+import (
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+var (
+	ErrBlockYourself = apperr.Invalid("you cannot block yourself")
+	ErrUserBlocked   = apperr.Forbidden("you can't interact with this user")
+)
+
+// BlockUser also removes any follow between the two users
+func BlockUser(blockerID, blockedID uint64) error {
+	if blockerID == blockedID {
+		return ErrBlockYourself
+	}
+	if _, err := repository.GetUserByID(db.DB, blockedID); err != nil {
+		return ErrUserNotFound
+	}
+	return repository.BlockUser(db.DB, blockerID, blockedID)
+}
+
+func UnblockUser(blockerID, blockedID uint64) error {
+	return repository.UnblockUser(db.DB, blockerID, blockedID)
+}
+
+func GetBlockedUsers(blockerID uint64) ([]model.User, error) {
+	return repository.GetBlockedUsers(db.DB, blockerID)
+}
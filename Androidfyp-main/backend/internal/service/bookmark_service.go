@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+var (
+	ErrCollectionNotFound    = apperr.NotFound("collection not found")
+	ErrInvalidCollectionName = apperr.Invalid("collection name must be between 1 and 100 characters")
+	ErrCollectionExists      = apperr.Conflict("a collection with this name already exists")
+)
+
+type BookmarkCollectionView struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+func buildBookmarkCollectionView(collection model.BookmarkCollection) BookmarkCollectionView {
+	return BookmarkCollectionView{
+		ID:        strconv.FormatUint(collection.ID, 10),
+		UserID:    strconv.FormatUint(collection.UserID, 10),
+		Name:      collection.Name,
+		CreatedAt: collection.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// BookmarkPage is one page of a user's saved posts
+type BookmarkPage struct {
+	Posts      []repository.BookmarkedPost
+	NextCursor string
+}
+
+// =================== Bookmark a post ===================
+func BookmarkPost(userID, postID uint64, collectionID *uint64) error {
+	exists, err := repository.PostExists(db.DB, postID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrPostNotFound
+	}
+
+	if collectionID != nil {
+		if _, err := repository.GetBookmarkCollection(db.DB, userID, *collectionID); err != nil {
+			if errors.Is(err, repository.ErrCollectionNotFound) {
+				return ErrCollectionNotFound
+			}
+			return err
+		}
+	}
+
+	return repository.SaveBookmark(db.DB, userID, postID, collectionID)
+}
+
+// =================== Remove a bookmark ===================
+func RemoveBookmark(userID, postID uint64) error {
+	return repository.RemoveBookmark(db.DB, userID, postID)
+}
+
+// =================== Check if user bookmarked a post ===================
+func HasUserBookmarked(userID, postID uint64) (bool, error) {
+	return repository.HasUserBookmarked(db.DB, userID, postID)
+}
+
+// =================== List my bookmarks (cursor paginated) ===================
+func GetMyBookmarks(userID uint64, collectionID *uint64, cursorToken string, limit int) (*BookmarkPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	posts, err := repository.GetUserBookmarks(db.DB, userID, collectionID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &BookmarkPage{Posts: posts}
+	if len(posts) == limit {
+		last := posts[len(posts)-1]
+		page.NextCursor = encodeCursor(last.BookmarkedAt, last.ID)
+	}
+	return page, nil
+}
+
+// =================== Collections ===================
+func CreateBookmarkCollection(userID uint64, name string) (*BookmarkCollectionView, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return nil, ErrInvalidCollectionName
+	}
+
+	exists, err := repository.BookmarkCollectionNameExists(db.DB, userID, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrCollectionExists
+	}
+
+	collection := model.NewBookmarkCollection(userID, name)
+	if err := repository.CreateBookmarkCollection(db.DB, &collection); err != nil {
+		// A parallel request can take the name between the check and the insert
+		return nil, apperr.Unique(err, ErrCollectionExists)
+	}
+	view := buildBookmarkCollectionView(collection)
+	return &view, nil
+}
+
+func GetBookmarkCollections(userID uint64) ([]BookmarkCollectionView, error) {
+	collections, err := repository.GetBookmarkCollections(db.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := []BookmarkCollectionView{}
+	for _, collection := range collections {
+		views = append(views, buildBookmarkCollectionView(collection))
+	}
+	return views, nil
+}
+
+func RenameBookmarkCollection(userID, collectionID uint64, name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return ErrInvalidCollectionName
+	}
+
+	exists, err := repository.BookmarkCollectionNameExists(db.DB, userID, name, collectionID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return ErrCollectionExists
+	}
+
+	err = repository.RenameBookmarkCollection(db.DB, userID, collectionID, name)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionNotFound
+	}
+	return apperr.Unique(err, ErrCollectionExists)
+}
+
+func DeleteBookmarkCollection(userID, collectionID uint64) error {
+	err := repository.DeleteBookmarkCollection(db.DB, userID, collectionID)
+	if errors.Is(err, repository.ErrCollectionNotFound) {
+		return ErrCollectionNotFound
+	}
+	return err
+}
@@ -1,22 +1,26 @@
 package service
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"time"
 
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/contentfilter"
 	db "wazzafak_back/internal/database"
 	"wazzafak_back/internal/model"
 	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 var (
-	ErrEmptyComment    = errors.New("comment content cannot be empty")
-	ErrCommentNotFound = errors.New("comment not found")
+	ErrEmptyComment    = apperr.Invalid("comment content cannot be empty")
+	ErrCommentNotFound = apperr.NotFound("comment not found")
 )
 
 // AddComment creates a new comment on a post
-func AddComment(userID, postID uint64, content string) (*model.Comment, error) {
+func AddComment(ctx context.Context, userID, postID uint64, content string) (*model.Comment, error) {
 	if content == "" {
 		return nil, ErrEmptyComment
 	}
@@ -30,13 +34,27 @@ func AddComment(userID, postID uint64, content string) (*model.Comment, error) {
 		return nil, ErrPostNotFound
 	}
 
+	held, holdReason, err := screenContent(ctx, userID, contentfilter.KindComment, content)
+	if err != nil {
+		return nil, err
+	}
+
 	comment := &model.Comment{
 		PostID:  postID,
 		UserID:  userID,
 		Content: content,
+		Hidden:  held,
 	}
 
-	err = repository.CreateComment(db.DB, comment)
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.CreateComment(tx, comment); err != nil {
+			return err
+		}
+		if held {
+			return fileAutomatedReport(tx, model.ReportTargetComment, comment.ID, holdReason)
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -44,25 +62,32 @@ func AddComment(userID, postID uint64, content string) (*model.Comment, error) {
 	return comment, nil
 }
 
-// DeleteCommentFromPost deletes a comment only if the current user owns it
-func DeleteCommentFromPost(postID, commentID, userID uint64) error {
+// DeleteCommentFromPost soft-deletes a comment only if the current user owns
+// it, and returns until when it can be restored
+func DeleteCommentFromPost(postID, commentID, userID uint64) (time.Time, error) {
 	comment, err := repository.GetCommentByID(db.DB, commentID)
 	if err != nil {
 		if err == repository.ErrCommentNotFound {
-			return ErrCommentNotFound
+			return time.Time{}, ErrCommentNotFound
 		}
-		return err
+		return time.Time{}, err
 	}
 
 	if comment.PostID != postID {
-		return ErrCommentNotFound
+		return time.Time{}, ErrCommentNotFound
 	}
 
 	if comment.UserID != userID {
-		return ErrUnauthorized
+		return time.Time{}, ErrUnauthorized
 	}
 
-	return repository.DeleteComment(db.DB, commentID)
+	if err := repository.DeleteComment(db.DB, commentID); err != nil {
+		if err == repository.ErrCommentNotFound {
+			return time.Time{}, ErrCommentNotFound
+		}
+		return time.Time{}, err
+	}
+	return time.Now().Add(deletedContentRestoreWindow), nil
 }
 
 // ✅ Struct returned to frontend
@@ -84,7 +109,7 @@ func GetCommentsByPost(postID, currentUserID uint64) ([]CommentResponse, error)
 		return nil, err
 	}
 
-	var comments []CommentResponse
+	comments := []CommentResponse{}
 	for _, c := range rawComments {
 		// Convert IDs safely
 		var id, postId, userId uint64
@@ -155,7 +180,7 @@ func GetCommentByID(commentID uint64) (*model.Comment, error) {
 }
 
 // UpdateComment updates a comment content with ownership check
-func UpdateComment(commentID, userID uint64, newContent string) (*model.Comment, error) {
+func UpdateComment(ctx context.Context, commentID, userID uint64, newContent string) (*model.Comment, error) {
 	if newContent == "" {
 		return nil, ErrEmptyComment
 	}
@@ -172,8 +197,26 @@ func UpdateComment(commentID, userID uint64, newContent string) (*model.Comment,
 		return nil, ErrUnauthorized
 	}
 
+	held, holdReason, err := screenContent(ctx, userID, contentfilter.KindComment, newContent)
+	if err != nil {
+		return nil, err
+	}
+
 	comment.Content = newContent
-	err = repository.UpdateComment(db.DB, comment)
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.UpdateComment(tx, comment); err != nil {
+			return err
+		}
+		// A comment that is already hidden is already in front of a moderator
+		if !held || comment.Hidden {
+			return nil
+		}
+		if err := repository.SetCommentHidden(tx, comment.ID, true); err != nil {
+			return err
+		}
+		comment.Hidden = true
+		return fileAutomatedReport(tx, model.ReportTargetComment, comment.ID, holdReason)
+	})
 	if err != nil {
 		return nil, err
 	}
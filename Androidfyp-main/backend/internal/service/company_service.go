@@ -0,0 +1,341 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxCompanyNameLength        = 150
+	maxCompanyDescriptionLength = 5000
+	companyInviteTTL            = 72 * time.Hour
+)
+
+var (
+	ErrCompanyNotFound      = apperr.NotFound("company not found")
+	ErrInvalidCompany       = apperr.Invalid("company name is required and website/logo must be http(s) links")
+	ErrInvalidCompanyRole   = apperr.Invalid("role must be owner, recruiter or viewer")
+	ErrInvalidInviteEmail   = apperr.Invalid("invalid email")
+	ErrNotCompanyMember     = apperr.NotFound("user is not a member of this company")
+	ErrLastCompanyOwner     = apperr.Conflict("a company needs at least one owner")
+	ErrAlreadyCompanyMember = apperr.Conflict("already a member of this company")
+	ErrInviteNotFound       = apperr.NotFound("invalid or expired invite code")
+)
+
+// CompanyInput is the editable part of a company page
+type CompanyInput struct {
+	Name        string
+	LogoURL     string
+	Description string
+	Website     string
+}
+
+func (in CompanyInput) normalize() (CompanyInput, error) {
+	in.Name = strings.TrimSpace(in.Name)
+	in.LogoURL = strings.TrimSpace(in.LogoURL)
+	in.Description = strings.TrimSpace(in.Description)
+	in.Website = strings.TrimSpace(in.Website)
+
+	if in.Name == "" || len(in.Name) > maxCompanyNameLength || len(in.Description) > maxCompanyDescriptionLength {
+		return in, ErrInvalidCompany
+	}
+	if (in.LogoURL != "" && !isHTTPURL(in.LogoURL)) || (in.Website != "" && !isHTTPURL(in.Website)) {
+		return in, ErrInvalidCompany
+	}
+	return in, nil
+}
+
+// CompanyView is a company page as shown to a viewer
+type CompanyView struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	LogoURL     string `json:"logo_url"`
+	Description string `json:"description"`
+	Website     string `json:"website"`
+	MemberCount int64  `json:"member_count"`
+	ViewerRole  string `json:"viewer_role,omitempty"` // the viewer's membership role, if any
+	CreatedAt   string `json:"created_at"`
+}
+
+func buildCompanyView(company model.Company, viewerRole string) CompanyView {
+	memberCount, _ := repository.CountCompanyMembers(db.DB, company.ID)
+	return CompanyView{
+		ID:          strconv.FormatUint(company.ID, 10),
+		Name:        company.Name,
+		LogoURL:     company.LogoURL,
+		Description: company.Description,
+		Website:     company.Website,
+		MemberCount: memberCount,
+		ViewerRole:  viewerRole,
+		CreatedAt:   company.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+type CompanyMemberView struct {
+	UserID   string `json:"user_id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	PhotoURL string `json:"photo_url"`
+	Role     string `json:"role"`
+	JoinedAt string `json:"joined_at"`
+}
+
+type CompanyInviteView struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	InvitedBy string `json:"invited_by"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+func buildCompanyInviteView(invite model.CompanyInvite) CompanyInviteView {
+	return CompanyInviteView{
+		ID:        strconv.FormatUint(invite.ID, 10),
+		Email:     invite.Email,
+		Role:      invite.Role,
+		InvitedBy: strconv.FormatUint(invite.InvitedBy, 10),
+		ExpiresAt: invite.ExpiresAt.Format(time.RFC3339),
+		CreatedAt: invite.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// =================== Create a company (creator becomes owner) ===================
+func CreateCompany(userID uint64, input CompanyInput) (*CompanyView, error) {
+	input, err := input.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	company := model.NewCompany(userID, input.Name, input.LogoURL, input.Description, input.Website)
+	if err := repository.CreateCompany(db.DB, &company); err != nil {
+		return nil, err
+	}
+
+	view := buildCompanyView(company, model.CompanyRoleOwner)
+	return &view, nil
+}
+
+// =================== Get a company page ===================
+func GetCompany(companyID, viewerID uint64) (*CompanyView, error) {
+	company, err := getCompany(companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := repository.GetCompanyRole(db.DB, companyID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := buildCompanyView(*company, role)
+	return &view, nil
+}
+
+// =================== Edit a company page ===================
+// Access is checked by middleware.RequireCompanyRole.
+func UpdateCompany(companyID uint64, input CompanyInput) (*CompanyView, error) {
+	input, err := input.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	company, err := getCompany(companyID)
+	if err != nil {
+		return nil, err
+	}
+	company.Name = input.Name
+	company.LogoURL = input.LogoURL
+	company.Description = input.Description
+	company.Website = input.Website
+
+	if err := repository.UpdateCompany(db.DB, company); err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+
+	view := buildCompanyView(*company, model.CompanyRoleOwner)
+	return &view, nil
+}
+
+// =================== Members ===================
+func GetCompanyMembers(companyID uint64) ([]CompanyMemberView, error) {
+	members, err := repository.GetCompanyMembers(db.DB, companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := []CompanyMemberView{}
+	for _, member := range members {
+		views = append(views, CompanyMemberView{
+			UserID:   strconv.FormatUint(member.UserID, 10),
+			Name:     member.Name,
+			Username: member.Username,
+			PhotoURL: member.PhotoURL,
+			Role:     member.Role,
+			JoinedAt: member.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return views, nil
+}
+
+// SetCompanyMemberRole changes a member's role. Access is checked by
+// middleware.RequireCompanyRole.
+func SetCompanyMemberRole(companyID, userID uint64, role string) error {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !model.IsCompanyRole(role) {
+		return ErrInvalidCompanyRole
+	}
+	return changeCompanyMember(companyID, userID, role)
+}
+
+// RemoveCompanyMember removes a member; members may also remove themselves
+func RemoveCompanyMember(companyID, userID uint64) error {
+	return changeCompanyMember(companyID, userID, "")
+}
+
+func changeCompanyMember(companyID, userID uint64, role string) error {
+	err := repository.ChangeCompanyMember(db.DB, companyID, userID, role)
+	switch {
+	case errors.Is(err, repository.ErrNotCompanyMember):
+		return ErrNotCompanyMember
+	case errors.Is(err, repository.ErrLastCompanyOwner):
+		return ErrLastCompanyOwner
+	}
+	return err
+}
+
+// =================== Invites ===================
+// InviteCompanyMember emails a code the invitee uses to join with the given
+// role. Access is checked by middleware.RequireCompanyRole.
+func InviteCompanyMember(companyID, inviterID uint64, email, role string) (*CompanyInviteView, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, ErrInvalidInviteEmail
+	}
+	role = strings.ToLower(strings.TrimSpace(role))
+	if !model.IsCompanyRole(role) {
+		return nil, ErrInvalidCompanyRole
+	}
+
+	company, err := getCompany(companyID)
+	if err != nil {
+		return nil, err
+	}
+
+	invite := model.NewCompanyInvite(companyID, email, role, generateCode(), inviterID, time.Now().Add(companyInviteTTL))
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.SaveCompanyInvite(tx, &invite); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, email, mailer.TemplateCompanyInvite, struct {
+			Company, Role, Code, ExpiresIn string
+		}{company.Name, role, invite.Code, "3 days"})
+	})
+	if err != nil {
+		return nil, err
+	}
+	wakeMailOutbox()
+
+	// Let an existing account know about the invite in the app as well
+	if user, err := repository.GetUserByEmail(db.DB, email); err == nil {
+		message := fmt.Sprintf("You were invited to join %s as %s. Check your email for the code.", company.Name, role)
+		notification := &model.Notification{
+			UserID:     user.ID,
+			FromUserID: inviterID,
+			Type:       repository.NotificationTypeCompanyInvite,
+			Message:    &message,
+		}
+		if err := repository.CreateNotification(db.DB, notification); err != nil {
+			slog.Error("company invite: notify user failed", "user_id", user.ID, "error", err)
+		}
+	}
+
+	view := buildCompanyInviteView(invite)
+	return &view, nil
+}
+
+func GetCompanyInvites(companyID uint64) ([]CompanyInviteView, error) {
+	invites, err := repository.GetCompanyInvites(db.DB, companyID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	views := []CompanyInviteView{}
+	for _, invite := range invites {
+		views = append(views, buildCompanyInviteView(invite))
+	}
+	return views, nil
+}
+
+func RevokeCompanyInvite(companyID, inviteID uint64) error {
+	if err := repository.DeleteCompanyInvite(db.DB, companyID, inviteID); err != nil {
+		if errors.Is(err, repository.ErrInviteNotFound) {
+			return ErrInviteNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AcceptCompanyInvite joins the company the code was sent for. The invite
+// must have been sent to the user's own email.
+func AcceptCompanyInvite(userID uint64, code string) (*CompanyView, error) {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	invite, err := repository.AcceptCompanyInvite(db.DB, strings.ToLower(user.Email), strings.TrimSpace(code), userID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrInviteNotFound):
+			return nil, ErrInviteNotFound
+		case errors.Is(err, repository.ErrAlreadyCompanyMember):
+			return nil, ErrAlreadyCompanyMember
+		}
+		return nil, err
+	}
+
+	return GetCompany(invite.CompanyID, userID)
+}
+
+// =================== My companies ===================
+func GetMyCompanies(userID uint64) ([]CompanyView, error) {
+	companies, err := repository.GetUserCompanies(db.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := []CompanyView{}
+	for _, company := range companies {
+		views = append(views, buildCompanyView(company.Company, company.Role))
+	}
+	return views, nil
+}
+
+func getCompany(companyID uint64) (*model.Company, error) {
+	company, err := repository.GetCompanyByID(db.DB, companyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	return company, nil
+}
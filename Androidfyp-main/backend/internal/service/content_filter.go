@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/contentfilter"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// contentFilter screens every post and comment before it is saved.
+// It is replaced by InitContentFilter at startup.
+var contentFilter = contentfilter.NewPipeline()
+
+// SetContentFilter swaps the pipeline, e.g. to plug in another classifier
+func SetContentFilter(p *contentfilter.Pipeline) {
+	contentFilter = p
+}
+
+// InitContentFilter builds the pipeline from the environment:
+//
+//	CONTENT_FILTER_WORDLIST          path to a word list file (see contentfilter.ParseWordList)
+//	CONTENT_FILTER_MAX_LINKS         links allowed before content is held (default 3)
+//	CONTENT_FILTER_MAX_REPEATS       identical posts/comments allowed per window (default 3)
+//	CONTENT_FILTER_REPEAT_WINDOW     window for the repeat check (default 24h)
+//	CONTENT_CLASSIFIER_URL           external classifier; when unset a local fake is used
+//	CONTENT_CLASSIFIER_FAKE_KEYWORDS comma-separated words the fake classifier flags
+func InitContentFilter() error {
+	checks := []contentfilter.Check{}
+
+	if path := os.Getenv("CONTENT_FILTER_WORDLIST"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("content filter word list: %w", err)
+		}
+		defer file.Close()
+
+		words, err := contentfilter.ParseWordList(file)
+		if err != nil {
+			return err
+		}
+		checks = append(checks, words)
+	}
+
+	maxLinks, err := envInt("CONTENT_FILTER_MAX_LINKS", 3)
+	if err != nil {
+		return err
+	}
+	checks = append(checks, contentfilter.LinkLimit{MaxLinks: maxLinks})
+
+	maxRepeats, err := envInt("CONTENT_FILTER_MAX_REPEATS", 3)
+	if err != nil {
+		return err
+	}
+	window := 24 * time.Hour
+	if raw := os.Getenv("CONTENT_FILTER_REPEAT_WINDOW"); raw != "" {
+		if window, err = time.ParseDuration(raw); err != nil {
+			return fmt.Errorf("CONTENT_FILTER_REPEAT_WINDOW: %w", err)
+		}
+	}
+	checks = append(checks, contentfilter.RepeatedContent{
+		History:    contentHistory{},
+		MaxRepeats: maxRepeats,
+		Window:     window,
+	})
+
+	var classifier contentfilter.Classifier
+	if url := os.Getenv("CONTENT_CLASSIFIER_URL"); url != "" {
+		classifier = contentfilter.NewHTTPClassifier(url)
+	} else {
+		fake := contentfilter.FakeClassifier{}
+		for _, keyword := range strings.Split(os.Getenv("CONTENT_CLASSIFIER_FAKE_KEYWORDS"), ",") {
+			if keyword = strings.TrimSpace(keyword); keyword != "" {
+				fake.Keywords = append(fake.Keywords, keyword)
+			}
+		}
+		classifier = fake
+	}
+	checks = append(checks, contentfilter.ClassifierCheck{
+		Classifier:   classifier,
+		HoldAbove:    0.5,
+		RejectAbove:  0.9,
+		RejectReason: "This looks like abusive or spam content and can't be posted",
+	})
+
+	SetContentFilter(contentfilter.NewPipeline(checks...))
+	return nil
+}
+
+func envInt(key string, fallback int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return fallback, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return value, nil
+}
+
+// contentHistory feeds the repeated-content check from the database
+type contentHistory struct{}
+
+func (contentHistory) CountRecentDuplicates(userID uint64, normalized string, since time.Time) (int64, error) {
+	return repository.CountRecentDuplicateContent(db.DB, userID, normalized, since)
+}
+
+// screenContent runs the filter. On reject it returns an unprocessable error
+// carrying the reason, which is meant to be shown to the author,
+// and held=true with the reason for moderators when the content must wait for review.
+// ctx is the request's, so a slow classifier stops when the client goes away.
+func screenContent(ctx context.Context, userID uint64, kind, text string) (held bool, reason string, err error) {
+	verdict, err := contentFilter.Screen(ctx, contentfilter.Submission{
+		UserID: userID,
+		Kind:   kind,
+		Text:   text,
+	})
+	if err != nil {
+		return false, "", err
+	}
+
+	switch verdict.Action {
+	case contentfilter.ActionReject:
+		return false, "", apperr.New(apperr.CodeUnprocessable, verdict.Reason)
+	case contentfilter.ActionHold:
+		slog.Info("content filter: held for review", "kind", kind, "user_id", userID, "reason", verdict.Reason)
+		return true, verdict.Check + ": " + verdict.Reason, nil
+	}
+	return false, "", nil
+}
+
+// fileAutomatedReport puts held content in the moderation queue. Content
+// that is already waiting there, e.g. held again after an edit, is left as is.
+func fileAutomatedReport(tx *gorm.DB, targetType string, targetID uint64, reason string) error {
+	report := model.NewReport(model.SystemReporterID, targetType, targetID, model.ReportReasonAutomated, reason)
+	return repository.CreateReportOnce(tx, &report)
+}
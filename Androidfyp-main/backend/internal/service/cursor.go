@@ -0,0 +1,54 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/repository"
+)
+
+var ErrInvalidCursor = apperr.Invalid("invalid cursor")
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// encodeCursor turns the last row of a page into an opaque token for the client
+func encodeCursor(createdAt time.Time, id uint64) string {
+	raw := fmt.Sprintf("%d:%d", createdAt.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor parses a token produced by encodeCursor; empty means first page
+func decodeCursor(token string) (*repository.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var nanos int64
+	var id uint64
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &repository.Cursor{CreatedAt: time.Unix(0, nanos), ID: id}, nil
+}
+
+// clampPageSize applies the default and upper bound to a requested page size
+func clampPageSize(limit int) int {
+	if limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// deletedContentRestoreWindow is how long a deleted post or comment can be
+	// brought back before the purge job removes it for good
+	deletedContentRestoreWindow = 10 * time.Minute
+	deletedContentBatchSize     = 100
+)
+
+var ErrRestoreWindowExpired = apperr.New(apperr.CodeGone, "it is too late to restore this")
+
+// RestorePost undoes the deletion of the user's post, with the comments,
+// reposts and notifications that were hidden along with it
+func RestorePost(postID, userID uint64) (*model.Post, error) {
+	post, err := repository.GetDeletedPost(db.DB, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if post.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+	if time.Since(post.DeletedAt.Time) > deletedContentRestoreWindow {
+		return nil, ErrRestoreWindowExpired
+	}
+
+	// A repost deleted together with its original comes back with the original
+	if post.Kind == model.PostKindRepost && post.RepostOfID != nil {
+		if _, err := repository.GetPostByID(db.DB, *post.RepostOfID); err != nil {
+			return nil, ErrPostNotFound
+		}
+	}
+
+	if err := repository.RestorePost(db.DB, post); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	post.DeletedAt = gorm.DeletedAt{}
+	return post, nil
+}
+
+// RestoreComment undoes the deletion of the user's comment. Comments hidden
+// because their post was deleted come back by restoring the post.
+func RestoreComment(postID, commentID, userID uint64) (*model.Comment, error) {
+	comment, err := repository.GetDeletedComment(db.DB, commentID)
+	if err != nil {
+		if err == repository.ErrCommentNotFound {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+
+	if comment.PostID != postID {
+		return nil, ErrCommentNotFound
+	}
+	if comment.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+	if time.Since(comment.DeletedAt.Time) > deletedContentRestoreWindow {
+		return nil, ErrRestoreWindowExpired
+	}
+
+	if _, err := repository.GetPostByID(db.DB, postID); err != nil {
+		return nil, ErrPostNotFound
+	}
+
+	if err := repository.RestoreComment(db.DB, comment); err != nil {
+		if err == repository.ErrCommentNotFound {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	comment.DeletedAt = gorm.DeletedAt{}
+	return comment, nil
+}
+
+// RunDeletedContentPurge hard-deletes posts and comments once their restore
+// window is over. Like the post scheduler it is safe on every replica.
+// Blocks until ctx is cancelled.
+func RunDeletedContentPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeDeletedPosts()
+		purgeDeletedComments()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeDeletedPosts() {
+	for {
+		purged, err := repository.PurgeDeletedPosts(db.DB, time.Now().Add(-deletedContentRestoreWindow), deletedContentBatchSize)
+		if err != nil {
+			slog.Error("content purge failed", "error", err)
+			return
+		}
+		if purged < deletedContentBatchSize {
+			return
+		}
+	}
+}
+
+func purgeDeletedComments() {
+	for {
+		purged, err := repository.PurgeDeletedComments(db.DB, time.Now().Add(-deletedContentRestoreWindow), deletedContentBatchSize)
+		if err != nil {
+			slog.Error("content purge failed", "error", err)
+			return
+		}
+		if purged < deletedContentBatchSize {
+			return
+		}
+	}
+}
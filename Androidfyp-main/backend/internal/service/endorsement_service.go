@@ -0,0 +1,127 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+// topEndorsersPerSkill is how many endorsers are shown next to each skill
+const topEndorsersPerSkill = 3
+
+var (
+	ErrEndorseYourself = apperr.Invalid("you cannot endorse your own skills")
+	ErrNotConnected    = apperr.Forbidden("you can only endorse people you follow who follow you back")
+)
+
+type EndorserView struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Username string `json:"username"`
+	PhotoURL string `json:"photo_url"`
+}
+
+// buildSkillViews adds endorsement counts, top endorsers and whether the
+// viewer endorsed each skill
+func buildSkillViews(skills []model.UserSkill, viewerID uint64) ([]SkillView, error) {
+	ids := make([]uint64, 0, len(skills))
+	for _, skill := range skills {
+		ids = append(ids, skill.ID)
+	}
+
+	counts, err := repository.CountSkillEndorsements(db.DB, ids)
+	if err != nil {
+		return nil, err
+	}
+	endorsers, err := repository.GetTopEndorsers(db.DB, ids, topEndorsersPerSkill)
+	if err != nil {
+		return nil, err
+	}
+	endorsed, err := repository.GetEndorsedSkillIDs(db.DB, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	top := make(map[uint64][]EndorserView)
+	for _, endorser := range endorsers {
+		top[endorser.SkillID] = append(top[endorser.SkillID], EndorserView{
+			ID:       strconv.FormatUint(endorser.UserID, 10),
+			Name:     endorser.Name,
+			Username: endorser.Username,
+			PhotoURL: endorser.PhotoURL,
+		})
+	}
+
+	views := []SkillView{}
+	for _, skill := range skills {
+		view := SkillView{
+			Name:             skill.Name,
+			EndorsementCount: counts[skill.ID],
+			TopEndorsers:     top[skill.ID],
+			EndorsedByMe:     endorsed[skill.ID],
+		}
+		if view.TopEndorsers == nil {
+			view.TopEndorsers = []EndorserView{}
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// EndorseSkill vouches for a skill on someone's profile. Only mutual
+// followers may endorse each other.
+func EndorseSkill(endorserID, userID uint64, skillName string) error {
+	skill, err := endorsableSkill(endorserID, userID, skillName)
+	if err != nil {
+		return err
+	}
+
+	mutual, err := repository.AreMutualFollowers(db.DB, endorserID, userID)
+	if err != nil {
+		return err
+	}
+	if !mutual {
+		return ErrNotConnected
+	}
+
+	message := "endorsed your skill: " + skill.Name
+	notification := &model.Notification{
+		UserID:     userID,     // recipient (skill owner)
+		FromUserID: endorserID, // actor
+		Type:       repository.NotificationTypeEndorsement,
+		Message:    &message,
+		IsRead:     false,
+	}
+	return repository.EndorseSkill(db.DB, skill.ID, endorserID, notification)
+}
+
+// UnendorseSkill takes an endorsement back; it works even after unfollowing
+func UnendorseSkill(endorserID, userID uint64, skillName string) error {
+	skill, err := endorsableSkill(endorserID, userID, skillName)
+	if err != nil {
+		return err
+	}
+	return repository.UnendorseSkill(db.DB, skill.ID, endorserID)
+}
+
+func endorsableSkill(endorserID, userID uint64, skillName string) (*model.UserSkill, error) {
+	if endorserID == userID {
+		return nil, ErrEndorseYourself
+	}
+	if _, err := repository.GetUserByID(db.DB, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	skill, err := repository.GetUserSkill(db.DB, userID, model.NormalizeSkill(skillName))
+	if err != nil {
+		if errors.Is(err, repository.ErrSkillNotFound) {
+			return nil, ErrSkillNotFound
+		}
+		return nil, err
+	}
+	return skill, nil
+}
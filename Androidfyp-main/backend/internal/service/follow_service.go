@@ -1,34 +1,53 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"strconv"
 
+	"wazzafak_back/internal/apperr"
 	db "wazzafak_back/internal/database"
 	"wazzafak_back/internal/model"
 	"wazzafak_back/internal/repository"
 )
 
 var (
-	ErrFollowYourself = errors.New("you cannot follow yourself")
-	ErrFollowFailed   = errors.New("failed to follow user")
-	ErrUnfollowFailed = errors.New("failed to unfollow user")
+	ErrFollowYourself = apperr.Invalid("you cannot follow yourself")
+	ErrFollowFailed   = apperr.Internal("failed to follow user")
+	ErrUnfollowFailed = apperr.Internal("failed to unfollow user")
 )
 
-func FollowUser(followerID, followingID uint64) error {
+func FollowUser(ctx context.Context, followerID, followingID uint64) error {
 	if followerID == followingID {
 		return ErrFollowYourself
 	}
-	return repository.FollowUser(db.DB, followerID, followingID)
+
+	blocked, err := repository.IsBlockedEitherWay(db.DB, followerID, followingID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+
+	if err := repository.FollowUser(db.DB, followerID, followingID); err != nil {
+		return err
+	}
+	recordAuditEvent(ctx, followerID, followerID, model.AuditFollowed, map[string]string{"following_id": strconv.FormatUint(followingID, 10)})
+	return nil
 }
 
-func UnfollowUser(followerID, followingID uint64) error {
-	return repository.UnfollowUser(db.DB, followerID, followingID)
+func UnfollowUser(ctx context.Context, followerID, followingID uint64) error {
+	if err := repository.UnfollowUser(db.DB, followerID, followingID); err != nil {
+		return err
+	}
+	recordAuditEvent(ctx, followerID, followerID, model.AuditUnfollowed, map[string]string{"following_id": strconv.FormatUint(followingID, 10)})
+	return nil
 }
 
 func GetFollowersByUsername(username string) ([]model.User, error) {
 	user, err := repository.GetUserByUsername(db.DB, username)
 	if err != nil {
-		return nil, err
+		return nil, translateUserErr(err)
 	}
 
 	return repository.GetFollowers(db.DB, user.ID)
@@ -37,7 +56,7 @@ func GetFollowersByUsername(username string) ([]model.User, error) {
 func GetFollowingByUsername(username string) ([]model.User, error) {
 	user, err := repository.GetUserByUsername(db.DB, username)
 	if err != nil {
-		return nil, err
+		return nil, translateUserErr(err)
 	}
 
 	return repository.GetFollowing(db.DB, user.ID)
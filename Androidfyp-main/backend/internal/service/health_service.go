@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+const (
+	readinessTimeout = 2 * time.Second
+	// The mail provider is an outside API, so it is asked at most this often
+	mailCheckInterval = time.Minute
+	mailCheckTimeout  = 5 * time.Second
+)
+
+// Readiness states
+const (
+	Ready    = "ready"
+	Degraded = "degraded" // serving, but a dependency requests don't wait on is down
+	NotReady = "not_ready"
+)
+
+// Check states
+const (
+	CheckOK      = "ok"
+	CheckFailing = "failing"
+	CheckSkipped = "skipped" // the provider can't be checked, e.g. the log mailer
+	CheckPending = "pending" // the first check hasn't finished yet
+)
+
+// HealthCheck is the outcome of one dependency check
+type HealthCheck struct {
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// Readiness says whether this replica should get traffic
+type Readiness struct {
+	Status string `json:"status"`
+	// Draining is set once shutdown has begun
+	Draining bool `json:"draining"`
+	// SchemaVersion is what the database was migrated to; this build needs ExpectedSchemaVersion
+	SchemaVersion         int                    `json:"schema_version"`
+	ExpectedSchemaVersion int                    `json:"expected_schema_version"`
+	MailProvider          string                 `json:"mail_provider"`
+	Checks                map[string]HealthCheck `json:"checks"`
+}
+
+var draining atomic.Bool
+
+// StartDraining makes every later readiness check fail, so load balancers
+// stop sending requests before the server shuts down
+func StartDraining() {
+	draining.Store(true)
+}
+
+// CheckReadiness pings the database and reads the schema version; both have
+// to work. The mail provider is only reported: emails wait in the outbox
+// while it is down, so its outage makes the replica degraded, not unready.
+func CheckReadiness(ctx context.Context) Readiness {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+
+	readiness := Readiness{
+		Status:                Ready,
+		Draining:              draining.Load(),
+		ExpectedSchemaVersion: model.SchemaVersion,
+		MailProvider:          mailProvider(),
+		Checks:                map[string]HealthCheck{},
+	}
+
+	readiness.Checks["database"] = runCheck(func() error {
+		return repository.PingDB(ctx, db.DB)
+	})
+	readiness.Checks["migrations"] = runCheck(func() error {
+		version, err := repository.GetSchemaVersion(ctx, db.DB)
+		if err != nil {
+			return err
+		}
+		readiness.SchemaVersion = version
+		if version < model.SchemaVersion {
+			return fmt.Errorf("database schema is at version %d, this build needs %d", version, model.SchemaVersion)
+		}
+		return nil
+	})
+	readiness.Checks["mail"] = checkMailProvider()
+
+	switch {
+	case readiness.Draining,
+		readiness.Checks["database"].Status != CheckOK,
+		readiness.Checks["migrations"].Status != CheckOK:
+		readiness.Status = NotReady
+	case readiness.Checks["mail"].Status == CheckFailing:
+		readiness.Status = Degraded
+	}
+	return readiness
+}
+
+func runCheck(check func() error) HealthCheck {
+	started := time.Now()
+	err := check()
+	result := HealthCheck{Status: CheckOK, DurationMS: float64(time.Since(started).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = CheckFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func mailProvider() string {
+	if provider := os.Getenv("MAIL_PROVIDER"); provider != "" {
+		return provider
+	}
+	return "brevo"
+}
+
+var (
+	mailCheckMu   sync.Mutex
+	mailCheck     HealthCheck
+	mailCheckedAt time.Time
+	mailChecking  bool
+)
+
+// checkMailProvider answers with the last result and refreshes it in the
+// background once it is mailCheckInterval old, so a slow provider never
+// holds up a probe and frequent probes don't flood it with calls
+func checkMailProvider() HealthCheck {
+	checker, ok := mailSender.(mailer.Checker)
+	if !ok {
+		return HealthCheck{Status: CheckSkipped}
+	}
+
+	mailCheckMu.Lock()
+	defer mailCheckMu.Unlock()
+	if !mailChecking && time.Since(mailCheckedAt) >= mailCheckInterval {
+		mailChecking = true
+		go refreshMailCheck(checker)
+	}
+	if mailCheckedAt.IsZero() {
+		return HealthCheck{Status: CheckPending}
+	}
+	return mailCheck
+}
+
+func refreshMailCheck(checker mailer.Checker) {
+	ctx, cancel := context.WithTimeout(context.Background(), mailCheckTimeout)
+	defer cancel()
+	result := runCheck(func() error { return checker.Check(ctx) })
+
+	mailCheckMu.Lock()
+	defer mailCheckMu.Unlock()
+	mailCheck = result
+	mailCheckedAt = time.Now()
+	mailChecking = false
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// idempotencyKeyTTL is how long a response is replayed for its key
+	idempotencyKeyTTL = 24 * time.Hour
+	// idempotencyClaimLease outlasts any request (see the server's
+	// WriteTimeout); an older unfinished claim belongs to a crashed replica
+	idempotencyClaimLease    = 2 * time.Minute
+	idempotencyPurgeBatch    = 500
+	idempotencyClaimAttempts = 3
+)
+
+var (
+	ErrIdempotencyKeyInUse  = apperr.Conflict("a request with this Idempotency-Key is still being processed")
+	ErrIdempotencyKeyReused = apperr.New(apperr.CodeUnprocessable, "this Idempotency-Key was already used for a different request")
+)
+
+// BeginIdempotentRequest claims key for the user's request to route. It
+// returns the stored response when an earlier request with the same key and
+// body already completed. Otherwise it returns nil and the caller handles
+// the request, then calls FinishIdempotentRequest or ReleaseIdempotentRequest.
+func BeginIdempotentRequest(userID uint64, key, route, requestHash string) (*model.IdempotencyKey, error) {
+	for attempt := 0; attempt < idempotencyClaimAttempts; attempt++ {
+		now := time.Now()
+		claim := model.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Route:       route,
+			RequestHash: requestHash,
+			ExpiresAt:   now.Add(idempotencyKeyTTL),
+		}
+		claimed, err := repository.ClaimIdempotencyKey(db.DB, &claim)
+		if err != nil {
+			return nil, err
+		}
+		if claimed {
+			return nil, nil
+		}
+
+		stored, err := repository.GetIdempotencyKey(db.DB, userID, key, route)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // released or purged in between; claim again
+		}
+		if err != nil {
+			return nil, err
+		}
+		claimCutoff := now.Add(-idempotencyClaimLease)
+		if !stored.ExpiresAt.After(now) || (stored.Status == 0 && !stored.CreatedAt.After(claimCutoff)) {
+			if err := repository.DeleteStaleIdempotencyKey(db.DB, userID, key, route, now, claimCutoff); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if stored.RequestHash != requestHash {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if stored.Status == 0 {
+			return nil, ErrIdempotencyKeyInUse
+		}
+		return stored, nil
+	}
+	return nil, ErrIdempotencyKeyInUse
+}
+
+// FinishIdempotentRequest stores the response to replay for the key
+func FinishIdempotentRequest(userID uint64, key, route string, status int, contentType string, body []byte) error {
+	return repository.CompleteIdempotencyKey(db.DB, userID, key, route, status, contentType, body)
+}
+
+// ReleaseIdempotentRequest drops the claim of a request whose outcome should
+// not be replayed, e.g. a server error, so a retry runs again
+func ReleaseIdempotentRequest(userID uint64, key, route string) error {
+	return repository.ReleaseIdempotencyKey(db.DB, userID, key, route)
+}
+
+// RunIdempotencyKeyPurge deletes expired idempotency keys. Safe on every
+// replica. Blocks until ctx is cancelled.
+func RunIdempotencyKeyPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purgeExpiredIdempotencyKeys(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func purgeExpiredIdempotencyKeys(ctx context.Context) {
+	for ctx.Err() == nil {
+		purged, err := repository.PurgeExpiredIdempotencyKeys(db.DB, time.Now(), idempotencyPurgeBatch)
+		if err != nil {
+			slog.Error("idempotency key purge failed", "error", err)
+			return
+		}
+		if purged < idempotencyPurgeBatch {
+			return
+		}
+	}
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	maxJobFieldLength       = 150
+	maxJobDescriptionLength = 10000
+)
+
+var (
+	ErrJobNotFound         = apperr.NotFound("job not found")
+	ErrNotRecruiter        = apperr.Forbidden("only recruiter accounts can post jobs")
+	ErrInvalidJobInput     = apperr.Invalid("title, company, job_position and description are required and must fit their length limits")
+	ErrInvalidRemoteType   = apperr.Invalid("remote_type must be onsite, hybrid or remote")
+	ErrInvalidSeniority    = apperr.Invalid("seniority must be intern, junior, mid, senior or lead")
+	ErrJobClosed           = apperr.Conflict("job is closed")
+	ErrInvalidJobStatus    = apperr.Invalid("status must be open, closed or all")
+	ErrRecruiterNotChanged = apperr.Conflict("user already has this recruiter status")
+)
+
+// JobInput is what a recruiter submits when creating or editing a job
+type JobInput struct {
+	Title           string
+	Company         string
+	Location        string
+	RemoteType      string
+	Seniority       string
+	JobPosition     string
+	JobPositionType string
+	Description     string
+}
+
+// normalize trims every field and checks the required ones and the enums
+func (in JobInput) normalize() (JobInput, error) {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Company = strings.TrimSpace(in.Company)
+	in.Location = strings.TrimSpace(in.Location)
+	in.RemoteType = strings.ToLower(strings.TrimSpace(in.RemoteType))
+	in.Seniority = strings.ToLower(strings.TrimSpace(in.Seniority))
+	in.JobPosition = strings.TrimSpace(in.JobPosition)
+	in.JobPositionType = strings.TrimSpace(in.JobPositionType)
+	in.Description = strings.TrimSpace(in.Description)
+
+	if in.Title == "" || in.Company == "" || in.JobPosition == "" || in.Description == "" {
+		return in, ErrInvalidJobInput
+	}
+	for _, field := range []string{in.Title, in.Company, in.Location, in.JobPosition, in.JobPositionType} {
+		if len(field) > maxJobFieldLength {
+			return in, ErrInvalidJobInput
+		}
+	}
+	if len(in.Description) > maxJobDescriptionLength {
+		return in, ErrInvalidJobInput
+	}
+	if !containsString(model.JobRemoteTypes, in.RemoteType) {
+		return in, ErrInvalidRemoteType
+	}
+	if !containsString(model.JobSeniorities, in.Seniority) {
+		return in, ErrInvalidSeniority
+	}
+	return in, nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// JobView is a job as shown to a viewer
+type JobView struct {
+	ID               string `json:"id"`
+	RecruiterID      string `json:"recruiter_id"`
+	CompanyID        string `json:"company_id,omitempty"`
+	PostID           string `json:"post_id,omitempty"`
+	Title            string `json:"title"`
+	Company          string `json:"company"`
+	Location         string `json:"location"`
+	RemoteType       string `json:"remote_type"`
+	Seniority        string `json:"seniority"`
+	JobPosition      string `json:"job_position"`
+	JobPositionType  string `json:"job_position_type"`
+	Description      string `json:"description"`
+	Status           string `json:"status"`
+	ClosedAt         string `json:"closed_at,omitempty"`
+	CreatedAt        string `json:"created_at"`
+	IsSaved          bool   `json:"is_saved"`
+	IsOwner          bool   `json:"is_owner"`
+	ViewerRole       string `json:"viewer_role,omitempty"`       // owner, recruiter or viewer when the viewer can manage the job
+	ApplicationID    string `json:"application_id,omitempty"`    // the viewer's application, if any
+	ApplicationStage string `json:"application_stage,omitempty"` // its current stage
+}
+
+func buildJobView(job model.Job, viewerID uint64) JobView {
+	view := JobView{
+		ID:              strconv.FormatUint(job.ID, 10),
+		RecruiterID:     strconv.FormatUint(job.RecruiterID, 10),
+		Title:           job.Title,
+		Company:         job.Company,
+		Location:        job.Location,
+		RemoteType:      job.RemoteType,
+		Seniority:       job.Seniority,
+		JobPosition:     job.JobPosition,
+		JobPositionType: job.JobPositionType,
+		Description:     job.Description,
+		Status:          job.Status,
+		CreatedAt:       job.CreatedAt.Format(time.RFC3339),
+		IsOwner:         job.RecruiterID == viewerID,
+	}
+	if job.CompanyID != nil {
+		view.CompanyID = strconv.FormatUint(*job.CompanyID, 10)
+	}
+	if job.PostID != nil {
+		view.PostID = strconv.FormatUint(*job.PostID, 10)
+	}
+	if job.ClosedAt != nil {
+		view.ClosedAt = job.ClosedAt.Format(time.RFC3339)
+	}
+	view.IsSaved, _ = repository.IsJobSaved(db.DB, viewerID, job.ID)
+	view.ViewerRole, _ = repository.GetJobRole(db.DB, &job, viewerID)
+	if view.ViewerRole == "" {
+		if application, err := repository.GetApplicationByJobAndUser(db.DB, job.ID, viewerID); err == nil {
+			view.ApplicationID = strconv.FormatUint(application.ID, 10)
+			view.ApplicationStage = application.Stage
+		}
+	}
+	return view
+}
+
+type JobPage struct {
+	Jobs       []JobView `json:"jobs"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+}
+
+// =================== Create a personal job (recruiter accounts only) ===================
+func CreateJob(recruiterID uint64, input JobInput) (*JobView, error) {
+	user, err := repository.GetUserByID(db.DB, recruiterID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.IsRecruiter {
+		return nil, ErrNotRecruiter
+	}
+	return createJob(recruiterID, nil, input)
+}
+
+// =================== Create a company job ===================
+// Access is checked by middleware.RequireCompanyRole; the company name is
+// always the page's own.
+func CreateCompanyJob(companyID, recruiterID uint64, input JobInput) (*JobView, error) {
+	company, err := repository.GetCompanyByID(db.DB, companyID)
+	if err != nil {
+		if errors.Is(err, repository.ErrCompanyNotFound) {
+			return nil, ErrCompanyNotFound
+		}
+		return nil, err
+	}
+	input.Company = company.Name
+	return createJob(recruiterID, &companyID, input)
+}
+
+func createJob(recruiterID uint64, companyID *uint64, input JobInput) (*JobView, error) {
+	input, err := input.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	job := model.NewJob(recruiterID, input.Title, input.Company, input.Location, input.RemoteType,
+		input.Seniority, input.JobPosition, input.JobPositionType, input.Description)
+	job.CompanyID = companyID
+
+	post := model.NewPost_structure(recruiterID, "", job.FeedText())
+	post.Kind = model.PostKindJob
+
+	if err := repository.CreateJob(db.DB, &job, &post); err != nil {
+		return nil, err
+	}
+
+	view := buildJobView(job, recruiterID)
+	return &view, nil
+}
+
+// =================== Edit an open job ===================
+// Access is checked by middleware.RequireJobRole.
+func UpdateJob(jobID, userID uint64, input JobInput) (*JobView, error) {
+	job, err := getJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobStatusOpen {
+		return nil, ErrJobClosed
+	}
+	if job.CompanyID != nil {
+		input.Company = job.Company
+	}
+
+	input, err = input.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	job.Title = input.Title
+	job.Company = input.Company
+	job.Location = input.Location
+	job.RemoteType = input.RemoteType
+	job.Seniority = input.Seniority
+	job.JobPosition = input.JobPosition
+	job.JobPositionType = input.JobPositionType
+	job.Description = input.Description
+
+	if err := repository.UpdateJob(db.DB, job); err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+
+	view := buildJobView(*job, userID)
+	return &view, nil
+}
+
+// =================== Close a job ===================
+// Access is checked by middleware.RequireJobRole.
+func CloseJob(jobID, userID uint64) (*JobView, error) {
+	if _, err := getJob(jobID); err != nil {
+		return nil, err
+	}
+
+	if err := repository.CloseJob(db.DB, jobID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return nil, ErrJobClosed
+		}
+		return nil, err
+	}
+	return GetJob(jobID, userID)
+}
+
+func getJob(jobID uint64) (*model.Job, error) {
+	job, err := repository.GetJobByID(db.DB, jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// =================== Get a job ===================
+func GetJob(jobID, viewerID uint64) (*JobView, error) {
+	job, err := getJob(jobID)
+	if err != nil {
+		return nil, err
+	}
+	view := buildJobView(*job, viewerID)
+	return &view, nil
+}
+
+// GetJobForPost returns the job behind a "job" feed post
+func GetJobForPost(postID, viewerID uint64) (*JobView, error) {
+	job, err := repository.GetJobByPostID(db.DB, postID)
+	if err != nil {
+		return nil, err
+	}
+	view := buildJobView(*job, viewerID)
+	return &view, nil
+}
+
+// =================== Search jobs ===================
+// An empty status lists open jobs; "all" lists every job.
+func SearchJobs(filter repository.JobFilter, viewerID uint64, cursorToken string, limit int) (*JobPage, error) {
+	switch filter.Status {
+	case "":
+		filter.Status = model.JobStatusOpen
+	case "all":
+		filter.Status = ""
+	case model.JobStatusOpen, model.JobStatusClosed:
+	default:
+		return nil, ErrInvalidJobStatus
+	}
+
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	jobs, err := repository.SearchJobs(db.DB, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &JobPage{Jobs: []JobView{}}
+	for _, job := range jobs {
+		page.Jobs = append(page.Jobs, buildJobView(job, viewerID))
+	}
+	if len(jobs) == limit {
+		last := jobs[len(jobs)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// =================== Saved jobs ===================
+func SaveJob(userID, jobID uint64) error {
+	if _, err := repository.GetJobByID(db.DB, jobID); err != nil {
+		if errors.Is(err, repository.ErrJobNotFound) {
+			return ErrJobNotFound
+		}
+		return err
+	}
+	return repository.SaveJob(db.DB, userID, jobID)
+}
+
+func UnsaveJob(userID, jobID uint64) error {
+	return repository.UnsaveJob(db.DB, userID, jobID)
+}
+
+func GetSavedJobs(userID uint64, cursorToken string, limit int) (*JobPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	entries, err := repository.GetSavedJobs(db.DB, userID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &JobPage{Jobs: []JobView{}}
+	for _, entry := range entries {
+		page.Jobs = append(page.Jobs, buildJobView(entry.Job, userID))
+	}
+	if len(entries) == limit {
+		last := entries[len(entries)-1]
+		page.NextCursor = encodeCursor(last.SavedAt, last.ID)
+	}
+	return page, nil
+}
+
+// =================== Recruiter accounts (admins only) ===================
+func SetRecruiter(adminID, userID uint64, isRecruiter bool) error {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if user.IsRecruiter == isRecruiter {
+		return ErrRecruiterNotChanged
+	}
+
+	action := model.ModerationGrantRecruiter
+	if !isRecruiter {
+		action = model.ModerationRevokeRecruiter
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.SetRecruiter(tx, userID, isRecruiter); err != nil {
+			return err
+		}
+		entry := model.NewModerationAction(adminID, action, model.ReportTargetUser, userID, nil, "")
+		return repository.CreateModerationAction(tx, &entry)
+	})
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"math"
 	"time"
+	"wazzafak_back/internal/apperr"
 	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
 	"wazzafak_back/internal/repository"
 
 	"strconv"
@@ -14,11 +18,40 @@ import (
 
 var jwtSecret = []byte("your-256-bit-secret") // TODO: load from env/config
 
+const (
+	// challengeTokenType marks the short-lived token handed out between the
+	// password and the 2FA code. AuthMiddleware refuses any token with a "typ".
+	challengeTokenType = "2fa_challenge"
+	challengeTokenTTL  = 5 * time.Minute
+)
+
+var ErrAccountSuspended = apperr.Forbidden("account suspended")
+
+// LoginResult is either a session token or, for 2FA accounts, a challenge
+// token to exchange for one together with a code
+type LoginResult struct {
+	Token             string
+	ChallengeToken    string
+	TwoFactorRequired bool
+}
+
+// issuedAtClaim is the iat of a new token. It keeps microseconds, so a token
+// issued earlier in the same second as a password change is still revoked.
+func issuedAtClaim(t time.Time) float64 {
+	return float64(t.UnixMicro()) / 1e6
+}
+
+// TokenIssuedAt reads the iat claim of a parsed token
+func TokenIssuedAt(claims jwt.MapClaims) time.Time {
+	issuedAt, _ := claims["iat"].(float64)
+	return time.UnixMicro(int64(math.Round(issuedAt * 1e6)))
+}
+
 // GenerateJWT creates a JWT token for a user ID
 func GenerateJWT(userID string) (string, error) {
 	claims := jwt.MapClaims{
 		"sub": userID,
-		"iat": time.Now().Unix(),
+		"iat": issuedAtClaim(time.Now()),
 		"exp": time.Now().Add(72 * time.Hour).Unix(),
 	}
 
@@ -26,23 +59,89 @@ func GenerateJWT(userID string) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
-// Login authenticates the user and returns a JWT token
-func Login(email, password string) (string, error) {
+// generateChallengeToken creates the interim token of a two-step login
+func generateChallengeToken(userID uint64) (string, error) {
+	claims := jwt.MapClaims{
+		"sub": strconv.FormatUint(userID, 10),
+		"typ": challengeTokenType,
+		"iat": issuedAtClaim(time.Now()),
+		"exp": time.Now().Add(challengeTokenTTL).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
+
+// ChallengeUserID is the account a challenge token was issued to; /login/2fa
+// is rate limited per account with it
+func ChallengeUserID(tokenString string) (uint64, bool) {
+	userID, _, err := parseChallengeToken(tokenString)
+	return userID, err == nil
+}
+
+// parseChallengeToken returns the user ID and issue time of a valid challenge token
+func parseChallengeToken(tokenString string) (uint64, time.Time, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return 0, time.Time{}, ErrInvalidChallenge
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != challengeTokenType {
+		return 0, time.Time{}, ErrInvalidChallenge
+	}
+	subject, _ := claims["sub"].(string)
+	userID, err := strconv.ParseUint(subject, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, ErrInvalidChallenge
+	}
+	return userID, TokenIssuedAt(claims), nil
+}
+
+// Login authenticates the user and returns a JWT token, or a challenge token
+// when the account has two-factor authentication enabled. Accounts with 2FA
+// are audited as signed in by CompleteTwoFactorLogin.
+func Login(ctx context.Context, email, password string) (*LoginResult, error) {
 	user, err := repository.GetUserByEmail(db.DB, email)
 	if err != nil {
-		return "", errors.New("invalid email")
+		recordAuditEvent(ctx, 0, 0, model.AuditLoginFailed, map[string]string{"reason": "unknown_email"})
+		return nil, apperr.Unauthenticated("invalid email")
 	}
 
 	if !checkPassword(password, user.Password) {
-		return "", errors.New("invalid password")
+		recordAuditEvent(ctx, user.ID, user.ID, model.AuditLoginFailed, map[string]string{"reason": "wrong_password"})
+		return nil, apperr.Unauthenticated("invalid password")
+	}
+
+	if user.IsSuspended(time.Now()) {
+		recordAuditEvent(ctx, user.ID, user.ID, model.AuditLoginFailed, map[string]string{"reason": "suspended"})
+		return nil, ErrAccountSuspended
+	}
+
+	twoFactor, err := repository.GetTwoFactor(db.DB, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrTwoFactorNotFound) {
+		return nil, err
+	}
+	if twoFactor != nil && twoFactor.IsEnabled() {
+		challenge, err := generateChallengeToken(user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{ChallengeToken: challenge, TwoFactorRequired: true}, nil
 	}
 
 	token, err := GenerateJWT(strconv.FormatUint(user.ID, 10))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return token, nil
+	recordAuditEvent(ctx, user.ID, user.ID, model.AuditLoginSucceeded, map[string]string{"method": "password"})
+	return &LoginResult{Token: token}, nil
 }
 
 func checkPassword(inputPassword, storedHash string) bool {
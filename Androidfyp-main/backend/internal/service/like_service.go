@@ -2,18 +2,26 @@ package service
 
 import (
 	"errors"
+
+	"wazzafak_back/internal/apperr"
 	db "wazzafak_back/internal/database"
 	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
 )
 
 var (
-	ErrPostAlreadyLiked = errors.New("post already liked")
-	ErrLikeNotFound     = errors.New("like not found")
+	ErrPostAlreadyLiked = apperr.Conflict("post already liked")
+	ErrLikeNotFound     = apperr.NotFound("like not found")
 )
 
 // ✅ Add a like
 func LikePost(userID, postID uint64) error {
-	return repository.AddLike(db.DB, userID, postID)
+	err := repository.AddLike(db.DB, userID, postID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPostNotFound
+	}
+	return apperr.Unique(err, ErrPostAlreadyLiked)
 }
 
 // ✅ Remove a like
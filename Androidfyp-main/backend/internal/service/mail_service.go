@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const (
+	outboxBatchSize   = 5
+	outboxSendTimeout = 30 * time.Second
+	// outboxLease is how long a claimed email is left alone. It outlasts
+	// sending the whole batch, or another replica would send the rest again.
+	outboxLease        = outboxBatchSize*outboxSendTimeout + time.Minute
+	maxMailAttempts    = 8
+	mailRetryBaseDelay = 30 * time.Second
+	mailRetryMaxDelay  = time.Hour
+)
+
+var ErrOutboxEmailNotFound = apperr.NotFound("dead email not found")
+
+var (
+	mailSender mailer.Mailer
+	// outboxWake lets a request that just queued an email skip the wait for
+	// the next tick
+	outboxWake = make(chan struct{}, 1)
+)
+
+// InitMailer picks the mail provider from the environment (see mailer.FromEnv)
+func InitMailer() error {
+	sender, err := mailer.FromEnv()
+	if err != nil {
+		return err
+	}
+	mailSender = sender
+	return nil
+}
+
+// enqueueEmail renders a template into the outbox inside tx. Call
+// wakeMailOutbox once tx has committed.
+func enqueueEmail(tx *gorm.DB, to, template string, data interface{}) error {
+	msg, err := mailer.Render(template, to, data)
+	if err != nil {
+		return err
+	}
+	email := model.NewOutboxEmail(msg.To, template, msg.Subject, msg.HTML, msg.Text)
+	return repository.EnqueueEmail(tx, &email)
+}
+
+func wakeMailOutbox() {
+	select {
+	case outboxWake <- struct{}{}:
+	default:
+	}
+}
+
+// RunMailOutbox delivers queued emails, retrying failures with exponential
+// backoff and dead-lettering them after maxMailAttempts. Like the post
+// scheduler it is safe on every replica. Blocks until ctx is cancelled.
+func RunMailOutbox(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deliverDueEmails(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-outboxWake:
+		}
+	}
+}
+
+func deliverDueEmails(ctx context.Context) {
+	for {
+		emails, err := repository.ClaimDueEmails(db.DB, time.Now(), outboxLease, outboxBatchSize)
+		if err != nil {
+			slog.Error("mail outbox failed", "error", err)
+			return
+		}
+		for _, email := range emails {
+			deliverEmail(ctx, email)
+		}
+		if len(emails) < outboxBatchSize || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+func deliverEmail(ctx context.Context, email model.OutboxEmail) {
+	sendCtx, cancel := context.WithTimeout(ctx, outboxSendTimeout)
+	defer cancel()
+
+	err := mailSender.Send(sendCtx, mailer.Message{
+		To:      email.ToEmail,
+		Subject: email.Subject,
+		HTML:    email.HTMLBody,
+		Text:    email.TextBody,
+	})
+	if err == nil {
+		if err := repository.MarkEmailSent(db.DB, email.ID, time.Now()); err != nil {
+			slog.Error("mail outbox: could not mark email sent", "email_id", email.ID, "error", err)
+		}
+		return
+	}
+
+	attempts := email.Attempts + 1
+	var next *time.Time
+	if attempts < maxMailAttempts {
+		at := time.Now().Add(mailRetryDelay(attempts))
+		next = &at
+	} else {
+		slog.Error("mail outbox: giving up", "template", email.Template, "email_id", email.ID, "to", email.ToEmail, "error", err)
+	}
+	if err := repository.MarkEmailFailed(db.DB, email.ID, attempts, next, err.Error()); err != nil {
+		slog.Error("mail outbox: could not mark email failed", "email_id", email.ID, "error", err)
+	}
+}
+
+// mailRetryDelay doubles from mailRetryBaseDelay up to mailRetryMaxDelay
+func mailRetryDelay(attempts int) time.Duration {
+	delay := mailRetryBaseDelay
+	for i := 1; i < attempts && delay < mailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > mailRetryMaxDelay {
+		delay = mailRetryMaxDelay
+	}
+	return delay
+}
+
+// =================== Dead letters (admins only) ===================
+type OutboxEmailView struct {
+	ID        string `json:"id"`
+	ToEmail   string `json:"to_email"`
+	Template  string `json:"template"`
+	Subject   string `json:"subject"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+	CreatedAt string `json:"created_at"`
+}
+
+type OutboxEmailPage struct {
+	Emails     []OutboxEmailView `json:"emails"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+func GetDeadEmails(cursorToken string, limit int) (*OutboxEmailPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	emails, err := repository.GetDeadEmails(db.DB, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &OutboxEmailPage{Emails: []OutboxEmailView{}}
+	for _, email := range emails {
+		page.Emails = append(page.Emails, OutboxEmailView{
+			ID:        strconv.FormatUint(email.ID, 10),
+			ToEmail:   email.ToEmail,
+			Template:  email.Template,
+			Subject:   email.Subject,
+			Attempts:  email.Attempts,
+			LastError: email.LastError,
+			CreatedAt: email.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	if len(emails) == limit {
+		last := emails[len(emails)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// RetryDeadEmail puts a dead-lettered email back in the queue
+func RetryDeadEmail(emailID uint64) error {
+	if err := repository.RequeueEmail(db.DB, emailID, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrOutboxEmailNotFound) {
+			return ErrOutboxEmailNotFound
+		}
+		return err
+	}
+	wakeMailOutbox()
+	return nil
+}
@@ -0,0 +1,511 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/realtime"
+	"wazzafak_back/internal/repository"
+)
+
+const maxMessageLength = 4000
+
+var (
+	ErrConversationNotFound  = apperr.NotFound("conversation not found")
+	ErrInvalidConversation   = apperr.Invalid("a conversation needs between 1 and 9 other members")
+	ErrCannotMessage         = apperr.Forbidden("you can't message this user")
+	ErrEmptyMessage          = apperr.Invalid("message cannot be empty")
+	ErrMessageTooLong        = apperr.Invalid("message is too long")
+	ErrNoMessageRequest      = apperr.Conflict("there is no pending message request")
+	ErrNotAGroup             = apperr.Invalid("only group conversations can be left")
+	ErrInvalidReadMarker     = apperr.Invalid("message does not belong to this conversation")
+	ErrConversationNotActive = apperr.Forbidden("accept the message request first")
+)
+
+// MessageView is a message as sent to the app
+type MessageView struct {
+	ID             string `json:"id"`
+	ConversationID string `json:"conversation_id"`
+	SenderID       string `json:"sender_id"`
+	Content        string `json:"content"`
+	CreatedAt      string `json:"created_at"`
+}
+
+func newMessageView(m model.Message) MessageView {
+	return MessageView{
+		ID:             strconv.FormatUint(m.ID, 10),
+		ConversationID: strconv.FormatUint(m.ConversationID, 10),
+		SenderID:       strconv.FormatUint(m.SenderID, 10),
+		Content:        m.Content,
+		CreatedAt:      m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// ConversationMemberView is a member with their read receipt
+type ConversationMemberView struct {
+	UserID            string `json:"user_id"`
+	Name              string `json:"name"`
+	Username          string `json:"username"`
+	PhotoURL          string `json:"photo_url"`
+	Status            string `json:"status"`
+	LastReadMessageID string `json:"last_read_message_id,omitempty"`
+}
+
+// ConversationView is a conversation as listed in the inbox
+type ConversationView struct {
+	ID            string                   `json:"id"`
+	IsGroup       bool                     `json:"is_group"`
+	Title         string                   `json:"title,omitempty"`
+	MyStatus      string                   `json:"my_status"`
+	UnreadCount   int64                    `json:"unread_count"`
+	LastMessage   *MessageView             `json:"last_message,omitempty"`
+	LastMessageAt string                   `json:"last_message_at"`
+	Members       []ConversationMemberView `json:"members"`
+}
+
+type ConversationPage struct {
+	Conversations []ConversationView `json:"conversations"`
+	NextCursor    string             `json:"next_cursor,omitempty"`
+}
+
+type MessagePage struct {
+	Messages   []MessageView `json:"messages"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// UnreadMessageBadges feed the messages tab badge
+type UnreadMessageBadges struct {
+	UnreadConversations int64 `json:"unread_conversations"`
+	UnreadMessages      int64 `json:"unread_messages"`
+	MessageRequests     int64 `json:"message_requests"`
+}
+
+// =================== Start a conversation ===================
+// With one other member this returns the existing 1:1 conversation if there is
+// one. Members who don't follow each other with the creator get a message
+// request instead of a normal conversation.
+func StartConversation(userID uint64, memberIDs []uint64, title, firstMessage string) (*ConversationView, error) {
+	others := make([]uint64, 0, len(memberIDs))
+	seen := map[uint64]bool{userID: true}
+	for _, id := range memberIDs {
+		if !seen[id] {
+			seen[id] = true
+			others = append(others, id)
+		}
+	}
+	if len(others) == 0 || len(others) > model.MaxGroupMembers-1 {
+		return nil, ErrInvalidConversation
+	}
+
+	for _, otherID := range others {
+		if _, err := repository.GetUserByID(db.DB, otherID); err != nil {
+			return nil, ErrUserNotFound
+		}
+		blocked, err := repository.IsBlockedEitherWay(db.DB, userID, otherID)
+		if err != nil {
+			return nil, err
+		}
+		if blocked {
+			return nil, ErrCannotMessage
+		}
+	}
+
+	isGroup := len(others) > 1
+	var conversation *model.Conversation
+
+	if !isGroup {
+		existing, err := repository.GetDirectConversation(db.DB, model.DirectConversationKey(userID, others[0]))
+		if err != nil && !errors.Is(err, repository.ErrConversationNotFound) {
+			return nil, err
+		}
+		conversation = existing
+
+		// Writing to someone again reopens a request you had declined
+		if existing != nil {
+			me, err := repository.GetConversationMember(db.DB, existing.ID, userID)
+			if err != nil {
+				return nil, err
+			}
+			if me.Status == model.MemberStatusDeclined {
+				if err := repository.SetMemberStatus(db.DB, existing.ID, userID, model.MemberStatusActive); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if conversation == nil {
+		created := model.NewConversation(userID, isGroup, strings.TrimSpace(title))
+		if !isGroup {
+			key := model.DirectConversationKey(userID, others[0])
+			created.DirectKey = &key
+			created.Title = ""
+		}
+
+		members := []model.ConversationMember{{ConversationID: created.ID, UserID: userID, Status: model.MemberStatusActive}}
+		for _, otherID := range others {
+			mutual, err := repository.AreMutualFollowers(db.DB, userID, otherID)
+			if err != nil {
+				return nil, err
+			}
+			status := model.MemberStatusRequest
+			if mutual {
+				status = model.MemberStatusActive
+			}
+			members = append(members, model.ConversationMember{ConversationID: created.ID, UserID: otherID, Status: status})
+		}
+
+		if err := repository.CreateConversation(db.DB, &created, members); err != nil {
+			return nil, err
+		}
+		conversation = &created
+	}
+
+	if strings.TrimSpace(firstMessage) != "" {
+		if _, err := SendMessage(userID, conversation.ID, firstMessage); err != nil {
+			return nil, err
+		}
+	}
+
+	return GetConversation(userID, conversation.ID)
+}
+
+// =================== Get one conversation ===================
+func GetConversation(userID, conversationID uint64) (*ConversationView, error) {
+	if _, err := visibleMembership(userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	summary, err := repository.GetUserConversation(db.DB, userID, conversationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrConversationNotFound) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	return buildConversationView(*summary)
+}
+
+// =================== List conversations / message requests ===================
+func ListConversations(userID uint64, requests bool, cursorToken string, limit int) (*ConversationPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	status := model.MemberStatusActive
+	if requests {
+		status = model.MemberStatusRequest
+	}
+
+	summaries, err := repository.GetUserConversations(db.DB, userID, status, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ConversationPage{Conversations: []ConversationView{}}
+	for _, summary := range summaries {
+		view, err := buildConversationView(summary)
+		if err != nil {
+			return nil, err
+		}
+		page.Conversations = append(page.Conversations, *view)
+	}
+	if len(summaries) == limit {
+		last := summaries[len(summaries)-1]
+		page.NextCursor = encodeCursor(last.LastMessageAt, last.ID)
+	}
+	return page, nil
+}
+
+func buildConversationView(summary repository.ConversationSummary) (*ConversationView, error) {
+	view := &ConversationView{
+		ID:            strconv.FormatUint(summary.ID, 10),
+		IsGroup:       summary.IsGroup,
+		Title:         summary.Title,
+		MyStatus:      summary.MemberStatus,
+		UnreadCount:   summary.UnreadCount,
+		LastMessageAt: summary.LastMessageAt.Format(time.RFC3339),
+		Members:       []ConversationMemberView{},
+	}
+
+	if summary.LastMessageID != nil {
+		view.LastMessage = &MessageView{
+			ID:             strconv.FormatUint(*summary.LastMessageID, 10),
+			ConversationID: view.ID,
+			SenderID:       strconv.FormatUint(*summary.LastMessageSenderID, 10),
+			Content:        *summary.LastMessageContent,
+			CreatedAt:      view.LastMessageAt,
+		}
+	}
+
+	members, err := repository.GetConversationMembers(db.DB, summary.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		member := ConversationMemberView{
+			UserID:   strconv.FormatUint(m.UserID, 10),
+			Name:     m.Name,
+			Username: m.Username,
+			PhotoURL: m.PhotoURL,
+			Status:   m.Status,
+		}
+		if m.LastReadMessageID != 0 {
+			member.LastReadMessageID = strconv.FormatUint(m.LastReadMessageID, 10)
+		}
+		view.Members = append(view.Members, member)
+	}
+	return view, nil
+}
+
+// =================== Message history ===================
+func GetMessages(userID, conversationID uint64, cursorToken string, limit int) (*MessagePage, error) {
+	if _, err := visibleMembership(userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	messages, err := repository.GetMessages(db.DB, conversationID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MessagePage{Messages: []MessageView{}}
+	for _, m := range messages {
+		page.Messages = append(page.Messages, newMessageView(m))
+	}
+	if len(messages) == limit {
+		last := messages[len(messages)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// =================== Send a message ===================
+// Replying to a message request accepts it.
+func SendMessage(userID, conversationID uint64, content string) (*MessageView, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, ErrEmptyMessage
+	}
+	if len(content) > maxMessageLength {
+		return nil, ErrMessageTooLong
+	}
+
+	me, err := visibleMembership(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := repository.GetConversationMembers(db.DB, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := repository.GetConversationByID(db.DB, conversationID)
+	if err != nil {
+		return nil, ErrConversationNotFound
+	}
+
+	// In a 1:1 chat a block or a declined request closes the conversation
+	if !conversation.IsGroup {
+		for _, m := range members {
+			if m.UserID == userID {
+				continue
+			}
+			if m.Status == model.MemberStatusDeclined {
+				return nil, ErrCannotMessage
+			}
+			blocked, err := repository.IsBlockedEitherWay(db.DB, userID, m.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if blocked {
+				return nil, ErrCannotMessage
+			}
+		}
+	}
+
+	if me.Status == model.MemberStatusRequest {
+		if err := repository.SetMemberStatus(db.DB, conversationID, userID, model.MemberStatusActive); err != nil {
+			return nil, err
+		}
+	}
+
+	message := model.NewMessage(conversationID, userID, content)
+	if err := repository.CreateMessage(db.DB, &message); err != nil {
+		return nil, err
+	}
+
+	view := newMessageView(message)
+	var recipients, requested []uint64
+	for _, m := range members {
+		switch m.Status {
+		case model.MemberStatusActive:
+			recipients = append(recipients, m.UserID)
+		case model.MemberStatusRequest:
+			if m.UserID == userID {
+				recipients = append(recipients, m.UserID)
+			} else {
+				requested = append(requested, m.UserID)
+			}
+		}
+	}
+	realtime.Default.Publish(recipients, realtime.Event{
+		Type:           realtime.EventMessage,
+		ConversationID: view.ConversationID,
+		Message:        view,
+	})
+	realtime.Default.Publish(requested, realtime.Event{
+		Type:           realtime.EventRequest,
+		ConversationID: view.ConversationID,
+		Message:        view,
+	})
+
+	return &view, nil
+}
+
+// =================== Read receipts ===================
+func MarkConversationRead(userID, conversationID, messageID uint64) error {
+	me, err := visibleMembership(userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	ok, err := repository.MessageInConversation(db.DB, conversationID, messageID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidReadMarker
+	}
+
+	advanced, err := repository.MarkConversationRead(db.DB, conversationID, userID, messageID, time.Now())
+	if err != nil || !advanced {
+		return err
+	}
+
+	// Reading a message request doesn't tell the sender it was seen
+	if me.Status != model.MemberStatusActive {
+		return nil
+	}
+	return publishToOthers(userID, conversationID, realtime.Event{
+		Type:           realtime.EventRead,
+		ConversationID: strconv.FormatUint(conversationID, 10),
+		UserID:         strconv.FormatUint(userID, 10),
+		MessageID:      strconv.FormatUint(messageID, 10),
+	})
+}
+
+// =================== Typing indicator ===================
+func SendTyping(userID, conversationID uint64) error {
+	me, err := visibleMembership(userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if me.Status != model.MemberStatusActive {
+		return ErrConversationNotActive
+	}
+
+	return publishToOthers(userID, conversationID, realtime.Event{
+		Type:           realtime.EventTyping,
+		ConversationID: strconv.FormatUint(conversationID, 10),
+		UserID:         strconv.FormatUint(userID, 10),
+	})
+}
+
+// =================== Message requests ===================
+func AcceptMessageRequest(userID, conversationID uint64) error {
+	return answerMessageRequest(userID, conversationID, model.MemberStatusActive)
+}
+
+func DeclineMessageRequest(userID, conversationID uint64) error {
+	return answerMessageRequest(userID, conversationID, model.MemberStatusDeclined)
+}
+
+func answerMessageRequest(userID, conversationID uint64, status string) error {
+	me, err := repository.GetConversationMember(db.DB, conversationID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotConversationMember) {
+			return ErrConversationNotFound
+		}
+		return err
+	}
+	if me.Status != model.MemberStatusRequest {
+		return ErrNoMessageRequest
+	}
+	return repository.SetMemberStatus(db.DB, conversationID, userID, status)
+}
+
+// =================== Leave a group ===================
+func LeaveConversation(userID, conversationID uint64) error {
+	if _, err := visibleMembership(userID, conversationID); err != nil {
+		return err
+	}
+
+	conversation, err := repository.GetConversationByID(db.DB, conversationID)
+	if err != nil {
+		return ErrConversationNotFound
+	}
+	if !conversation.IsGroup {
+		return ErrNotAGroup
+	}
+	return repository.SetMemberStatus(db.DB, conversationID, userID, model.MemberStatusLeft)
+}
+
+// =================== Unread badges ===================
+func GetUnreadMessageBadges(userID uint64) (*UnreadMessageBadges, error) {
+	counts, err := repository.GetUnreadMessageCounts(db.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &UnreadMessageBadges{
+		UnreadConversations: counts.UnreadConversations,
+		UnreadMessages:      counts.UnreadMessages,
+		MessageRequests:     counts.MessageRequests,
+	}, nil
+}
+
+// visibleMembership returns the user's membership unless they left or declined
+func visibleMembership(userID, conversationID uint64) (*model.ConversationMember, error) {
+	me, err := repository.GetConversationMember(db.DB, conversationID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotConversationMember) {
+			return nil, ErrConversationNotFound
+		}
+		return nil, err
+	}
+	if me.Status != model.MemberStatusActive && me.Status != model.MemberStatusRequest {
+		return nil, ErrConversationNotFound
+	}
+	return me, nil
+}
+
+// publishToOthers sends an event to the other active members of a conversation
+func publishToOthers(userID, conversationID uint64, event realtime.Event) error {
+	members, err := repository.GetConversationMembers(db.DB, conversationID)
+	if err != nil {
+		return err
+	}
+
+	var recipients []uint64
+	for _, m := range members {
+		if m.UserID != userID && m.Status == model.MemberStatusActive {
+			recipients = append(recipients, m.UserID)
+		}
+	}
+	realtime.Default.Publish(recipients, event)
+	return nil
+}
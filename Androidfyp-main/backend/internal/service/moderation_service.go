@@ -0,0 +1,348 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/realtime"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidReportTarget = apperr.Invalid("target type must be post, comment or user")
+	ErrInvalidReportReason = apperr.Invalid("invalid report reason")
+	ErrReportTargetMissing = apperr.NotFound("reported content not found")
+	ErrReportYourself      = apperr.Invalid("you cannot report yourself")
+	ErrAlreadyReported     = apperr.Conflict("you have already reported this")
+	ErrReportNotFound      = apperr.NotFound("report not found")
+	ErrUserNotFound        = apperr.NotFound("user not found")
+	ErrCannotSuspendAdmin  = apperr.Forbidden("admins cannot be suspended")
+	ErrInvalidModeration   = apperr.Invalid("invalid moderation action")
+	ErrNothingToModerate   = apperr.NotFound("content is not in a state this action applies to")
+)
+
+const maxReportDetailsLength = 1000
+
+type ReportView struct {
+	ID         string `json:"id"`
+	ReporterID string `json:"reporter_id"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	Reason     string `json:"reason"`
+	Details    string `json:"details"`
+	Status     string `json:"status"`
+	ResolvedBy string `json:"resolved_by,omitempty"`
+	ResolvedAt string `json:"resolved_at,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func buildReportView(report model.Report) ReportView {
+	view := ReportView{
+		ID:         strconv.FormatUint(report.ID, 10),
+		ReporterID: strconv.FormatUint(report.ReporterID, 10),
+		TargetType: report.TargetType,
+		TargetID:   strconv.FormatUint(report.TargetID, 10),
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     report.Status,
+		CreatedAt:  report.CreatedAt.Format(time.RFC3339),
+	}
+	if report.ResolvedBy != nil {
+		view.ResolvedBy = strconv.FormatUint(*report.ResolvedBy, 10)
+	}
+	if report.ResolvedAt != nil {
+		view.ResolvedAt = report.ResolvedAt.Format(time.RFC3339)
+	}
+	return view
+}
+
+// ReportPage is one page of the moderation queue
+type ReportPage struct {
+	Reports    []ReportView `json:"reports"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+type ModerationActionView struct {
+	ID         string `json:"id"`
+	AdminID    string `json:"admin_id"`
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	ReportID   string `json:"report_id,omitempty"`
+	Reason     string `json:"reason"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func buildModerationActionView(action model.ModerationAction) ModerationActionView {
+	view := ModerationActionView{
+		ID:         strconv.FormatUint(action.ID, 10),
+		AdminID:    strconv.FormatUint(action.AdminID, 10),
+		Action:     action.Action,
+		TargetType: action.TargetType,
+		TargetID:   strconv.FormatUint(action.TargetID, 10),
+		Reason:     action.Reason,
+		CreatedAt:  action.CreatedAt.Format(time.RFC3339),
+	}
+	if action.ReportID != nil {
+		view.ReportID = strconv.FormatUint(*action.ReportID, 10)
+	}
+	return view
+}
+
+// ModerationLogPage is one page of the moderation audit log
+type ModerationLogPage struct {
+	Actions    []ModerationActionView `json:"actions"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+// =================== Report content ===================
+func ReportContent(reporterID uint64, targetType string, targetID uint64, reason, details string) (*model.Report, error) {
+	if !isValidReportReason(reason) {
+		return nil, ErrInvalidReportReason
+	}
+
+	details = strings.TrimSpace(details)
+	if utf8.RuneCountInString(details) > maxReportDetailsLength {
+		details = string([]rune(details)[:maxReportDetailsLength])
+	}
+
+	if err := checkReportTarget(reporterID, targetType, targetID); err != nil {
+		return nil, err
+	}
+
+	already, err := repository.HasOpenReport(db.DB, reporterID, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if already {
+		return nil, ErrAlreadyReported
+	}
+
+	report := model.NewReport(reporterID, targetType, targetID, reason, details)
+	// A double tap can report between the check and the insert
+	if err := repository.CreateReport(db.DB, &report); err != nil {
+		return nil, apperr.Unique(err, ErrAlreadyReported)
+	}
+	return &report, nil
+}
+
+func isValidReportReason(reason string) bool {
+	for _, r := range model.ReportReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReportTarget makes sure the reported thing exists and is visible
+func checkReportTarget(reporterID uint64, targetType string, targetID uint64) error {
+	switch targetType {
+	case model.ReportTargetPost:
+		exists, err := repository.PostExists(db.DB, targetID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportTargetMissing
+		}
+	case model.ReportTargetComment:
+		comment, err := repository.GetCommentByID(db.DB, targetID)
+		if err != nil {
+			if errors.Is(err, repository.ErrCommentNotFound) {
+				return ErrReportTargetMissing
+			}
+			return err
+		}
+		if comment.Hidden {
+			return ErrReportTargetMissing
+		}
+	case model.ReportTargetUser:
+		if targetID == reporterID {
+			return ErrReportYourself
+		}
+		if _, err := repository.GetUserByID(db.DB, targetID); err != nil {
+			return ErrReportTargetMissing
+		}
+	default:
+		return ErrInvalidReportTarget
+	}
+	return nil
+}
+
+// =================== Moderation queue ===================
+func ListReports(filter repository.ReportFilter, cursorToken string, limit int) (*ReportPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	reports, err := repository.GetReports(db.DB, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ReportPage{Reports: []ReportView{}}
+	for _, report := range reports {
+		page.Reports = append(page.Reports, buildReportView(report))
+	}
+	if len(reports) == limit {
+		last := reports[len(reports)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// =================== Moderation actions ===================
+
+// ModeratePost hides, unhides or removes a post
+func ModeratePost(adminID, postID uint64, action, reason string, reportID *uint64) error {
+	return moderate(adminID, action, model.ReportTargetPost, postID, reason, reportID, func(tx *gorm.DB) error {
+		switch action {
+		case model.ModerationHidePost:
+			return translateModerationErr(repository.SetPostHidden(tx, postID, true))
+		case model.ModerationUnhidePost:
+			return translateModerationErr(repository.SetPostHidden(tx, postID, false))
+		case model.ModerationRemovePost:
+			return translateModerationErr(repository.PurgePost(tx, postID))
+		}
+		return ErrInvalidModeration
+	})
+}
+
+// ModerateComment hides, unhides or removes a comment
+func ModerateComment(adminID, commentID uint64, action, reason string, reportID *uint64) error {
+	return moderate(adminID, action, model.ReportTargetComment, commentID, reason, reportID, func(tx *gorm.DB) error {
+		switch action {
+		case model.ModerationHideComment:
+			return translateModerationErr(repository.SetCommentHidden(tx, commentID, true))
+		case model.ModerationUnhideComment:
+			return translateModerationErr(repository.SetCommentHidden(tx, commentID, false))
+		case model.ModerationRemoveComment:
+			return translateModerationErr(repository.PurgeComment(tx, commentID))
+		}
+		return ErrInvalidModeration
+	})
+}
+
+// SuspendUser blocks a user from logging in and from using their existing tokens
+func SuspendUser(adminID, userID uint64, until *time.Time, reason string, reportID *uint64) error {
+	if until != nil && !until.After(time.Now()) {
+		return ErrInvalidModeration
+	}
+
+	target, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if target.IsAdmin {
+		return ErrCannotSuspendAdmin
+	}
+
+	err = moderate(adminID, model.ModerationSuspendUser, model.ReportTargetUser, userID, reason, reportID, func(tx *gorm.DB) error {
+		return repository.SuspendUser(tx, userID, until, reason)
+	})
+	if err != nil {
+		return err
+	}
+	realtime.Default.DisconnectUser(userID)
+	return nil
+}
+
+// UnsuspendUser lifts a suspension
+func UnsuspendUser(adminID, userID uint64, reason string) error {
+	if _, err := repository.GetUserByID(db.DB, userID); err != nil {
+		return ErrUserNotFound
+	}
+
+	return moderate(adminID, model.ModerationUnsuspendUser, model.ReportTargetUser, userID, reason, nil, func(tx *gorm.DB) error {
+		return repository.UnsuspendUser(tx, userID)
+	})
+}
+
+// DismissReport closes a report without touching the reported content
+func DismissReport(adminID, reportID uint64, reason string) error {
+	report, err := repository.GetReportByID(db.DB, reportID)
+	if err != nil {
+		if errors.Is(err, repository.ErrReportNotFound) {
+			return ErrReportNotFound
+		}
+		return err
+	}
+
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.ResolveReport(tx, reportID, model.ReportStatusDismissed, adminID); err != nil {
+			if errors.Is(err, repository.ErrReportNotFound) {
+				return ErrReportNotFound
+			}
+			return err
+		}
+
+		entry := model.NewModerationAction(adminID, model.ModerationDismissReport, report.TargetType, report.TargetID, &reportID, reason)
+		return repository.CreateModerationAction(tx, &entry)
+	})
+}
+
+// moderate runs a moderation change, records it in the audit log and closes
+// the open reports on the target, all in one transaction
+func moderate(adminID uint64, action, targetType string, targetID uint64, reason string, reportID *uint64, apply func(tx *gorm.DB) error) error {
+	return db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := apply(tx); err != nil {
+			return err
+		}
+
+		entry := model.NewModerationAction(adminID, action, targetType, targetID, reportID, reason)
+		if err := repository.CreateModerationAction(tx, &entry); err != nil {
+			return err
+		}
+
+		// Undoing an action doesn't resolve anything
+		if action == model.ModerationUnhidePost || action == model.ModerationUnhideComment || action == model.ModerationUnsuspendUser {
+			return nil
+		}
+		return repository.ResolveOpenReports(tx, targetType, targetID, model.ReportStatusActioned, adminID)
+	})
+}
+
+func translateModerationErr(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, repository.ErrCommentNotFound) {
+		return ErrNothingToModerate
+	}
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// =================== Moderation audit log ===================
+func ListModerationActions(filter repository.ModerationActionFilter, cursorToken string, limit int) (*ModerationLogPage, error) {
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	actions, err := repository.GetModerationActions(db.DB, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ModerationLogPage{Actions: []ModerationActionView{}}
+	for _, action := range actions {
+		page.Actions = append(page.Actions, buildModerationActionView(action))
+	}
+	if len(actions) == limit {
+		last := actions[len(actions)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"bufio"
+	_ "embed"
+	"strings"
+
+	"wazzafak_back/internal/apperr"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores anything past 72 bytes
+	minIdentityLength = 3  // shorter usernames/local parts would reject too much
+)
+
+var (
+	ErrPasswordTooShort    = apperr.Invalid("password must be at least 8 characters")
+	ErrPasswordTooLong     = apperr.Invalid("password must be at most 72 bytes")
+	ErrPasswordTooCommon   = apperr.Invalid("this password is too common, choose another one")
+	ErrPasswordHasIdentity = apperr.Invalid("password must not contain your username or email")
+	ErrWrongPassword       = apperr.Forbidden("current password is incorrect")
+	ErrPasswordNotChanged  = apperr.Invalid("new password must differ from the current one")
+)
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var commonPasswords = loadCommonPasswords(commonPasswordList)
+
+func loadCommonPasswords(list string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	scanner := bufio.NewScanner(strings.NewReader(list))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
+
+// CheckPasswordPolicy is the one password policy for registration, reset and
+// change: a length range, no well-known breached password, and nothing that
+// can be guessed from the account's username or email.
+func CheckPasswordPolicy(password, username, email string) error {
+	if len([]rune(password)) < minPasswordLength {
+		return ErrPasswordTooShort
+	}
+	if len(password) > maxPasswordLength {
+		return ErrPasswordTooLong
+	}
+
+	lowered := strings.ToLower(password)
+	if _, ok := commonPasswords[lowered]; ok {
+		return ErrPasswordTooCommon
+	}
+
+	localPart := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		localPart = email[:at]
+	}
+	for _, identity := range []string{username, localPart} {
+		identity = strings.ToLower(strings.TrimSpace(identity))
+		if len(identity) >= minIdentityLength && strings.Contains(lowered, identity) {
+			return ErrPasswordHasIdentity
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckPasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		username string
+		email    string
+		want     error
+	}{
+		{"acceptable", "correct horse battery", "sara", "sara@example.com", nil},
+		{"too short", "Ab3$xyz", "sara", "sara@example.com", ErrPasswordTooShort},
+		{"length counts characters", "كلمةسرية", "sara", "sara@example.com", nil},
+		{"72 bytes is the limit", strings.Repeat("x", 71) + "y", "sara", "sara@example.com", nil},
+		{"over 72 bytes", strings.Repeat("x", 72) + "y", "sara", "sara@example.com", ErrPasswordTooLong},
+		{"multibyte over 72 bytes", strings.Repeat("ك", 37), "sara", "sara@example.com", ErrPasswordTooLong},
+		{"common", "password1", "sara", "sara@example.com", ErrPasswordTooCommon},
+		{"common in another case", "PassWord1", "sara", "sara@example.com", ErrPasswordTooCommon},
+		{"contains username", "my-Sara-secret", "sara", "other@example.com", ErrPasswordHasIdentity},
+		{"contains email local part", "xx_jdoe_2024!", "someone", "JDoe@example.com", ErrPasswordHasIdentity},
+		{"email domain is fine", "example-rocks-42", "someone", "jdoe@example.com", nil},
+		{"short identities are ignored", "al-the-great-1", "al", "al@example.com", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckPasswordPolicy(tt.password, tt.username, tt.email); !errors.Is(err, tt.want) {
+				t.Errorf("CheckPasswordPolicy(%q) = %v, want %v", tt.password, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCommonPasswords(t *testing.T) {
+	passwords := loadCommonPasswords("# comment\n\n  Secret123  \nhunter22\n")
+	if len(passwords) != 2 {
+		t.Fatalf("loaded %d passwords, want 2", len(passwords))
+	}
+	for _, want := range []string{"secret123", "hunter22"} {
+		if _, ok := passwords[want]; !ok {
+			t.Errorf("%q missing", want)
+		}
+	}
+}
@@ -2,28 +2,34 @@
 package service
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
+	"strconv"
 	"time"
 
+	"wazzafak_back/internal/apperr"
 	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/mailer"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/realtime"
 	"wazzafak_back/internal/repository"
-	"wazzafak_back/utils"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrInvalidResetCode          = errors.New("invalid or expired reset code")
-	ErrPasswordResetUserNotFound = errors.New("user not found")
+	ErrInvalidResetCode          = apperr.Invalid("invalid or expired reset code")
+	ErrPasswordResetUserNotFound = apperr.NotFound("user not found")
 )
 
 // SendPasswordResetCode generates and sends a reset code
-func SendPasswordResetCode(email string) error {
+func SendPasswordResetCode(ctx context.Context, email string) error {
 	// Check if user exists
-	_, err := repository.GetUserByEmail(db.DB, email)
+	user, err := repository.GetUserByEmail(db.DB, email)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, repository.ErrUserNotFound) {
 			return ErrPasswordResetUserNotFound
 		}
 		return err
@@ -33,50 +39,109 @@ func SendPasswordResetCode(email string) error {
 	code := generateCode()
 	expiresAt := time.Now().Add(15 * time.Minute)
 
-	// Save to database
-	if err := repository.SavePasswordResetCode(db.DB, email, code, expiresAt); err != nil {
-		return err
-	}
-
-	// Send email using utils package
-	err = utils.SendPasswordResetEmail(email, code)
+	// Save the code and queue its email together
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.SavePasswordResetCode(tx, email, code, expiresAt); err != nil {
+			return err
+		}
+		return enqueueEmail(tx, email, mailer.TemplatePasswordReset, codeEmailData{Code: code, ExpiresIn: "15 minutes"})
+	})
 	if err != nil {
 		return err
 	}
 
+	wakeMailOutbox()
+	recordAuditEvent(ctx, user.ID, user.ID, model.AuditPasswordResetRequested, nil)
 	return nil
 }
 
-// VerifyPasswordResetCode checks if the code is valid
+// VerifyPasswordResetCode checks if the code is valid. Every check counts
+// against the code's attempt limit.
 func VerifyPasswordResetCode(email, code string) error {
-	_, err := repository.GetPasswordResetCode(db.DB, email, code)
+	reset, err := repository.GetPasswordReset(db.DB, email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return ErrInvalidResetCode
 		}
 		return err
 	}
+
+	ok, err := repository.ConsumePasswordResetAttempt(db.DB, email, maxCodeAttempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTooManyCodeAttempts
+	}
+
+	if subtle.ConstantTimeCompare([]byte(reset.Code), []byte(code)) != 1 {
+		return ErrInvalidResetCode
+	}
 	return nil
 }
 
 // ResetPassword updates the user's password after verification
-func ResetPassword(email, code, newPassword string) error {
+func ResetPassword(ctx context.Context, email, code, newPassword string) error {
 	// Verify the code
 	if err := VerifyPasswordResetCode(email, code); err != nil {
 		return err
 	}
 
+	user, err := repository.GetUserByEmail(db.DB, email)
+	if err != nil {
+		return ErrPasswordResetUserNotFound
+	}
+	if err := CheckPasswordPolicy(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
 	// Hash the new password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return err
 	}
 
-	// Update password
-	if err := repository.UpdateUserPassword(db.DB, email, string(hashedPassword)); err != nil {
+	// Update password; this also signs out every existing session
+	if err := repository.UpdateUserPassword(db.DB, email, string(hashedPassword), time.Now()); err != nil {
 		return err
 	}
 
+	realtime.Default.DisconnectUser(user.ID)
+	recordAuditEvent(ctx, user.ID, user.ID, model.AuditPasswordReset, nil)
+
 	// Delete the reset code
 	return repository.DeletePasswordResetCode(db.DB, email)
 }
+
+// ChangePassword sets a new password for a signed-in user who knows the
+// current one. Every other session is signed out; the returned token is the
+// caller's new one.
+func ChangePassword(ctx context.Context, userID uint64, currentPassword, newPassword string) (string, error) {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return "", ErrUserNotFound
+	}
+	if !checkPassword(currentPassword, user.Password) {
+		return "", ErrWrongPassword
+	}
+	if currentPassword == newPassword {
+		return "", ErrPasswordNotChanged
+	}
+	if err := CheckPasswordPolicy(newPassword, user.Username, user.Email); err != nil {
+		return "", err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	if err := repository.UpdateUserPasswordByID(db.DB, userID, string(hashedPassword), time.Now()); err != nil {
+		return "", err
+	}
+	// The caller reconnects with the returned token
+	realtime.Default.DisconnectUser(userID)
+	recordAuditEvent(ctx, userID, userID, model.AuditPasswordChanged, nil)
+
+	return GenerateJWT(strconv.FormatUint(userID, 10))
+}
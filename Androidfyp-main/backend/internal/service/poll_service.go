@@ -0,0 +1,196 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 6
+	maxPollOptionLength = 100
+)
+
+var (
+	ErrPollQuestionRequired = apperr.Invalid("a poll needs a question in content")
+	ErrInvalidPollOptions   = apperr.Invalid("a poll needs between 2 and 6 distinct, non-empty options of at most 100 characters")
+	ErrInvalidPollExpiry    = apperr.Invalid("poll expiry must be in the future and after the publish time")
+	ErrPollNotFound         = apperr.NotFound("poll not found")
+	ErrPollClosed           = apperr.Conflict("poll is closed")
+	ErrAlreadyVoted         = apperr.Conflict("you have already voted in this poll")
+	ErrInvalidPollVote      = apperr.Invalid("invalid poll option selection")
+)
+
+// PollInput describes the poll attached to a new post
+type PollInput struct {
+	Options        []string
+	MultipleChoice bool
+	ExpiresAt      *time.Time
+}
+
+// validate trims the options and checks the 2–6 option rule and the expiry
+func (in PollInput) validate(publishAt *time.Time) ([]string, *time.Time, error) {
+	if len(in.Options) < minPollOptions || len(in.Options) > maxPollOptions {
+		return nil, nil, ErrInvalidPollOptions
+	}
+
+	seen := make(map[string]bool, len(in.Options))
+	options := make([]string, 0, len(in.Options))
+	for _, option := range in.Options {
+		option = strings.TrimSpace(option)
+		key := strings.ToLower(option)
+		if option == "" || utf8.RuneCountInString(option) > maxPollOptionLength || seen[key] {
+			return nil, nil, ErrInvalidPollOptions
+		}
+		seen[key] = true
+		options = append(options, option)
+	}
+
+	if in.ExpiresAt == nil {
+		return options, nil, nil
+	}
+
+	opensAt := time.Now()
+	if publishAt != nil {
+		opensAt = *publishAt
+	}
+	if !in.ExpiresAt.After(opensAt) {
+		return nil, nil, ErrInvalidPollExpiry
+	}
+
+	expiresAt := in.ExpiresAt.UTC()
+	return options, &expiresAt, nil
+}
+
+// PollOptionView is one option as shown to a viewer; the count is
+// omitted while the results are hidden from them
+type PollOptionView struct {
+	ID         string `json:"id"`
+	Text       string `json:"text"`
+	VotesCount *int64 `json:"votes_count,omitempty"`
+}
+
+// PollView is the poll block returned inside a PostResponse
+type PollView struct {
+	Options        []PollOptionView `json:"options"`
+	MultipleChoice bool             `json:"multiple_choice"`
+	ExpiresAt      string           `json:"expires_at,omitempty"`
+	IsClosed       bool             `json:"is_closed"`
+	HasVoted       bool             `json:"has_voted"`
+	ResultsVisible bool             `json:"results_visible"`
+	TotalVoters    *int64           `json:"total_voters,omitempty"`
+	MyOptionIDs    []string         `json:"my_option_ids,omitempty"`
+}
+
+// =================== Get a poll as seen by a viewer ===================
+// Results stay hidden until the viewer votes, the poll closes, or the viewer is the author.
+func GetPollView(postID, viewerID, authorID uint64) (*PollView, error) {
+	poll, err := repository.GetPoll(db.DB, postID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPollNotFound) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+
+	tallies, err := repository.GetPollTallies(db.DB, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	myVotes, err := repository.GetUserPollVotes(db.DB, postID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &PollView{
+		Options:        []PollOptionView{},
+		MultipleChoice: poll.MultipleChoice,
+		IsClosed:       poll.IsClosed(time.Now()),
+		HasVoted:       len(myVotes) > 0,
+	}
+	if poll.ExpiresAt != nil {
+		view.ExpiresAt = poll.ExpiresAt.Format(time.RFC3339)
+	}
+	view.ResultsVisible = view.HasVoted || view.IsClosed || viewerID == authorID
+
+	for _, optionID := range myVotes {
+		view.MyOptionIDs = append(view.MyOptionIDs, strconv.FormatUint(optionID, 10))
+	}
+
+	for _, tally := range tallies {
+		option := PollOptionView{
+			ID:   strconv.FormatUint(tally.ID, 10),
+			Text: tally.Text,
+		}
+		if view.ResultsVisible {
+			count := tally.VotesCount
+			option.VotesCount = &count
+		}
+		view.Options = append(view.Options, option)
+	}
+
+	if view.ResultsVisible {
+		total, err := repository.CountPollVoters(db.DB, postID)
+		if err != nil {
+			return nil, err
+		}
+		view.TotalVoters = &total
+	}
+
+	return view, nil
+}
+
+// =================== Vote in a poll ===================
+func VoteInPoll(userID, postID uint64, optionIDs []uint64) (*PollView, error) {
+	post, err := repository.GetPostByID(db.DB, postID)
+	if err != nil || post.Status != model.PostStatusPublished {
+		return nil, ErrPostNotFound
+	}
+
+	poll, err := repository.GetPoll(db.DB, postID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPollNotFound) {
+			return nil, ErrPollNotFound
+		}
+		return nil, err
+	}
+
+	// Drop duplicates so {a, a} can't sneak past the single-choice rule
+	seen := make(map[uint64]bool, len(optionIDs))
+	unique := make([]uint64, 0, len(optionIDs))
+	for _, id := range optionIDs {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	if len(unique) == 0 || (!poll.MultipleChoice && len(unique) > 1) {
+		return nil, ErrInvalidPollVote
+	}
+
+	err = repository.CastPollVote(db.DB, postID, userID, unique, time.Now())
+	switch {
+	case errors.Is(err, repository.ErrPollNotFound):
+		return nil, ErrPollNotFound
+	case errors.Is(err, repository.ErrPollClosed):
+		return nil, ErrPollClosed
+	case errors.Is(err, repository.ErrAlreadyVoted):
+		return nil, ErrAlreadyVoted
+	case errors.Is(err, repository.ErrInvalidPollOption):
+		return nil, ErrInvalidPollVote
+	case err != nil:
+		return nil, err
+	}
+
+	return GetPollView(postID, userID, post.UserID)
+}
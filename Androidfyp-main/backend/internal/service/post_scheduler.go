@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/repository"
+)
+
+const (
+	scheduledPostsBatchSize = 100
+	closedPollsBatchSize    = 100
+)
+
+// RunPostScheduler publishes scheduled posts once their publish_at has passed
+// and notifies poll authors when their poll closes. It is safe to run on every
+// replica: rows are claimed with row locks, so each post is published (and each
+// author notified) exactly once. Blocks until ctx is cancelled.
+func RunPostScheduler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		publishDuePosts()
+		notifyClosedPolls()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func publishDuePosts() {
+	for {
+		published, err := repository.PublishDuePosts(db.DB, time.Now(), scheduledPostsBatchSize)
+		if err != nil {
+			slog.Error("post scheduler failed", "error", err)
+			return
+		}
+		if published > 0 {
+			slog.Info("post scheduler: published scheduled posts", "count", published)
+		}
+		// A short batch means nothing else is due right now
+		if published < scheduledPostsBatchSize {
+			return
+		}
+	}
+}
+
+func notifyClosedPolls() {
+	for {
+		notified, err := repository.NotifyClosedPolls(db.DB, time.Now(), closedPollsBatchSize)
+		if err != nil {
+			slog.Error("post scheduler failed", "error", err)
+			return
+		}
+		if notified < closedPollsBatchSize {
+			return
+		}
+	}
+}
@@ -1,8 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/contentfilter"
 	db "wazzafak_back/internal/database"
 	"wazzafak_back/internal/model"
 	"wazzafak_back/internal/repository"
@@ -12,34 +17,288 @@ import (
 
 // --- Error definitions ---
 var (
-	ErrPostNotFound     = errors.New("post not found")
-	ErrInvalidPostInput = errors.New("either content or photo_url must be provided")
-	ErrUnauthorized     = errors.New("unauthorized")
+	ErrPostNotFound      = apperr.NotFound("post not found")
+	ErrInvalidPostInput  = apperr.Invalid("either content or photo_url must be provided")
+	ErrUnauthorized      = apperr.Forbidden("unauthorized")
+	ErrInvalidPostStatus = apperr.Invalid("status must be draft, scheduled or published")
+	ErrInvalidPublishAt  = apperr.Invalid("publish_at must be in the future")
+	ErrPostNotEditable   = apperr.Conflict("only drafts and scheduled posts can be edited")
 )
 
+// PostInput is what a user submits when creating or editing a post.
+// Status may be left empty: it becomes "scheduled" when PublishAt is set,
+// otherwise "published".
+type PostInput struct {
+	PhotoURL  string
+	Content   string
+	Status    string
+	PublishAt *time.Time
+	Poll      *PollInput // set to create a poll post; Content is the question
+}
+
+// resolveStatus validates the requested status/publish time combination
+func (in PostInput) resolveStatus() (string, *time.Time, error) {
+	status := in.Status
+	if status == "" {
+		status = model.PostStatusPublished
+		if in.PublishAt != nil {
+			status = model.PostStatusScheduled
+		}
+	}
+
+	switch status {
+	case model.PostStatusPublished:
+		if in.PublishAt != nil {
+			return "", nil, ErrInvalidPostStatus
+		}
+		return status, nil, nil
+	case model.PostStatusDraft:
+		return status, nil, nil
+	case model.PostStatusScheduled:
+		if in.PublishAt == nil || !in.PublishAt.After(time.Now()) {
+			return "", nil, ErrInvalidPublishAt
+		}
+		publishAt := in.PublishAt.UTC()
+		return status, &publishAt, nil
+	default:
+		return "", nil, ErrInvalidPostStatus
+	}
+}
+
 // =================== Create a new post ===================
-func CreatePost(userID uint64, photoURL, content string) error {
-	if content == "" && photoURL == "" {
-		return ErrInvalidPostInput
+func CreatePost(ctx context.Context, userID uint64, input PostInput) (*model.Post, error) {
+	if input.Content == "" && input.PhotoURL == "" {
+		return nil, ErrInvalidPostInput
+	}
+
+	status, publishAt, err := input.resolveStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	post := model.NewPost_structure(userID, input.PhotoURL, input.Content)
+	post.Status = status
+	post.PublishAt = publishAt
+
+	var (
+		poll        *model.Poll
+		pollOptions []model.PollOption
+	)
+	if input.Poll != nil {
+		if poll, pollOptions, err = buildPoll(&post, *input.Poll); err != nil {
+			return nil, err
+		}
+	}
+
+	// Drafts are private, so they are screened when they go out instead
+	var held bool
+	var holdReason string
+	if status != model.PostStatusDraft {
+		texts := make([]string, 0, len(pollOptions))
+		for _, option := range pollOptions {
+			texts = append(texts, option.Text)
+		}
+		held, holdReason, err = screenContent(ctx, userID, contentfilter.KindPost, postText(post.Content, texts))
+		if err != nil {
+			return nil, err
+		}
+		if held {
+			post.Status = model.PostStatusHidden
+			post.PublishAt = nil
+		}
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if poll != nil {
+			if err := repository.CreatePollPost(tx, &post, poll, pollOptions); err != nil {
+				return err
+			}
+		} else if err := repository.CreatePost(tx, &post); err != nil {
+			return err
+		}
+
+		if held {
+			return fileAutomatedReport(tx, model.ReportTargetPost, post.ID, holdReason)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return repository.CreatePost(db.DB, userID, photoURL, content)
+	return &post, nil
 }
 
-// =================== Delete a post (only owner) ===================
-func DeletePost(postID, userID uint64) error {
+// buildPoll validates the poll and turns post into a poll post
+func buildPoll(post *model.Post, input PollInput) (*model.Poll, []model.PollOption, error) {
+	if strings.TrimSpace(post.Content) == "" {
+		return nil, nil, ErrPollQuestionRequired
+	}
+
+	options, expiresAt, err := input.validate(post.PublishAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	post.Kind = model.PostKindPoll
+	poll, pollOptions := model.NewPoll_structure(post.ID, input.MultipleChoice, expiresAt, options)
+	return &poll, pollOptions, nil
+}
+
+// postText is everything the content filter should read in a post
+func postText(content string, pollOptions []string) string {
+	return strings.Join(append([]string{content}, pollOptions...), "\n")
+}
+
+// screenExistingPost screens a draft that is about to be published or scheduled
+func screenExistingPost(ctx context.Context, post *model.Post) (bool, string, error) {
+	tallies, err := repository.GetPollTallies(db.DB, post.ID)
+	if err != nil {
+		return false, "", err
+	}
+
+	texts := make([]string, 0, len(tallies))
+	for _, tally := range tallies {
+		texts = append(texts, tally.Text)
+	}
+	return screenContent(ctx, post.UserID, contentfilter.KindPost, postText(post.Content, texts))
+}
+
+// =================== Edit a draft or scheduled post (only owner) ===================
+func UpdateUnpublishedPost(ctx context.Context, postID, userID uint64, input PostInput) (*model.Post, error) {
+	if input.Content == "" && input.PhotoURL == "" {
+		return nil, ErrInvalidPostInput
+	}
+
+	post, err := repository.GetPostByID(db.DB, postID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+	if post.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+	if !post.IsUnpublished() {
+		return nil, ErrPostNotEditable
+	}
+
+	status, publishAt, err := input.resolveStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	post.PhotoURL = input.PhotoURL
+	post.Content = input.Content
+	post.Status = status
+	post.PublishAt = publishAt
+
+	var held bool
+	var holdReason string
+	if status != model.PostStatusDraft {
+		if held, holdReason, err = screenExistingPost(ctx, post); err != nil {
+			return nil, err
+		}
+	}
+
+	// Publishing (and holding) goes through PublishPost/HoldPost so created_at
+	// is stamped, so save the edit as a draft first
+	if status == model.PostStatusPublished || held {
+		post.Status = model.PostStatusDraft
+	}
+
+	if err := repository.UpdateUnpublishedPost(db.DB, post); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPostNotEditable
+		}
+		return nil, err
+	}
+
+	if held {
+		return holdAndReload(post.ID, holdReason)
+	}
+	if status == model.PostStatusPublished {
+		return publishAndReload(post.ID)
+	}
+	return post, nil
+}
+
+// =================== Publish a draft/scheduled post now (only owner) ===================
+func PublishPostNow(ctx context.Context, postID, userID uint64) (*model.Post, error) {
 	post, err := repository.GetPostByID(db.DB, postID)
+	if err != nil {
+		return nil, ErrPostNotFound
+	}
+	if post.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+	if !post.IsUnpublished() {
+		return nil, ErrPostNotEditable
+	}
+
+	held, holdReason, err := screenExistingPost(ctx, post)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return holdAndReload(postID, holdReason)
+	}
+
+	return publishAndReload(postID)
+}
+
+func publishAndReload(postID uint64) (*model.Post, error) {
+	if err := repository.PublishPost(db.DB, postID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Someone (the scheduler) published it first
+			return nil, ErrPostNotEditable
+		}
+		return nil, err
+	}
+	return repository.GetPostByID(db.DB, postID)
+}
+
+// holdAndReload hides a flagged draft and puts it in the moderation queue
+func holdAndReload(postID uint64, reason string) (*model.Post, error) {
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.HoldPost(tx, postID); err != nil {
+			return err
+		}
+		return fileAutomatedReport(tx, model.ReportTargetPost, postID, reason)
+	})
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return ErrPostNotFound
+		return nil, ErrPostNotEditable
 	}
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return repository.GetPostByID(db.DB, postID)
+}
+
+// =================== Get my drafts and scheduled posts ===================
+func GetMyUnpublishedPosts(userID uint64) ([]model.Post, error) {
+	return repository.GetUnpublishedPostsByUserID(db.DB, userID)
+}
+
+// =================== Delete a post (only owner) ===================
+// DeletePost soft-deletes the post and returns until when it can be restored
+func DeletePost(postID, userID uint64) (time.Time, error) {
+	post, err := repository.GetPostByID(db.DB, postID)
+	if errors.Is(err, repository.ErrPostNotFound) {
+		return time.Time{}, ErrPostNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
 	}
 
 	if post.UserID != userID {
-		return ErrUnauthorized
+		return time.Time{}, ErrUnauthorized
 	}
 
-	return repository.DeletePost(db.DB, postID)
+	if err := repository.DeletePost(db.DB, postID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return time.Time{}, ErrPostNotFound
+		}
+		return time.Time{}, err
+	}
+	return time.Now().Add(deletedContentRestoreWindow), nil
 }
 
 // =================== Get all posts ===================
@@ -55,7 +314,7 @@ func GetUserFeed(userID uint64) ([]model.Post, error) {
 // =================== Get a post by ID ===================
 func GetPostByID(postID uint64) (*model.Post, error) {
 	post, err := repository.GetPostByID(db.DB, postID)
-	if errors.Is(err, gorm.ErrRecordNotFound) {
+	if errors.Is(err, repository.ErrPostNotFound) {
 		return nil, ErrPostNotFound
 	}
 	return post, err
@@ -65,7 +324,7 @@ func GetPostByID(postID uint64) (*model.Post, error) {
 func GetPostsByUsername(username string) ([]model.Post, error) {
 	user, err := repository.GetUserByUsername(db.DB, username)
 	if err != nil {
-		return nil, err
+		return nil, translateUserErr(err)
 	}
 	return repository.GetPostsByUserID(db.DB, user.ID)
 }
@@ -0,0 +1,575 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+)
+
+const (
+	maxHeadlineLength     = 150
+	maxBioLength          = 2000
+	maxSkillLength        = 50
+	maxProfileFieldLength = 150
+	maxProfileTextLength  = 2000
+	maxLinkLabelLength    = 50
+	profileMonthFormat    = "2006-01"
+)
+
+var (
+	ErrInvalidAbout         = apperr.Invalid("headline must be at most 150 characters and bio at most 2000")
+	ErrInvalidSkill         = apperr.Invalid("skills must be 1 to 50 characters")
+	ErrTooManySkills        = apperr.Invalid("a profile can list at most 50 skills")
+	ErrSkillExists          = apperr.Conflict("skill already on your profile")
+	ErrSkillNotFound        = apperr.NotFound("skill not found")
+	ErrInvalidProfileEntry  = apperr.Invalid("required fields are missing or too long")
+	ErrInvalidProfileDate   = apperr.Invalid("dates must be in YYYY-MM format")
+	ErrInvalidDateRange     = apperr.Invalid("start date must not be in the future or after the end date")
+	ErrProfileSectionFull   = apperr.Conflict("this profile section is full")
+	ErrProfileEntryNotFound = apperr.NotFound("profile entry not found")
+	ErrInvalidLink          = apperr.Invalid("links need a label of at most 50 characters and an http(s) url")
+)
+
+// ExperienceInput is a work experience entry as submitted; dates are YYYY-MM
+// and an empty EndDate means the job is current
+type ExperienceInput struct {
+	Title       string
+	Company     string
+	Location    string
+	StartDate   string
+	EndDate     string
+	Description string
+}
+
+// EducationInput is an education entry as submitted; dates are YYYY-MM
+type EducationInput struct {
+	School       string
+	Degree       string
+	FieldOfStudy string
+	StartDate    string
+	EndDate      string
+	Description  string
+}
+
+type SkillView struct {
+	Name             string         `json:"name"`
+	EndorsementCount int64          `json:"endorsement_count"`
+	TopEndorsers     []EndorserView `json:"top_endorsers"`
+	EndorsedByMe     bool           `json:"endorsed_by_me"`
+}
+
+type ExperienceView struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Company     string `json:"company"`
+	Location    string `json:"location"`
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date,omitempty"` // empty for the current job
+	Description string `json:"description"`
+}
+
+type EducationView struct {
+	ID           string `json:"id"`
+	School       string `json:"school"`
+	Degree       string `json:"degree"`
+	FieldOfStudy string `json:"field_of_study"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date,omitempty"`
+	Description  string `json:"description"`
+}
+
+type LinkView struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// ProfileView is the structured part of a profile
+type ProfileView struct {
+	Headline   string           `json:"headline"`
+	Bio        string           `json:"bio"`
+	Skills     []SkillView      `json:"skills"`
+	Experience []ExperienceView `json:"experience"`
+	Education  []EducationView  `json:"education"`
+	Links      []LinkView       `json:"links"`
+}
+
+// UserWithProfile is a user together with their profile sections
+type UserWithProfile struct {
+	*model.User
+	Skills     []SkillView      `json:"skills"`
+	Experience []ExperienceView `json:"experience"`
+	Education  []EducationView  `json:"education"`
+	Links      []LinkView       `json:"links"`
+}
+
+func formatMonth(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(profileMonthFormat)
+}
+
+// parseDateRange checks a YYYY-MM start and optional end
+func parseDateRange(start, end string) (time.Time, *time.Time, error) {
+	startDate, err := time.Parse(profileMonthFormat, strings.TrimSpace(start))
+	if err != nil {
+		return time.Time{}, nil, ErrInvalidProfileDate
+	}
+	if startDate.After(time.Now()) {
+		return time.Time{}, nil, ErrInvalidDateRange
+	}
+
+	end = strings.TrimSpace(end)
+	if end == "" {
+		return startDate, nil, nil
+	}
+	endDate, err := time.Parse(profileMonthFormat, end)
+	if err != nil {
+		return time.Time{}, nil, ErrInvalidProfileDate
+	}
+	if endDate.Before(startDate) {
+		return time.Time{}, nil, ErrInvalidDateRange
+	}
+	return startDate, &endDate, nil
+}
+
+// checkFields trims the fields in place; required ones must not be empty
+func checkFields(required []*string, optional []*string, maxLength int) error {
+	for _, field := range append(required, optional...) {
+		*field = strings.TrimSpace(*field)
+		if len(*field) > maxLength {
+			return ErrInvalidProfileEntry
+		}
+	}
+	for _, field := range required {
+		if *field == "" {
+			return ErrInvalidProfileEntry
+		}
+	}
+	return nil
+}
+
+// =================== Read ===================
+
+// GetProfile returns the profile sections of a user
+func GetProfile(userID uint64) (*ProfileView, error) {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	full, err := withProfile(user, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &ProfileView{
+		Headline:   user.Headline,
+		Bio:        user.Bio,
+		Skills:     full.Skills,
+		Experience: full.Experience,
+		Education:  full.Education,
+		Links:      full.Links,
+	}, nil
+}
+
+// GetUserWithProfile is GetUserByUsername with the profile sections attached
+func GetUserWithProfile(username string, viewerID uint64) (*UserWithProfile, error) {
+	user, err := repository.GetUserByUsername(db.DB, username)
+	if err != nil {
+		// An old handle still points at its owner while it's reserved
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return nil, err
+		}
+		if user, err = resolveReservedUsername(username); err != nil {
+			return nil, err
+		}
+	}
+	return withProfile(user, viewerID)
+}
+
+func withProfile(user *model.User, viewerID uint64) (*UserWithProfile, error) {
+	skills, err := repository.GetUserSkills(db.DB, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	skillViews, err := buildSkillViews(skills, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	experiences, err := repository.GetExperiences(db.DB, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	educations, err := repository.GetEducations(db.DB, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	links, err := repository.GetProfileLinks(db.DB, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	full := &UserWithProfile{
+		User:       user,
+		Skills:     skillViews,
+		Experience: []ExperienceView{},
+		Education:  []EducationView{},
+		Links:      []LinkView{},
+	}
+	for _, entry := range experiences {
+		full.Experience = append(full.Experience, buildExperienceView(entry))
+	}
+	for _, entry := range educations {
+		full.Education = append(full.Education, buildEducationView(entry))
+	}
+	for _, link := range links {
+		full.Links = append(full.Links, buildLinkView(link))
+	}
+	return full, nil
+}
+
+func buildExperienceView(entry model.Experience) ExperienceView {
+	return ExperienceView{
+		ID:          strconv.FormatUint(entry.ID, 10),
+		Title:       entry.Title,
+		Company:     entry.Company,
+		Location:    entry.Location,
+		StartDate:   formatMonth(&entry.StartDate),
+		EndDate:     formatMonth(entry.EndDate),
+		Description: entry.Description,
+	}
+}
+
+func buildEducationView(entry model.Education) EducationView {
+	return EducationView{
+		ID:           strconv.FormatUint(entry.ID, 10),
+		School:       entry.School,
+		Degree:       entry.Degree,
+		FieldOfStudy: entry.FieldOfStudy,
+		StartDate:    formatMonth(&entry.StartDate),
+		EndDate:      formatMonth(entry.EndDate),
+		Description:  entry.Description,
+	}
+}
+
+func buildLinkView(link model.ProfileLink) LinkView {
+	return LinkView{
+		ID:    strconv.FormatUint(link.ID, 10),
+		Label: link.Label,
+		URL:   link.URL,
+	}
+}
+
+// =================== Headline and bio ===================
+func UpdateAbout(userID uint64, headline, bio string) error {
+	headline = strings.TrimSpace(headline)
+	bio = strings.TrimSpace(bio)
+	if len(headline) > maxHeadlineLength || len(bio) > maxBioLength {
+		return ErrInvalidAbout
+	}
+	return repository.UpdateUserAbout(db.DB, userID, headline, bio)
+}
+
+// =================== Skills ===================
+
+// ReplaceSkills sets the whole skills list, in the given order. Repeated
+// skills are listed once.
+func ReplaceSkills(userID uint64, names []string) ([]SkillView, error) {
+	skills := []model.UserSkill{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" || len(name) > maxSkillLength {
+			return nil, ErrInvalidSkill
+		}
+		key := model.NormalizeSkill(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		skills = append(skills, model.NewUserSkill(userID, name, len(skills)))
+	}
+	if len(skills) > model.MaxProfileSkills {
+		return nil, ErrTooManySkills
+	}
+
+	if err := repository.ReplaceUserSkills(db.DB, userID, skills); err != nil {
+		return nil, err
+	}
+
+	// Kept skills keep their endorsements, so reload them
+	saved, err := repository.GetUserSkills(db.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	return buildSkillViews(saved, userID)
+}
+
+// AddSkill appends one skill to the end of the list
+func AddSkill(userID uint64, name string) (*SkillView, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || len(name) > maxSkillLength {
+		return nil, ErrInvalidSkill
+	}
+
+	count, err := repository.CountUserSkills(db.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= model.MaxProfileSkills {
+		return nil, ErrTooManySkills
+	}
+
+	skill := model.NewUserSkill(userID, name, int(count))
+	if err := repository.AddUserSkill(db.DB, &skill); err != nil {
+		if errors.Is(err, repository.ErrSkillExists) {
+			return nil, ErrSkillExists
+		}
+		return nil, err
+	}
+	return &SkillView{Name: skill.Name, TopEndorsers: []EndorserView{}}, nil
+}
+
+func RemoveSkill(userID uint64, name string) error {
+	if err := repository.DeleteUserSkill(db.DB, userID, model.NormalizeSkill(name)); err != nil {
+		if errors.Is(err, repository.ErrSkillNotFound) {
+			return ErrSkillNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// =================== Experience ===================
+func AddExperience(userID uint64, input ExperienceInput) (*ExperienceView, error) {
+	entry := model.NewExperience(userID)
+	if err := fillExperience(&entry, input); err != nil {
+		return nil, err
+	}
+	if err := checkSectionRoom(&model.Experience{}, userID, model.MaxProfileExperience); err != nil {
+		return nil, err
+	}
+	if err := repository.CreateProfileEntry(db.DB, &entry); err != nil {
+		return nil, err
+	}
+	view := buildExperienceView(entry)
+	return &view, nil
+}
+
+func UpdateExperience(userID, entryID uint64, input ExperienceInput) (*ExperienceView, error) {
+	entry := model.Experience{ID: entryID, UserID: userID}
+	if err := fillExperience(&entry, input); err != nil {
+		return nil, err
+	}
+	err := repository.UpdateProfileEntry(db.DB, &entry, userID,
+		"title", "company", "location", "start_date", "end_date", "description")
+	if err != nil {
+		return nil, profileEntryError(err)
+	}
+	view := buildExperienceView(entry)
+	return &view, nil
+}
+
+func fillExperience(entry *model.Experience, input ExperienceInput) error {
+	if err := checkFields([]*string{&input.Title, &input.Company}, []*string{&input.Location}, maxProfileFieldLength); err != nil {
+		return err
+	}
+	if err := checkFields(nil, []*string{&input.Description}, maxProfileTextLength); err != nil {
+		return err
+	}
+	start, end, err := parseDateRange(input.StartDate, input.EndDate)
+	if err != nil {
+		return err
+	}
+
+	entry.Title = input.Title
+	entry.Company = input.Company
+	entry.Location = input.Location
+	entry.StartDate = start
+	entry.EndDate = end
+	entry.Description = input.Description
+	return nil
+}
+
+func DeleteExperience(userID, entryID uint64) error {
+	return profileEntryError(repository.DeleteProfileEntry(db.DB, &model.Experience{}, userID, entryID))
+}
+
+// =================== Education ===================
+func AddEducation(userID uint64, input EducationInput) (*EducationView, error) {
+	entry := model.NewEducation(userID)
+	if err := fillEducation(&entry, input); err != nil {
+		return nil, err
+	}
+	if err := checkSectionRoom(&model.Education{}, userID, model.MaxProfileEducation); err != nil {
+		return nil, err
+	}
+	if err := repository.CreateProfileEntry(db.DB, &entry); err != nil {
+		return nil, err
+	}
+	view := buildEducationView(entry)
+	return &view, nil
+}
+
+func UpdateEducation(userID, entryID uint64, input EducationInput) (*EducationView, error) {
+	entry := model.Education{ID: entryID, UserID: userID}
+	if err := fillEducation(&entry, input); err != nil {
+		return nil, err
+	}
+	err := repository.UpdateProfileEntry(db.DB, &entry, userID,
+		"school", "degree", "field_of_study", "start_date", "end_date", "description")
+	if err != nil {
+		return nil, profileEntryError(err)
+	}
+	view := buildEducationView(entry)
+	return &view, nil
+}
+
+func fillEducation(entry *model.Education, input EducationInput) error {
+	if err := checkFields([]*string{&input.School}, []*string{&input.Degree, &input.FieldOfStudy}, maxProfileFieldLength); err != nil {
+		return err
+	}
+	if err := checkFields(nil, []*string{&input.Description}, maxProfileTextLength); err != nil {
+		return err
+	}
+	start, end, err := parseDateRange(input.StartDate, input.EndDate)
+	if err != nil {
+		return err
+	}
+
+	entry.School = input.School
+	entry.Degree = input.Degree
+	entry.FieldOfStudy = input.FieldOfStudy
+	entry.StartDate = start
+	entry.EndDate = end
+	entry.Description = input.Description
+	return nil
+}
+
+func DeleteEducation(userID, entryID uint64) error {
+	return profileEntryError(repository.DeleteProfileEntry(db.DB, &model.Education{}, userID, entryID))
+}
+
+// =================== Links ===================
+func AddLink(userID uint64, label, url string) (*LinkView, error) {
+	label, url, err := checkLink(label, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSectionRoom(&model.ProfileLink{}, userID, model.MaxProfileLinks); err != nil {
+		return nil, err
+	}
+
+	link := model.NewProfileLink(userID, label, url)
+	if err := repository.CreateProfileEntry(db.DB, &link); err != nil {
+		return nil, err
+	}
+	view := buildLinkView(link)
+	return &view, nil
+}
+
+func UpdateLink(userID, linkID uint64, label, url string) (*LinkView, error) {
+	label, url, err := checkLink(label, url)
+	if err != nil {
+		return nil, err
+	}
+
+	link := model.ProfileLink{ID: linkID, UserID: userID, Label: label, URL: url}
+	if err := repository.UpdateProfileEntry(db.DB, &link, userID, "label", "url"); err != nil {
+		return nil, profileEntryError(err)
+	}
+	view := buildLinkView(link)
+	return &view, nil
+}
+
+func checkLink(label, url string) (string, string, error) {
+	label = strings.TrimSpace(label)
+	url = strings.TrimSpace(url)
+	if label == "" || len(label) > maxLinkLabelLength || !isHTTPURL(url) {
+		return "", "", ErrInvalidLink
+	}
+	return label, url, nil
+}
+
+func DeleteLink(userID, linkID uint64) error {
+	return profileEntryError(repository.DeleteProfileEntry(db.DB, &model.ProfileLink{}, userID, linkID))
+}
+
+func checkSectionRoom(section interface{}, userID uint64, max int) error {
+	count, err := repository.CountProfileEntries(db.DB, section, userID)
+	if err != nil {
+		return err
+	}
+	if count >= int64(max) {
+		return ErrProfileSectionFull
+	}
+	return nil
+}
+
+func profileEntryError(err error) error {
+	if errors.Is(err, repository.ErrProfileEntryNotFound) {
+		return ErrProfileEntryNotFound
+	}
+	return err
+}
+
+// =================== People search ===================
+
+// UserSummary is a search result
+type UserSummary struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Username        string `json:"username"`
+	PhotoURL        string `json:"photo_url"`
+	Headline        string `json:"headline"`
+	JobPosition     string `json:"job_position"`
+	JobPositionType string `json:"job_position_type"`
+}
+
+type UserSearchPage struct {
+	Users      []UserSummary `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// SearchUsers finds people by name, headline, bio, skill, employer or school
+func SearchUsers(filter repository.UserSearchFilter, cursorToken string, limit int) (*UserSearchPage, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	filter.Skill = model.NormalizeSkill(filter.Skill)
+	filter.Company = strings.TrimSpace(filter.Company)
+	filter.School = strings.TrimSpace(filter.School)
+
+	cursor, err := decodeCursor(cursorToken)
+	if err != nil {
+		return nil, err
+	}
+	limit = clampPageSize(limit)
+
+	users, err := repository.SearchUsers(db.DB, filter, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &UserSearchPage{Users: []UserSummary{}}
+	for _, user := range users {
+		page.Users = append(page.Users, UserSummary{
+			ID:              strconv.FormatUint(user.ID, 10),
+			Name:            user.Name,
+			Username:        user.Username,
+			PhotoURL:        user.PhotoURL,
+			Headline:        user.Headline,
+			JobPosition:     user.JobPosition,
+			JobPositionType: user.JobPositionType,
+		})
+	}
+	if len(users) == limit {
+		last := users[len(users)-1]
+		page.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
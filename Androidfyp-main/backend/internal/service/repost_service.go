@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"wazzafak_back/internal/apperr"
+	"wazzafak_back/internal/contentfilter"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAlreadyReposted = apperr.Conflict("post already reposted")
+	ErrRepostNotFound  = apperr.NotFound("repost not found")
+	ErrEmptyQuote      = apperr.Invalid("a quote must include content or a photo")
+)
+
+// resolveShareTarget returns the post that should be referenced when sharing postID.
+// Sharing a pure repost shares its original instead, so chains never form.
+func resolveShareTarget(postID uint64) (*model.Post, error) {
+	post, err := repository.GetPostByID(db.DB, postID)
+	if err != nil || post.Status != model.PostStatusPublished {
+		return nil, ErrPostNotFound
+	}
+
+	if post.Kind == model.PostKindRepost && post.RepostOfID != nil {
+		original, err := repository.GetPostByID(db.DB, *post.RepostOfID)
+		if err != nil || original.Status != model.PostStatusPublished {
+			return nil, ErrPostNotFound
+		}
+		return original, nil
+	}
+	return post, nil
+}
+
+// =================== Repost (pure share) ===================
+func RepostPost(userID, postID uint64) error {
+	original, err := resolveShareTarget(postID)
+	if err != nil {
+		return err
+	}
+
+	already, err := repository.HasUserReposted(db.DB, userID, original.ID)
+	if err != nil {
+		return err
+	}
+	if already {
+		return ErrAlreadyReposted
+	}
+
+	repost := model.NewRepost_structure(userID, original.ID, "", "")
+	// A parallel tap can repost between the check and the insert
+	return apperr.Unique(repository.CreateRepost(db.DB, &repost), ErrAlreadyReposted)
+}
+
+// =================== Undo a repost ===================
+func UndoRepost(userID, postID uint64) error {
+	original, err := resolveShareTarget(postID)
+	if err != nil {
+		return err
+	}
+
+	repost, err := repository.GetUserRepost(db.DB, userID, original.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRepostNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Undoing a repost is a toggle like unliking, so there is nothing to restore
+	return repository.PurgePost(db.DB, repost.ID)
+}
+
+// =================== Quote post (share with commentary) ===================
+func QuotePost(ctx context.Context, userID, postID uint64, photoURL, content string) (*model.Post, error) {
+	if content == "" && photoURL == "" {
+		return nil, ErrEmptyQuote
+	}
+
+	original, err := resolveShareTarget(postID)
+	if err != nil {
+		return nil, err
+	}
+
+	held, holdReason, err := screenContent(ctx, userID, contentfilter.KindPost, content)
+	if err != nil {
+		return nil, err
+	}
+
+	quote := model.NewRepost_structure(userID, original.ID, photoURL, content)
+	if held {
+		quote.Status = model.PostStatusHidden
+	}
+
+	err = db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.CreateRepost(tx, &quote); err != nil {
+			return err
+		}
+		if held {
+			return fileAutomatedReport(tx, model.ReportTargetPost, quote.ID, holdReason)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// =================== Get reposts count for a post ===================
+func GetRepostsCount(postID uint64) (int, error) {
+	return repository.GetRepostsCount(db.DB, postID)
+}
+
+// =================== Check if user reposted a post ===================
+func HasUserReposted(userID, postID uint64) (bool, error) {
+	return repository.HasUserReposted(db.DB, userID, postID)
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"wazzafak_back/internal/apperr"
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/repository"
+	"wazzafak_back/internal/totp"
+
+	"gorm.io/gorm"
+)
+
+const (
+	twoFactorIssuer   = "Wazzafak"
+	recoveryCodeCount = 10
+
+	// maxTwoFactorAttempts wrong codes lock every check of the second factor
+	// (login, disabling 2FA, deleting the account) for twoFactorLockout
+	maxTwoFactorAttempts = 5
+	twoFactorLockout     = 15 * time.Minute
+)
+
+var (
+	ErrTwoFactorEnabled     = apperr.Conflict("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled  = apperr.Conflict("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp    = apperr.Conflict("start two-factor setup first")
+	ErrInvalidTwoFactorCode = apperr.Forbidden("invalid authentication code")
+	ErrInvalidChallenge     = apperr.Unauthenticated("login challenge is invalid or expired, log in again")
+	ErrTwoFactorLocked      = apperr.New(apperr.CodeRateLimited, "too many wrong codes, try again in 15 minutes")
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TwoFactorSetup is shown once while enrolling; OTPAuthURI goes into a QR code
+type TwoFactorSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// =================== Enrollment ===================
+// SetupTwoFactor creates a fresh secret. 2FA stays off until EnableTwoFactor
+// sees a code from it, so an abandoned setup locks nobody out.
+func SetupTwoFactor(userID uint64) (*TwoFactorSetup, error) {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if twoFactor, err := repository.GetTwoFactor(db.DB, userID); err == nil && twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.SaveTwoFactorSecret(db.DB, userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorSetup{
+		Secret:     secret,
+		OTPAuthURI: totp.ProvisioningURI(twoFactorIssuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms enrollment with a first code and returns the
+// recovery codes, which are never shown again
+func EnableTwoFactor(ctx context.Context, userID uint64, code string) ([]string, error) {
+	twoFactor, err := repository.GetTwoFactor(db.DB, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return nil, ErrTwoFactorNotSetUp
+		}
+		return nil, err
+	}
+	if twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	now := time.Now()
+	step, ok := totp.Validate(twoFactor.Secret, code, now)
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.EnableTwoFactor(db.DB, userID, step, hashes, now); err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return nil, ErrTwoFactorEnabled
+		}
+		return nil, err
+	}
+	recordAuditEvent(ctx, userID, userID, model.Audit2FAEnabled, nil)
+	return codes, nil
+}
+
+func GetTwoFactorStatus(userID uint64) (*TwoFactorStatus, error) {
+	twoFactor, err := repository.GetTwoFactor(db.DB, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return &TwoFactorStatus{}, nil
+		}
+		return nil, err
+	}
+	status := &TwoFactorStatus{Enabled: twoFactor.IsEnabled()}
+	if status.Enabled {
+		if status.RecoveryCodesLeft, err = repository.CountUnusedRecoveryCodes(db.DB, userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// RegenerateRecoveryCodes replaces every recovery code; it takes an
+// authenticator code so a stolen session alone can't do it
+func RegenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error) {
+	twoFactor, err := enabledTwoFactor(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := repository.ReplaceRecoveryCodes(db.DB, userID, hashes); err != nil {
+		return nil, err
+	}
+	recordAuditEvent(ctx, userID, userID, model.AuditRecoveryCodesRegenerated, nil)
+	return codes, nil
+}
+
+// =================== Disable ===================
+// DisableTwoFactor needs the password and an authenticator or recovery code
+func DisableTwoFactor(ctx context.Context, userID uint64, password, code string) error {
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return ErrUserNotFound
+	}
+	if !checkPassword(password, user.Password) {
+		return ErrWrongPassword
+	}
+
+	twoFactor, err := enabledTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+	if err := verifySecondFactor(twoFactor, code); err != nil {
+		return err
+	}
+	if err := repository.DeleteTwoFactor(db.DB, userID); err != nil {
+		return err
+	}
+	recordAuditEvent(ctx, userID, userID, model.Audit2FADisabled, nil)
+	return nil
+}
+
+// AdminDisableTwoFactor is for users locked out of their authenticator and
+// recovery codes. It is recorded in the moderation audit log and in the
+// user's security events.
+func AdminDisableTwoFactor(ctx context.Context, adminID, userID uint64, reason string) error {
+	if _, err := repository.GetUserByID(db.DB, userID); err != nil {
+		return ErrUserNotFound
+	}
+
+	err := db.DB.Transaction(func(tx *gorm.DB) error {
+		if err := repository.DeleteTwoFactor(tx, userID); err != nil {
+			if errors.Is(err, repository.ErrTwoFactorNotFound) {
+				return ErrTwoFactorNotEnabled
+			}
+			return err
+		}
+		entry := model.NewModerationAction(adminID, model.ModerationDisable2FA, model.ReportTargetUser, userID, nil, reason)
+		return repository.CreateModerationAction(tx, &entry)
+	})
+	if err != nil {
+		return err
+	}
+	recordAuditEvent(ctx, userID, adminID, model.Audit2FADisabled, nil)
+	return nil
+}
+
+// =================== Two-step login ===================
+// CompleteTwoFactorLogin exchanges the challenge token from Login plus an
+// authenticator or recovery code for a session token
+func CompleteTwoFactorLogin(ctx context.Context, challengeToken, code string) (string, error) {
+	userID, issuedAt, err := parseChallengeToken(challengeToken)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := repository.GetUserByID(db.DB, userID)
+	if err != nil {
+		return "", ErrInvalidChallenge
+	}
+	if user.IsSuspended(time.Now()) {
+		recordAuditEvent(ctx, userID, userID, model.AuditLoginFailed, map[string]string{"reason": "suspended"})
+		return "", ErrAccountSuspended
+	}
+	// A password change since the challenge was issued cancels it
+	if user.PasswordChangedAt != nil && issuedAt.Before(*user.PasswordChangedAt) {
+		return "", ErrInvalidChallenge
+	}
+
+	twoFactor, err := enabledTwoFactor(userID)
+	if err != nil {
+		return "", ErrInvalidChallenge
+	}
+	if err := verifySecondFactor(twoFactor, code); err != nil {
+		if errors.Is(err, ErrTwoFactorLocked) {
+			recordAuditEvent(ctx, userID, userID, model.AuditLoginFailed, map[string]string{"reason": "two_factor_locked"})
+			return "", err
+		}
+		// Not signed in yet, so a bad code fails authentication rather than
+		// being forbidden like it is for a signed-in user
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			recordAuditEvent(ctx, userID, userID, model.AuditLoginFailed, map[string]string{"reason": "wrong_two_factor_code"})
+			return "", apperr.Wrap(err, apperr.CodeUnauthenticated, "")
+		}
+		return "", err
+	}
+
+	token, err := GenerateJWT(strconv.FormatUint(userID, 10))
+	if err != nil {
+		return "", err
+	}
+	recordAuditEvent(ctx, userID, userID, model.AuditLoginSucceeded, map[string]string{"method": "two_factor"})
+	return token, nil
+}
+
+func enabledTwoFactor(userID uint64) (*model.TwoFactor, error) {
+	twoFactor, err := repository.GetTwoFactor(db.DB, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrTwoFactorNotFound) {
+			return nil, ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+	if !twoFactor.IsEnabled() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+	return twoFactor, nil
+}
+
+// verifySecondFactor checks code against the account's attempt limit: the
+// attempt is counted before the check and the count cleared after a success
+func verifySecondFactor(twoFactor *model.TwoFactor, code string) error {
+	now := time.Now()
+	allowed, err := repository.ConsumeTwoFactorAttempt(db.DB, twoFactor.UserID, maxTwoFactorAttempts, now.Add(twoFactorLockout), now)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTwoFactorLocked
+	}
+	if err := checkSecondFactor(twoFactor, code); err != nil {
+		return err
+	}
+	return repository.ResetTwoFactorAttempts(db.DB, twoFactor.UserID)
+}
+
+// checkSecondFactor accepts either a 6-digit authenticator code or a recovery code
+func checkSecondFactor(twoFactor *model.TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return checkTOTP(twoFactor, code)
+	}
+
+	used, err := repository.UseRecoveryCode(db.DB, twoFactor.UserID, hashRecoveryCode(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// checkTOTP validates an authenticator code and burns its time step
+func checkTOTP(twoFactor *model.TwoFactor, code string) error {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	fresh, err := repository.UseTOTPStep(db.DB, twoFactor.UserID, step)
+	if err != nil {
+		return err
+	}
+	if !fresh {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// generateRecoveryCodes returns the codes to show (xxxx-xxxx) and their hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw := make([]byte, 5) // 40 bits -> 8 base32 characters
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		code := encoded[:4] + "-" + encoded[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode ignores case, spaces and dashes so codes can be typed loosely
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
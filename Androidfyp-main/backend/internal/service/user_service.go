@@ -1,8 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
 
+	"wazzafak_back/internal/apperr"
 	database "wazzafak_back/internal/database"
 	"wazzafak_back/internal/model"
 	"wazzafak_back/internal/repository"
@@ -11,19 +13,21 @@ import (
 )
 
 var (
-	ErrUsernameExists = errors.New("username already exists")
-	ErrEmailExists    = errors.New("email already exists")
+	ErrUsernameExists = apperr.Conflict("username already exists")
+	ErrEmailExists    = apperr.Conflict("email already exists")
 )
 
 func CreateUser(username, name, email, password, jobPosition, jobPositionType string) (*model.User, error) {
 	var existingUser model.User
 
-	// Check username
-	if err := database.DB.Where("username = ?", username).First(&existingUser).Error; err == nil {
-		return nil, ErrUsernameExists
-	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+	// Check username, including handles still reserved after a change
+	taken, err := repository.IsUsernameTaken(database.DB, username, 0)
+	if err != nil {
 		return nil, err
 	}
+	if taken {
+		return nil, ErrUsernameExists
+	}
 
 	// Check email
 	if err := database.DB.Where("email = ?", email).First(&existingUser).Error; err == nil {
@@ -38,36 +42,58 @@ func CreateUser(username, name, email, password, jobPosition, jobPositionType st
 	}
 
 	if err := repository.CreateUser_indatabase(database.DB, user); err != nil {
-		return nil, err
+		return nil, apperr.Unique(err, ErrAlreadyRegistered)
 	}
 
 	return user, nil
 }
 
-func UpdateUserPhoto(userID uint64, photoURL string) error {
-	return repository.UpdateUserPhoto(database.DB, userID, photoURL)
+func UpdateUserPhoto(ctx context.Context, userID uint64, photoURL string) error {
+	if err := repository.UpdateUserPhoto(database.DB, userID, photoURL); err != nil {
+		return translateUserErr(err)
+	}
+	recordAuditEvent(ctx, userID, userID, model.AuditPhotoChanged, nil)
+	return nil
 }
 
-func DeleteUserPhoto(userID uint64, defaultPhotoURL string) error {
-	return repository.UpdateUserPhoto(database.DB, userID, defaultPhotoURL)
+func DeleteUserPhoto(ctx context.Context, userID uint64, defaultPhotoURL string) error {
+	if err := repository.UpdateUserPhoto(database.DB, userID, defaultPhotoURL); err != nil {
+		return translateUserErr(err)
+	}
+	recordAuditEvent(ctx, userID, userID, model.AuditPhotoRemoved, nil)
+	return nil
 }
 
-func UpdateUserName(userID uint64, name string) error {
-	return repository.UpdateUserName(database.DB, userID, name)
+func UpdateUserName(ctx context.Context, userID uint64, name string) error {
+	if err := repository.UpdateUserName(database.DB, userID, name); err != nil {
+		return translateUserErr(err)
+	}
+	recordAuditEvent(ctx, userID, userID, model.AuditNameChanged, nil)
+	return nil
 }
 
 func GetUserByID(userID uint64) (*model.User, error) {
-	return repository.GetUserByID(database.DB, userID)
+	user, err := repository.GetUserByID(database.DB, userID)
+	return user, translateUserErr(err)
 }
 
 func GetUserByUsername(username string) (*model.User, error) {
-	return repository.GetUserByUsername(database.DB, username)
+	user, err := repository.GetUserByUsername(database.DB, username)
+	return user, translateUserErr(err)
 }
 
 func GetUserPhotoByID(userID uint64) (string, error) {
 	user, err := repository.GetUserByID(database.DB, userID)
 	if err != nil {
-		return "", err
+		return "", translateUserErr(err)
 	}
 	return user.PhotoURL, nil
 }
+
+// translateUserErr turns the repository's missing-user error into ErrUserNotFound
+func translateUserErr(err error) error {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	return err
+}
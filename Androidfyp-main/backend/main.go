@@ -1,15 +1,25 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	db "wazzafak_back/internal/database"
-	"wazzafak_back/internal/handler"
+	"wazzafak_back/internal/logging"
 	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/ratelimit"
+	"wazzafak_back/internal/realtime"
+	"wazzafak_back/internal/service"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/joho/godotenv"
 )
 
@@ -18,90 +28,73 @@ func main() {
 	db.InitIDGenerator()
 
 	// Load environment variables from .env
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("No .env file found, assuming environment variables are set externally")
+	envErr := godotenv.Load()
+
+	// Structured logs (LOG_LEVEL, LOG_FORMAT)
+	logging.Init()
+	if envErr != nil {
+		slog.Info("No .env file found, assuming environment variables are set externally")
 	}
 
 	// Connect to database
 	if err := db.Connect(); err != nil {
-		log.Fatalf("Failed to connect to DB: %v", err)
+		fatal("Failed to connect to DB", err)
+	}
+
+	// Create tables owned by this service if they don't exist yet
+	if err := model.AutoMigrate(db.DB); err != nil {
+		fatal("Failed to migrate DB", err)
 	}
 
-	// Check that essential env variables are set
-	emailFrom := os.Getenv("EMAIL_FROM")
-	apiKey := os.Getenv("BREVO_API_KEY")
+	// Build the content filter (word list, heuristics, classifier)
+	if err := service.InitContentFilter(); err != nil {
+		fatal("Failed to set up content filter", err)
+	}
+
+	// Pick the mail provider (Brevo, SMTP or a local log sink)
+	if err := service.InitMailer(); err != nil {
+		fatal("Failed to set up mailer", err)
+	}
 
-	if emailFrom == "" || apiKey == "" {
-		log.Fatal("EMAIL_FROM and BREVO_API_KEY must be set")
+	slog.Info("Database connected, ready to go!")
+
+	// Background workers run until shutdown cancels workersCtx
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	goWorker := func(run func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			run()
+		}()
 	}
 
-	log.Println("Database connected, ready to go!")
-
-	// Create router
-	r := chi.NewRouter()
-
-	// Public routes
-	r.Post("/login", handler.LoginHandler)
-	r.Get("/health", handler.HealthCheckHandler)
-
-	// Email verification & registration routes (public)
-	r.Post("/send-verification-code", handler.SendVerificationCodeHandler)
-	r.Post("/verify-email-code", handler.VerifyEmailCodeHandler)
-	r.Post("/complete-registration", handler.CompleteRegistrationHandler)
-
-	// Password reset routes (public)
-	r.Post("/send-password-reset", handler.SendPasswordResetCodeHandler)
-	r.Post("/reset-password", handler.ResetPasswordHandler)
-
-	// Protected routes (require auth)
-	r.Route("/", func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware) // Auth middleware applied here
-		r.Get("/users/me/posts", handler.GetMyPostsHandler)
-
-		// User routes
-		r.Get("/users/me", handler.GetUserProfile)                      // Get authenticated user's profile
-		r.Get("/users/me/followers", handler.GetMyFollowers)            // Get authenticated user's followers
-		r.Get("/users/me/following", handler.GetMyFollowing)            // Get authenticated user's following
-		r.Get("/users/id/{userID}", handler.GetUserByIDHandler)         // Get user by ID
-		r.Get("/users/id/{userID}/photo", handler.GetUserPhotoByID)     // Get user photo by ID
-		r.Get("/users/id/{userID}/followers", handler.GetFollowersByID) // Get user's followers by ID
-		r.Get("/users/id/{userID}/following", handler.GetFollowingByID) // Get user's following by ID
-		r.Put("/users/name", handler.UpdateUserName)
-		r.Put("/users/photo", handler.UpdatePhoto)
-		r.Delete("/users/photo", handler.DeletePhoto)
-		r.Get("/posts/{postID}/likes/users", handler.GetPostLikesHandler)
-
-		// User profile by username (public info)
-		r.Get("/users/{username}", handler.GetUserByUsername)
-		r.Get("/users/{username}/posts", handler.GetUserPosts)
-		r.Get("/users/{username}/followers", handler.GetFollowers)
-		r.Get("/users/{username}/following", handler.GetFollowing)
-
-		// Post routes
-		r.Post("/posts", handler.CreatePost)
-		r.Get("/posts/{postID}", handler.GetPost)
-		r.Delete("/posts/{postID}", handler.DeletePost)
-		r.Get("/posts/all", handler.GetAllPostsHandler)
-		r.Get("/posts/{postID}/comments", handler.GetCommentsForPostHandler)
-		// Follow/unfollow
-		r.Post("/follow/{userID}", handler.FollowHandler)
-		r.Delete("/unfollow/{userID}", handler.UnfollowHandler)
-
-		// Post interactions
-		r.Post("/posts/{postID}/like", handler.LikePostHandler)
-		r.Post("/posts/{postID}/unlike", handler.UnlikePostHandler)
-		r.Post("/posts/{postID}/comment", handler.CommentOnPostHandler)
-		r.Delete("/posts/{postID}/comments/{commentID}", handler.DeleteCommentFromPostHandler)
-
-		// Feed
-		r.Get("/users/feed", handler.GetFeedHandler)
-		// Notification routes
-		r.Get("/notifications", handler.GetNotificationsHandler)
-		r.Get("/notifications/unread-count", handler.GetUnreadCountHandler)
-		r.Put("/notifications/{notificationID}/read", handler.MarkNotificationReadHandler)
-		r.Put("/notifications/mark-all-read", handler.MarkAllNotificationsReadHandler)
-	})
+	// Publish scheduled posts and close polls in the background
+	goWorker(func() { service.RunPostScheduler(workersCtx, time.Minute) })
+
+	// Deliver queued emails with retries
+	goWorker(func() { service.RunMailOutbox(workersCtx, 10*time.Second) })
+
+	// Hard-delete posts and comments once their restore window is over
+	goWorker(func() { service.RunDeletedContentPurge(workersCtx, time.Minute) })
+
+	// Purge accounts whose deletion grace period is over
+	goWorker(func() { service.RunAccountDeletion(workersCtx, time.Hour) })
+
+	// Forget idempotency keys once their responses are no longer replayed
+	goWorker(func() { service.RunIdempotencyKeyPurge(workersCtx, time.Hour) })
+
+	// Rate limits are counted in memory unless replicas have to share them
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if os.Getenv("RATE_LIMIT_STORE") == "postgres" {
+		postgresStore := ratelimit.NewPostgresStore(db.DB)
+		goWorker(func() { postgresStore.RunPurger(workersCtx, 10*time.Minute) })
+		limitStore = postgresStore
+	}
+	limiter := ratelimit.NewLimiter(limitStore)
+	middleware.TrustedProxyHops = trustedProxyHops()
+
+	r := newRouter(limiter)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -109,8 +102,86 @@ func main() {
 		port = "8080"
 	}
 
-	log.Printf("Server running on port %s", port)
-	if err := http.ListenAndServe(":"+port, r); err != nil {
-		log.Fatalf("Failed to start HTTP server: %v", err)
+	// Requests still running when the shutdown deadline passes are cancelled,
+	// so slow outbound calls (the content classifier) give up too
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		BaseContext:       func(net.Listener) context.Context { return requestsCtx },
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      60 * time.Second, // the data export is the slowest response
+		IdleTimeout:       2 * time.Minute,
+	}
+	// Shutdown doesn't track hijacked WebSockets; drop them so the app
+	// reconnects to another replica
+	server.RegisterOnShutdown(realtime.Default.CloseAll)
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	slog.Info("Server running", "port", port)
+
+	select {
+	case err := <-serverErr:
+		fatal("Failed to start HTTP server", err)
+	case <-signals.Done():
 	}
+	stopSignals() // a second signal kills the process right away
+	slog.Info("Shutting down")
+
+	// Fail readiness first and give the load balancer time to notice
+	service.StartDraining()
+	time.Sleep(drainDelay())
+
+	// Stop accepting connections and wait for in-flight requests
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("HTTP server did not drain in time", "error", err)
+	}
+	cancelRequests()
+
+	// Workers finish the batch they are on; nothing uses the DB after this
+	stopWorkers()
+	workers.Wait()
+	if err := db.Close(); err != nil {
+		slog.Error("Failed to close DB", "error", err)
+	}
+	slog.Info("Shutdown complete")
+}
+
+// shutdownTimeout bounds how long in-flight requests get to finish
+const shutdownTimeout = 30 * time.Second
+
+// drainDelay is how long a stopping replica keeps serving while failing
+// /health/ready (SHUTDOWN_DRAIN_DELAY, e.g. "5s"; none by default)
+func drainDelay() time.Duration {
+	delay, err := time.ParseDuration(os.Getenv("SHUTDOWN_DRAIN_DELAY"))
+	if err != nil {
+		return 0
+	}
+	return delay
+}
+
+// trustedProxyHops reads TRUST_PROXY_HEADERS=true and TRUSTED_PROXY_HOPS,
+// the number of proxies in front of the server (1 when unset)
+func trustedProxyHops() int {
+	if os.Getenv("TRUST_PROXY_HEADERS") != "true" {
+		return 0
+	}
+	hops, err := strconv.Atoi(os.Getenv("TRUSTED_PROXY_HOPS"))
+	if err != nil || hops < 1 {
+		return 1
+	}
+	return hops
+}
+
+// fatal logs why the service can't start and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
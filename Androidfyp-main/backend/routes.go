@@ -0,0 +1,268 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"wazzafak_back/internal/handler"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/openapi"
+	"wazzafak_back/internal/ratelimit"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newRouter wires every route. handler.APIDoc describes the same routes;
+// routes_test.go fails when the two drift apart.
+func newRouter(limiter *ratelimit.Limiter) *chi.Mux {
+	perIP := func(name string, limit int64, window time.Duration) func(http.Handler) http.Handler {
+		return middleware.RateLimit(limiter, ratelimit.Rule{Name: name + ":ip", Limit: limit, Window: window}, middleware.ClientIP)
+	}
+	perEmail := func(name string, limit int64, window time.Duration) func(http.Handler) http.Handler {
+		return middleware.RateLimit(limiter, ratelimit.Rule{Name: name + ":email", Limit: limit, Window: window}, middleware.AccountKey("email"))
+	}
+	perUser := func(name string, limit int64, window time.Duration) func(http.Handler) http.Handler {
+		return middleware.RateLimit(limiter, ratelimit.Rule{Name: name + ":user", Limit: limit, Window: window}, middleware.UserKey)
+	}
+	perChallenge := func(name string, limit int64, window time.Duration) func(http.Handler) http.Handler {
+		return middleware.RateLimit(limiter, ratelimit.Rule{Name: name + ":user", Limit: limit, Window: window}, middleware.ChallengeKey)
+	}
+
+	r := chi.NewRouter()
+
+	// Request IDs, access log and latency metrics for every request
+	r.Use(middleware.Observe)
+
+	// Reject request bodies that don't match the OpenAPI document
+	r.Use(openapi.ValidateRequests(handler.APIDoc()))
+
+	// Public routes
+	r.With(perIP("login", 30, 15*time.Minute), perEmail("login", 10, 15*time.Minute)).
+		Post("/login", handler.LoginHandler)
+	r.With(perIP("login-2fa", 30, 15*time.Minute), perChallenge("login-2fa", 10, 15*time.Minute)).
+		Post("/login/2fa", handler.TwoFactorLoginHandler) // Second step for accounts with 2FA
+	r.Get("/health", handler.HealthCheckHandler)
+	r.Get("/health/live", handler.LivenessHandler)
+	r.Get("/health/ready", handler.ReadinessHandler)
+	r.Get("/openapi.json", handler.OpenAPIHandler)
+	r.Get("/metrics", handler.MetricsHandler)
+
+	// Email verification & registration routes (public)
+	r.With(perIP("send-verification", 10, time.Hour), perEmail("send-verification", 3, 15*time.Minute)).
+		Post("/send-verification-code", handler.SendVerificationCodeHandler)
+	r.With(perIP("verify-email", 30, 15*time.Minute), perEmail("verify-email", 10, 15*time.Minute)).
+		Post("/verify-email-code", handler.VerifyEmailCodeHandler)
+	r.With(perIP("complete-registration", 10, time.Hour)).
+		Post("/complete-registration", handler.CompleteRegistrationHandler)
+
+	// Password reset routes (public)
+	r.With(perIP("send-reset", 10, time.Hour), perEmail("send-reset", 3, 15*time.Minute)).
+		Post("/send-password-reset", handler.SendPasswordResetCodeHandler)
+	r.With(perIP("reset-password", 30, 15*time.Minute), perEmail("reset-password", 10, 15*time.Minute)).
+		Post("/reset-password", handler.ResetPasswordHandler)
+
+	// Protected routes (require auth)
+	r.Route("/", func(r chi.Router) {
+		r.Use(middleware.AuthMiddleware) // Auth middleware applied here
+		r.Use(middleware.Idempotency)    // Replays retried writes that carry an Idempotency-Key
+		r.Get("/users/me/posts", handler.GetMyPostsHandler)
+		r.Get("/users/me/drafts", handler.GetMyDraftsHandler)
+
+		// User routes
+		r.Get("/users/me", handler.GetUserProfile)                      // Get authenticated user's profile
+		r.Get("/users/me/followers", handler.GetMyFollowers)            // Get authenticated user's followers
+		r.Get("/users/me/following", handler.GetMyFollowing)            // Get authenticated user's following
+		r.Get("/users/id/{userID}", handler.GetUserByIDHandler)         // Get user by ID
+		r.Get("/users/id/{userID}/photo", handler.GetUserPhotoByID)     // Get user photo by ID
+		r.Get("/users/id/{userID}/followers", handler.GetFollowersByID) // Get user's followers by ID
+		r.Get("/users/id/{userID}/following", handler.GetFollowingByID) // Get user's following by ID
+		r.Put("/users/name", handler.UpdateUserName)
+		r.Put("/users/photo", handler.UpdatePhoto)
+		r.Delete("/users/photo", handler.DeletePhoto)
+		r.Put("/users/me/username", handler.ChangeUsernameHandler)
+		r.With(perUser("email-change", 3, 15*time.Minute)).
+			Post("/users/me/email", handler.RequestEmailChangeHandler) // Sends a code to the new address
+		r.With(perUser("email-change-verify", 10, 15*time.Minute)).
+			Post("/users/me/email/verify", handler.ConfirmEmailChangeHandler)
+		r.With(perUser("change-password", 10, 15*time.Minute)).
+			Put("/users/me/password", handler.ChangePasswordHandler) // Signs out other sessions
+
+		// Two-factor authentication
+		r.Get("/users/me/2fa", handler.GetTwoFactorStatusHandler)
+		r.Post("/users/me/2fa/setup", handler.SetupTwoFactorHandler)
+		r.With(perUser("2fa-code", 10, 15*time.Minute)).Group(func(r chi.Router) {
+			r.Post("/users/me/2fa/enable", handler.EnableTwoFactorHandler)
+			r.Post("/users/me/2fa/recovery-codes", handler.RegenerateRecoveryCodesHandler)
+			r.Post("/users/me/2fa/disable", handler.DisableTwoFactorHandler)
+		})
+
+		// Account deletion and data export
+		r.With(perUser("delete-account", 5, 15*time.Minute)).
+			Delete("/users/me", handler.DeleteAccountHandler) // Purged after a grace period
+		r.Post("/users/me/deletion/cancel", handler.CancelAccountDeletionHandler)
+		r.With(perUser("export", 5, time.Hour)).
+			Get("/users/me/export", handler.ExportAccountHandler)
+		r.Get("/users/me/security-events", handler.GetMySecurityEventsHandler)
+		r.Get("/posts/{postID}/likes/users", handler.GetPostLikesHandler)
+
+		// Bookmarks (private to the owner)
+		r.Get("/users/me/bookmarks", handler.GetMyBookmarksHandler)
+		r.Get("/users/me/bookmarks/collections", handler.GetBookmarkCollectionsHandler)
+		r.Post("/users/me/bookmarks/collections", handler.CreateBookmarkCollectionHandler)
+		r.Put("/users/me/bookmarks/collections/{collectionID}", handler.RenameBookmarkCollectionHandler)
+		r.Delete("/users/me/bookmarks/collections/{collectionID}", handler.DeleteBookmarkCollectionHandler)
+
+		// Professional profile
+		r.Get("/users/me/profile", handler.GetMyProfileHandler)
+		r.Put("/users/me/profile", handler.UpdateAboutHandler)
+		r.Put("/users/me/profile/skills", handler.ReplaceSkillsHandler)
+		r.Post("/users/me/profile/skills", handler.AddSkillHandler)
+		r.Delete("/users/me/profile/skills/{skill}", handler.RemoveSkillHandler)
+		r.Post("/users/me/profile/experience", handler.AddExperienceHandler)
+		r.Put("/users/me/profile/experience/{entryID}", handler.UpdateExperienceHandler)
+		r.Delete("/users/me/profile/experience/{entryID}", handler.DeleteExperienceHandler)
+		r.Post("/users/me/profile/education", handler.AddEducationHandler)
+		r.Put("/users/me/profile/education/{entryID}", handler.UpdateEducationHandler)
+		r.Delete("/users/me/profile/education/{entryID}", handler.DeleteEducationHandler)
+		r.Post("/users/me/profile/links", handler.AddLinkHandler)
+		r.Put("/users/me/profile/links/{entryID}", handler.UpdateLinkHandler)
+		r.Delete("/users/me/profile/links/{entryID}", handler.DeleteLinkHandler)
+		r.Get("/users/search", handler.SearchUsersHandler)
+		r.Post("/users/id/{userID}/skills/{skill}/endorse", handler.EndorseSkillHandler)
+		r.Delete("/users/id/{userID}/skills/{skill}/endorse", handler.UnendorseSkillHandler)
+
+		// User profile by username (public info)
+		r.Get("/users/{username}", handler.GetUserByUsername)
+		r.Get("/users/{username}/posts", handler.GetUserPosts)
+		r.Get("/users/{username}/followers", handler.GetFollowers)
+		r.Get("/users/{username}/following", handler.GetFollowing)
+
+		// Post routes
+		r.Post("/posts", handler.CreatePost)
+		r.Get("/posts/{postID}", handler.GetPost)
+		r.Put("/posts/{postID}", handler.UpdatePostHandler)
+		r.Delete("/posts/{postID}", handler.DeletePost)
+		r.Post("/posts/{postID}/restore", handler.RestorePostHandler) // Undo a delete within the restore window
+		r.Post("/posts/{postID}/publish", handler.PublishPostHandler)
+		r.Get("/posts/all", handler.GetAllPostsHandler)
+		r.Get("/posts/{postID}/comments", handler.GetCommentsForPostHandler)
+		// Follow/unfollow
+		r.Post("/follow/{userID}", handler.FollowHandler)
+		r.Delete("/unfollow/{userID}", handler.UnfollowHandler)
+
+		// Post interactions
+		r.Post("/posts/{postID}/like", handler.LikePostHandler)
+		r.Post("/posts/{postID}/unlike", handler.UnlikePostHandler)
+		r.Post("/posts/{postID}/bookmark", handler.BookmarkPostHandler)
+		r.Delete("/posts/{postID}/bookmark", handler.RemoveBookmarkHandler)
+		r.Post("/posts/{postID}/repost", handler.RepostHandler)
+		r.Delete("/posts/{postID}/repost", handler.UndoRepostHandler)
+		r.Post("/posts/{postID}/quote", handler.QuotePostHandler)
+		r.Post("/posts/{postID}/vote", handler.VotePollHandler)
+		r.Post("/posts/{postID}/comment", handler.CommentOnPostHandler)
+		r.Delete("/posts/{postID}/comments/{commentID}", handler.DeleteCommentFromPostHandler)
+		r.Post("/posts/{postID}/comments/{commentID}/restore", handler.RestoreCommentHandler)
+
+		// Reporting
+		r.Post("/posts/{postID}/report", handler.ReportPostHandler)
+		r.Post("/posts/{postID}/comments/{commentID}/report", handler.ReportCommentHandler)
+		r.Post("/users/id/{userID}/report", handler.ReportUserHandler)
+
+		// Blocking
+		r.Get("/users/me/blocks", handler.GetMyBlocksHandler)
+		r.Post("/users/id/{userID}/block", handler.BlockUserHandler)
+		r.Delete("/users/id/{userID}/block", handler.UnblockUserHandler)
+
+		// Direct messages
+		r.Get("/ws", handler.WebSocketHandler)
+		r.Get("/conversations", handler.GetConversationsHandler)
+		r.Post("/conversations", handler.StartConversationHandler)
+		r.Get("/conversations/requests", handler.GetMessageRequestsHandler)
+		r.Get("/conversations/unread-count", handler.GetUnreadMessagesCountHandler)
+		r.Get("/conversations/{conversationID}", handler.GetConversationHandler)
+		r.Get("/conversations/{conversationID}/messages", handler.GetMessagesHandler)
+		r.Post("/conversations/{conversationID}/messages", handler.SendMessageHandler)
+		r.Post("/conversations/{conversationID}/read", handler.MarkConversationReadHandler)
+		r.Post("/conversations/{conversationID}/accept", handler.AcceptMessageRequestHandler)
+		r.Post("/conversations/{conversationID}/decline", handler.DeclineMessageRequestHandler)
+		r.Delete("/conversations/{conversationID}/members/me", handler.LeaveConversationHandler)
+
+		// Jobs — managing a job or its applicants needs a role for it, see middleware.RequireJobRole
+		jobRecruiter := middleware.RequireJobRole(model.CompanyRoleRecruiter)
+		jobViewer := middleware.RequireJobRole(model.CompanyRoleViewer)
+		applicationRecruiter := middleware.RequireApplicationRole(model.CompanyRoleRecruiter)
+
+		r.Get("/jobs", handler.SearchJobsHandler)
+		r.Post("/jobs", handler.CreateJobHandler)
+		r.Get("/jobs/{jobID}", handler.GetJobHandler)
+		r.With(jobRecruiter).Put("/jobs/{jobID}", handler.UpdateJobHandler)
+		r.With(jobRecruiter).Post("/jobs/{jobID}/close", handler.CloseJobHandler)
+		r.Post("/jobs/{jobID}/save", handler.SaveJobHandler)
+		r.Delete("/jobs/{jobID}/save", handler.UnsaveJobHandler)
+		r.Get("/users/me/jobs", handler.GetMyJobsHandler)
+		r.Get("/users/me/saved-jobs", handler.GetSavedJobsHandler)
+
+		// Job applications
+		r.Post("/jobs/{jobID}/apply", handler.ApplyToJobHandler)
+		r.With(jobViewer).Get("/jobs/{jobID}/applications", handler.GetJobApplicantsHandler)
+		r.Get("/users/me/applications", handler.GetMyApplicationsHandler)
+		r.Get("/applications/{applicationID}", handler.GetApplicationHandler)
+		r.Post("/applications/{applicationID}/withdraw", handler.WithdrawApplicationHandler)
+		r.With(applicationRecruiter).Put("/applications/{applicationID}/stage", handler.ChangeApplicationStageHandler)
+		r.With(applicationRecruiter).Put("/applications/{applicationID}/notes", handler.UpdateApplicationNotesHandler)
+
+		// Companies
+		companyOwner := middleware.RequireCompanyRole(model.CompanyRoleOwner)
+		companyRecruiter := middleware.RequireCompanyRole(model.CompanyRoleRecruiter)
+		companyMember := middleware.RequireCompanyRole(model.CompanyRoleViewer)
+
+		r.Post("/companies", handler.CreateCompanyHandler)
+		r.With(perUser("accept-invite", 10, 15*time.Minute)).
+			Post("/companies/invites/accept", handler.AcceptCompanyInviteHandler)
+		r.Get("/users/me/companies", handler.GetMyCompaniesHandler)
+		r.Get("/companies/{companyID}", handler.GetCompanyHandler)
+		r.With(companyOwner).Put("/companies/{companyID}", handler.UpdateCompanyHandler)
+		r.Get("/companies/{companyID}/jobs", handler.GetCompanyJobsHandler)
+		r.With(companyRecruiter).Post("/companies/{companyID}/jobs", handler.CreateCompanyJobHandler)
+		r.With(companyMember).Get("/companies/{companyID}/members", handler.GetCompanyMembersHandler)
+		r.Delete("/companies/{companyID}/members/me", handler.LeaveCompanyHandler)
+		r.With(companyOwner).Put("/companies/{companyID}/members/{userID}", handler.SetCompanyMemberRoleHandler)
+		r.With(companyOwner).Delete("/companies/{companyID}/members/{userID}", handler.RemoveCompanyMemberHandler)
+		r.With(companyOwner).Get("/companies/{companyID}/invites", handler.GetCompanyInvitesHandler)
+		r.With(companyOwner).Post("/companies/{companyID}/invites", handler.InviteCompanyMemberHandler)
+		r.With(companyOwner).Delete("/companies/{companyID}/invites/{inviteID}", handler.RevokeCompanyInviteHandler)
+
+		// Feed
+		r.Get("/users/feed", handler.GetFeedHandler)
+		// Notification routes
+		r.Get("/notifications", handler.GetNotificationsHandler)
+		r.Get("/notifications/unread-count", handler.GetUnreadCountHandler)
+		r.Put("/notifications/{notificationID}/read", handler.MarkNotificationReadHandler)
+		r.Put("/notifications/mark-all-read", handler.MarkAllNotificationsReadHandler)
+
+		// Moderation (admins only)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middleware.AdminOnly)
+			r.Get("/reports", handler.GetReportsHandler)
+			r.Post("/reports/{reportID}/dismiss", handler.DismissReportHandler)
+			r.Post("/posts/{postID}/hide", handler.HidePostHandler)
+			r.Post("/posts/{postID}/unhide", handler.UnhidePostHandler)
+			r.Delete("/posts/{postID}", handler.RemovePostHandler)
+			r.Post("/comments/{commentID}/hide", handler.HideCommentHandler)
+			r.Post("/comments/{commentID}/unhide", handler.UnhideCommentHandler)
+			r.Delete("/comments/{commentID}", handler.RemoveCommentHandler)
+			r.Post("/users/{userID}/suspend", handler.SuspendUserHandler)
+			r.Post("/users/{userID}/unsuspend", handler.UnsuspendUserHandler)
+			r.Post("/users/{userID}/recruiter", handler.GrantRecruiterHandler)
+			r.Delete("/users/{userID}/recruiter", handler.RevokeRecruiterHandler)
+			r.Delete("/users/{userID}/2fa", handler.AdminDisableTwoFactorHandler)
+			r.Get("/audit-log", handler.GetModerationLogHandler)
+			r.Get("/security-events", handler.GetSecurityEventsHandler)
+			r.Get("/outbox/dead", handler.GetDeadEmailsHandler)
+			r.Post("/outbox/{emailID}/retry", handler.RetryDeadEmailHandler)
+		})
+	})
+
+	return r
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	db "wazzafak_back/internal/database"
+	"wazzafak_back/internal/handler"
+	"wazzafak_back/internal/middleware"
+	"wazzafak_back/internal/model"
+	"wazzafak_back/internal/openapi"
+	"wazzafak_back/internal/ratelimit"
+	"wazzafak_back/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func testRouter() *chi.Mux {
+	return newRouter(ratelimit.NewLimiter(ratelimit.NewMemoryStore()))
+}
+
+// Every route the router serves is documented, and every documented route of
+// this service is served
+func TestRoutesMatchAPIDoc(t *testing.T) {
+	doc := handler.APIDoc()
+
+	served := map[string]bool{}
+	err := chi.Walk(testRouter(), func(method, route string, h http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		route = strings.Replace(route, "/*/", "/", -1)
+		served[method+" "+route] = true
+		if doc.Operation(method, route) == nil {
+			t.Errorf("%s %s is served but not documented", method, route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, route := range doc.Routes() {
+		if len(route.Operation.Servers) > 0 {
+			continue // the interview AI service
+		}
+		if !served[route.Method+" "+route.Path] {
+			t.Errorf("%s %s is documented but not served", route.Method, route.Path)
+		}
+	}
+}
+
+// The document itself is sound: references resolve and every operation says
+// what it answers on success
+func TestAPIDocIsComplete(t *testing.T) {
+	doc := handler.APIDoc()
+
+	var check func(where string, s *openapi.Schema)
+	check = func(where string, s *openapi.Schema) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			if _, ok := doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]; !ok {
+				t.Errorf("%s: %s does not resolve", where, s.Ref)
+			}
+		}
+		for _, prop := range s.Properties {
+			check(where, prop)
+		}
+		for _, part := range s.AllOf {
+			check(where, part)
+		}
+		check(where, s.Items)
+		check(where, s.AdditionalProperties)
+	}
+
+	for name, schema := range doc.Components.Schemas {
+		check("schema "+name, schema)
+	}
+	for _, route := range doc.Routes() {
+		where := route.Method + " " + route.Path
+		if len(route.Operation.Responses) < 2 {
+			t.Errorf("%s documents no success response", where)
+		}
+		if body := route.Operation.RequestBody; body != nil {
+			for _, media := range body.Content {
+				check(where, media.Schema)
+			}
+		}
+		for _, response := range route.Operation.Responses {
+			for _, media := range response.Content {
+				check(where, media.Schema)
+			}
+		}
+	}
+}
+
+type contractCase struct {
+	method, path, token, body string
+	idempotencyKey            string
+	status                    int
+}
+
+// serve runs a request through the router and fails when the response isn't
+// the one the document gives its operation
+func serve(t *testing.T, router http.Handler, c contractCase) *httptest.ResponseRecorder {
+	t.Helper()
+	doc := handler.APIDoc()
+
+	req := httptest.NewRequest(c.method, c.path, strings.NewReader(c.body))
+	if c.body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.idempotencyKey != "" {
+		req.Header.Set(middleware.IdempotencyKeyHeader, c.idempotencyKey)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	where := c.method + " " + c.path
+	if c.status != 0 && rec.Code != c.status {
+		t.Errorf("%s: status %d, want %d: %s", where, rec.Code, c.status, rec.Body.String())
+	}
+	op, _ := doc.Find(c.method, req.URL.Path)
+	if op == nil {
+		t.Errorf("%s: no documented operation", where)
+		return rec
+	}
+	if err := doc.ValidateResponse(op, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()); err != nil {
+		t.Errorf("%s: response drifts from the document: %v\n%s", where, err, rec.Body.String())
+	}
+	return rec
+}
+
+// Responses that don't need the database
+func TestResponsesMatchAPIDoc(t *testing.T) {
+	router := testRouter()
+
+	cases := []contractCase{
+		{method: "GET", path: "/health", status: http.StatusOK},
+		{method: "GET", path: "/health/live", status: http.StatusOK},
+		{method: "GET", path: "/openapi.json", status: http.StatusOK},
+
+		// Rejected by ValidateRequests before the handler runs
+		{method: "POST", path: "/login", body: `{"email": "a@b.c"}`, status: http.StatusBadRequest},
+		{method: "POST", path: "/login", body: `{"email": "a@b.c", "password": 5}`, status: http.StatusBadRequest},
+		{method: "POST", path: "/login", body: `{"email": "a@b.c", "password": ""}`, status: http.StatusBadRequest},
+		{method: "POST", path: "/login", body: `{"email": `, status: http.StatusBadRequest},
+		{method: "POST", path: "/send-verification-code", status: http.StatusBadRequest},
+
+		// Rejected by AuthMiddleware
+		{method: "GET", path: "/users/me", status: http.StatusUnauthorized},
+		{method: "GET", path: "/posts/1", token: "not-a-jwt", status: http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		serve(t, router, c)
+	}
+}
+
+// Responses of a signed-in user, against a scratch database named by
+// TEST_DATABASE_URL. The test creates its own user and posts.
+func TestResponsesMatchAPIDocWithDatabase(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	var err error
+	db.DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.InitIDGenerator()
+	if err := model.AutoMigrate(db.DB); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.InitContentFilter(); err != nil {
+		t.Fatal(err)
+	}
+
+	suffix := strconv.FormatUint(db.GenerateID(), 10)
+	user, err := service.CreateUser("contract"+suffix, "Contract Test", "contract"+suffix+"@example.com", "password123", "Engineer", "full-time")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := service.GenerateJWT(strconv.FormatUint(user.ID, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := testRouter()
+
+	serve(t, router, contractCase{method: "POST", path: "/posts", token: token, body: `{"content": "Contract test post"}`})
+	serve(t, router, contractCase{method: "POST", path: "/posts", token: token, body: `{"content": 42}`, status: http.StatusBadRequest})
+
+	// A retry with the same Idempotency-Key is answered from the first response
+	retry := contractCase{method: "POST", path: "/posts", token: token, body: `{"content": "Sent twice"}`, idempotencyKey: "contract-" + suffix, status: http.StatusCreated}
+	serve(t, router, retry)
+	if rec := serve(t, router, retry); rec.Header().Get(middleware.IdempotentReplayHeader) != "true" {
+		t.Errorf("retry with the same Idempotency-Key was not replayed")
+	}
+	retry.body, retry.status = `{"content": "Something else"}`, http.StatusUnprocessableEntity
+	serve(t, router, retry)
+
+	for _, path := range []string{
+		"/health/ready",
+		"/users/me",
+		"/users/me/posts",
+		"/users/me/drafts",
+		"/users/me/followers",
+		"/users/me/following",
+		"/users/id/" + strconv.FormatUint(user.ID, 10),
+		"/users/" + user.Username,
+		"/users/me/2fa",
+		"/users/me/security-events",
+		"/users/me/profile",
+		"/users/me/bookmarks",
+		"/users/me/bookmarks/collections",
+		"/users/me/blocks",
+		"/users/me/jobs",
+		"/users/me/saved-jobs",
+		"/users/me/applications",
+		"/users/me/companies",
+		"/users/feed",
+		"/posts/all",
+		"/jobs",
+		"/conversations",
+		"/conversations/requests",
+		"/conversations/unread-count",
+		"/notifications",
+		"/notifications/unread-count",
+		"/posts/1",
+	} {
+		serve(t, router, contractCase{method: "GET", path: path, token: token})
+	}
+}